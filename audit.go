@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mkobaly/hipchatBot/audit"
+	"github.com/mkobaly/hipchatBot/chat"
+	"github.com/mkobaly/hipchatBot/config"
+)
+
+// How big the audit log grows before it is rotated and how many old files
+// are kept, unless config.yaml says otherwise
+const (
+	defaultAuditSize    = 10
+	defaultAuditBackups = 5
+)
+
+// maxAuditEntries is how many entries /build audit shows at most
+const maxAuditEntries = 20
+
+// auditTimeFormat is how the time of an audit entry is shown
+const auditTimeFormat = "Jan 2 15:04:05"
+
+// auditEntry is an audit log entry as the audit reply lists it
+type auditEntry struct {
+	audit.Entry
+	When string
+}
+
+// openAuditLog opens the audit log set up in config.yaml
+func openAuditLog(cfg config.AuditLog) (*audit.Log, error) {
+	size, backups := cfg.MaxSize, cfg.Backups
+	if size == 0 {
+		size = defaultAuditSize
+	}
+	if backups == 0 {
+		backups = defaultAuditBackups
+	}
+	return audit.Open(cfg.File, int64(size)<<20, backups)
+}
+
+// commandEntry starts the audit log entry of a command
+func commandEntry(command chat.Command, now time.Time) *audit.Entry {
+	return &audit.Entry{
+		Time:     now,
+		Source:   command.Source,
+		UserID:   command.User.ID,
+		User:     command.User.Name,
+		Mention:  command.User.MentionName,
+		RoomID:   command.RoomID,
+		RoomName: command.RoomName,
+		Command:  command.Text,
+		Outcome:  audit.OutcomeOK,
+	}
+}
+
+// writeAudit appends the entry to the audit log
+func (c *Context) writeAudit(e *audit.Entry) {
+	if err := c.audit.Write(*e); err != nil {
+		log.Printf("Error writing audit log: %v", err)
+	}
+}
+
+// auditReplier records the outcome of a command from its replies in the
+// command's audit log entry
+type auditReplier struct {
+	chat.Replier
+	entry *audit.Entry
+}
+
+// auditNotifier is an auditReplier that can still post after the command was
+// answered
+type auditNotifier struct {
+	auditReplier
+	chat.Notifier
+}
+
+// audited wraps r to record the outcome of the command in e, keeping r a
+// chat.Notifier when it is one
+func audited(r chat.Replier, e *audit.Entry) chat.Replier {
+	ar := auditReplier{Replier: r, entry: e}
+	if n, ok := r.(chat.Notifier); ok {
+		return auditNotifier{auditReplier: ar, Notifier: n}
+	}
+	return ar
+}
+
+// outcomeRank orders the outcomes, a command keeps its worst one
+var outcomeRank = map[string]int{audit.OutcomeOK: 0, audit.OutcomeRefused: 1, audit.OutcomeFailed: 2}
+
+func (r auditReplier) Reply(ctx context.Context, m chat.Message) error {
+	outcome := audit.OutcomeOK
+	if m.Template == "" || m.Template == "help" {
+		switch m.Color {
+		case chat.ColorYellow:
+			outcome = audit.OutcomeRefused
+		case chat.ColorRed:
+			outcome = audit.OutcomeFailed
+		}
+	}
+	if outcomeRank[outcome] >= outcomeRank[r.entry.Outcome] {
+		r.entry.Outcome = outcome
+		r.entry.Reply = m.Text
+		if m.Template != "" {
+			r.entry.Reply = m.Template
+		}
+	}
+	return r.Replier.Reply(ctx, m)
+}
+
+// auditEntries returns the entries of the audit log matching the options of
+// /build audit, --user and --since, with their times formatted
+func (c *Context) auditEntries(flags commandFlags, now time.Time) ([]auditEntry, error) {
+	q := audit.Query{User: flags.Get("user"), Limit: maxAuditEntries}
+	if since := flags.Get("since"); since != "" {
+		age, err := parseAge(since)
+		if err != nil {
+			return nil, err
+		}
+		q.Since = now.Add(-age)
+	}
+	entries, err := c.audit.Find(q)
+	if err != nil {
+		return nil, err
+	}
+	var res []auditEntry
+	for _, e := range entries {
+		res = append(res, auditEntry{Entry: e, When: e.Time.Local().Format(auditTimeFormat)})
+	}
+	return res, nil
+}
+
+// parseAge reads how far back to look, as a number of days ("1d") or weeks
+// ("2w") or a duration like "12h" or "30m"
+func parseAge(s string) (time.Duration, error) {
+	unit := map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour}[s[len(s)-1:]]
+	if unit != 0 {
+		if n, err := strconv.Atoi(strings.TrimSpace(s[:len(s)-1])); err == nil && n >= 0 {
+			return time.Duration(n) * unit, nil
+		}
+	} else if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return d, nil
+	}
+	return 0, fmt.Errorf("can not read --since %v, give it like 1d, 2w or 12h", s)
+}
@@ -0,0 +1,227 @@
+// Package audit keeps an append-only log of the commands the bot receives and
+// the actions it takes, as JSON lines in a file rotated by size
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Outcomes of a command or action
+const (
+	OutcomeOK      = "ok"
+	OutcomeRefused = "refused"
+	OutcomeFailed  = "failed"
+)
+
+// Entry is one command received or action taken. Action is the command's
+// action, like "kick", or "schedule run" for the builds the bot kicks off by
+// itself. TaskID is the build it kicked off and Reply what it answered.
+type Entry struct {
+	Time     time.Time `json:"time"`
+	Source   string    `json:"source,omitempty"`
+	UserID   string    `json:"userId,omitempty"`
+	User     string    `json:"user,omitempty"`
+	Mention  string    `json:"mention,omitempty"`
+	RoomID   string    `json:"roomId,omitempty"`
+	RoomName string    `json:"roomName,omitempty"`
+	Command  string    `json:"command,omitempty"`
+	Action   string    `json:"action"`
+	Server   string    `json:"server,omitempty"`
+	ConfigID string    `json:"configId,omitempty"`
+	Branch   string    `json:"branch,omitempty"`
+	TaskID   string    `json:"taskId,omitempty"`
+	Outcome  string    `json:"outcome"`
+	Reply    string    `json:"reply,omitempty"`
+}
+
+// By reports whether the entry is of the user, given by ID, mention or name
+func (e Entry) By(user string) bool {
+	user = strings.TrimPrefix(user, "@")
+	return strings.EqualFold(e.UserID, user) || strings.EqualFold(e.Mention, user) || strings.EqualFold(e.User, user)
+}
+
+// Log appends entries to a file. Once the file would grow over maxSize bytes
+// it is renamed to file.1, file.1 to file.2 and so on, keeping backups old
+// files. A Log without a file drops every entry.
+type Log struct {
+	file    string
+	maxSize int64
+	backups int
+	// mu guards the file being written, rotating keeps Find from reading the
+	// files while they are renamed
+	mu       sync.Mutex
+	rotating sync.RWMutex
+	f        *os.File
+	size     int64
+}
+
+// Open opens the log in file for appending
+func Open(file string, maxSize int64, backups int) (*Log, error) {
+	l := &Log{file: file, maxSize: maxSize, backups: backups}
+	if file == "" {
+		return l, nil
+	}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *Log) open() error {
+	f, err := os.OpenFile(l.file, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	l.f, l.size = f, fi.Size()
+	return nil
+}
+
+// Enabled reports whether the entries are kept
+func (l *Log) Enabled() bool {
+	return l != nil && l.file != ""
+}
+
+// Write appends the entry to the log. When the file can not be rotated the
+// entry is still appended to it and the error returned.
+func (l *Log) Write(e Entry) error {
+	if !l.Enabled() {
+		return nil
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var rotateErr error
+	if l.maxSize > 0 && l.size > 0 && l.size+int64(len(b)) > l.maxSize {
+		rotateErr = l.rotate()
+	}
+	if l.f == nil {
+		// an earlier rotation could not reopen the file
+		if err := l.open(); err != nil {
+			return err
+		}
+	}
+	n, err := l.f.Write(b)
+	l.size += int64(n)
+	if err == nil {
+		err = rotateErr
+	}
+	return err
+}
+
+// rotate moves the current file to the first backup and starts a new one.
+// When that fails the log goes on in whatever file is left in place, so one
+// failed rotation does not stop the log for good. l.mu must be held.
+func (l *Log) rotate() error {
+	l.rotating.Lock()
+	defer l.rotating.Unlock()
+	l.f.Close()
+	l.f = nil
+	if err := l.shift(); err != nil {
+		l.open()
+		return err
+	}
+	return l.open()
+}
+
+// shift renames the file and its backups one place up, dropping the oldest
+func (l *Log) shift() error {
+	os.Remove(l.backup(l.backups))
+	for i := l.backups - 1; i >= 1; i-- {
+		if err := os.Rename(l.backup(i), l.backup(i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if l.backups > 0 {
+		if err := os.Rename(l.file, l.backup(1)); err != nil {
+			return err
+		}
+	} else if err := os.Remove(l.file); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (l *Log) backup(i int) string {
+	return fmt.Sprintf("%v.%v", l.file, i)
+}
+
+// Close closes the log's file
+func (l *Log) Close() error {
+	if !l.Enabled() {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil {
+		return nil
+	}
+	return l.f.Close()
+}
+
+// Query selects entries from the log. An empty User is every user's and a
+// zero Since goes back to the oldest entry kept. Limit keeps the latest
+// entries only.
+type Query struct {
+	User  string
+	Since time.Time
+	Limit int
+}
+
+// Find returns the entries matching q, oldest first, from the backups and the
+// current file. Lines that can not be read, like one being written, are
+// skipped. Entries are written while Find reads, only rotations wait for it.
+func (l *Log) Find(q Query) ([]Entry, error) {
+	if !l.Enabled() {
+		return nil, nil
+	}
+	l.rotating.RLock()
+	defer l.rotating.RUnlock()
+	var res []Entry
+	for i := l.backups; i >= 0; i-- {
+		file := l.file
+		if i > 0 {
+			file = l.backup(i)
+		}
+		f, err := os.Open(file)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		s := bufio.NewScanner(f)
+		s.Buffer(nil, 1<<20)
+		for s.Scan() {
+			var e Entry
+			if json.Unmarshal(s.Bytes(), &e) != nil {
+				continue
+			}
+			if (q.User == "" || e.By(q.User)) && !e.Time.Before(q.Since) {
+				res = append(res, e)
+			}
+		}
+		err = s.Err()
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if q.Limit > 0 && len(res) > q.Limit {
+		res = res[len(res)-q.Limit:]
+	}
+	return res, nil
+}
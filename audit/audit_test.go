@@ -0,0 +1,125 @@
+package audit
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "audit.log")
+
+	// room for about two entries per file
+	l, err := Open(file, 400, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2026, 10, 15, 2, 0, 0, 0, time.UTC)
+	users := []string{"Ada", "Grace"}
+	for i := 0; i < 10; i++ {
+		e := Entry{Time: start.Add(time.Duration(i) * time.Hour), UserID: users[i%2], User: users[i%2], Command: "/build kick Web_CI main", Action: "kick", Outcome: OutcomeOK}
+		if err := l.Write(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"audit.log", "audit.log.1", "audit.log.2"} {
+		fi, err := os.Stat(filepath.Join(dir, name))
+		if err != nil || fi.Size() > 400 {
+			t.Errorf("%v not rotated: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(file + ".3"); !os.IsNotExist(err) {
+		t.Errorf("more backups kept than asked for")
+	}
+
+	l, err = Open(file, 400, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	all, err := l.Find(Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) < 4 || !all[len(all)-1].Time.Equal(start.Add(9*time.Hour)) {
+		t.Fatalf("unexpected entries %+v", all)
+	}
+	for i := 1; i < len(all); i++ {
+		if all[i].Time.Before(all[i-1].Time) {
+			t.Errorf("entries out of order: %+v", all)
+		}
+	}
+	ada, _ := l.Find(Query{User: "@ada", Since: start.Add(7 * time.Hour)})
+	if len(ada) != 1 || ada[0].User != "Ada" || !ada[0].Time.Equal(start.Add(8*time.Hour)) {
+		t.Errorf("unexpected entries of Ada %+v", ada)
+	}
+	if latest, _ := l.Find(Query{Limit: 2}); len(latest) != 2 || latest[1].User != "Grace" {
+		t.Errorf("unexpected latest entries %+v", latest)
+	}
+}
+
+func TestLogAfterFailedRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "audit.log")
+	l, err := Open(file, 200, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	// the first backup can not be replaced while a directory is in its way
+	if err := os.MkdirAll(filepath.Join(file+".1", "in-the-way"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	e := Entry{Time: time.Now(), User: "Ada", Command: "/build kick Web_CI main", Action: "kick", Outcome: OutcomeOK}
+	failed := false
+	for i := 0; i < 4; i++ {
+		failed = l.Write(e) != nil || failed
+	}
+	if !failed {
+		t.Fatalf("rotation onto a directory did not fail")
+	}
+	if b, _ := ioutil.ReadFile(file); strings.Count(string(b), "\n") != 4 {
+		t.Errorf("entries lost while the rotation failed: %q", b)
+	}
+
+	os.RemoveAll(file + ".1")
+	for i := 0; i < 4; i++ {
+		if err := l.Write(e); err != nil {
+			t.Fatalf("log broken after a failed rotation: %v", err)
+		}
+	}
+	if _, err := os.Stat(file + ".1"); err != nil {
+		t.Errorf("log not rotated once it could be: %v", err)
+	}
+}
+
+func TestLogWithoutFile(t *testing.T) {
+	l, err := Open("", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Enabled() {
+		t.Errorf("log without a file enabled")
+	}
+	if err := l.Write(Entry{Action: "kick"}); err != nil {
+		t.Error(err)
+	}
+	if entries, err := l.Find(Query{}); err != nil || len(entries) != 0 {
+		t.Errorf("log without a file has %+v, %v", entries, err)
+	}
+}
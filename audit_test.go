@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mkobaly/hipchatBot/audit"
+	"github.com/mkobaly/hipchatBot/chat"
+	"github.com/mkobaly/hipchatBot/config"
+)
+
+func TestAudit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	c, _ := settingsContext(t)
+	c.audit, err = openAuditLog(config.AuditLog{File: filepath.Join(dir, "audit.log")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.audit.Close()
+	ctx := context.Background()
+	run := func(text string, user chat.User) replies {
+		var r replies
+		c.dispatch(ctx, chat.Command{Text: text, User: user, RoomID: "1", RoomName: "Web", Source: "HipChat"}, &r)
+		return r
+	}
+	ada := chat.User{ID: "2", Name: "Ada Lovelace", MentionName: "Ada"}
+	grace := chat.User{ID: "1", Name: "Grace", MentionName: "grace"}
+	releaser := chat.User{ID: "4513556", Name: "Michael Kobaly", MentionName: "MichaelKobaly"}
+	admin := chat.User{ID: "5", Name: "Linus", MentionName: "linus"}
+
+	run("/build kick Web_CI main", ada)
+	run("/build kick Web_CI", grace)
+	entries, err := c.audit.Find(audit.Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+	kick := entries[0]
+	if kick.UserID != "2" || kick.Mention != "Ada" || kick.RoomName != "Web" || kick.Action != "kick" ||
+		kick.ConfigID != "Web_CI" || kick.Branch != "main" || kick.TaskID != "1" || kick.Outcome != audit.OutcomeOK {
+		t.Errorf("unexpected kick entry %+v", kick)
+	}
+	if entries[1].Outcome != audit.OutcomeRefused || entries[1].Reply == "" {
+		t.Errorf("unexpected entry of a kick without a branch %+v", entries[1])
+	}
+
+	if r := run("/build audit", releaser); len(r) != 1 || r[0].Color != chat.ColorRed {
+		t.Errorf("audit log shown without permissions: %+v", r)
+	}
+	c.cfg.Permissions = testPermissions
+	if r := run("/build audit", grace); len(r) != 1 || r[0].Color != chat.ColorRed {
+		t.Errorf("audit log shown to a viewer: %+v", r)
+	}
+	if r := run("/build audit", releaser); len(r) != 1 || r[0].Color != chat.ColorRed {
+		t.Errorf("audit log shown to a role allowed every command: %+v", r)
+	}
+	r := run("/build audit --user @ada --since 1d", admin)
+	if len(r) != 1 || r[0].Template != "audit" {
+		t.Fatalf("unexpected reply %+v", r)
+	}
+	if shown := r[0].Data.([]auditEntry); len(shown) != 1 || shown[0].ConfigID != "Web_CI" || shown[0].When == "" {
+		t.Errorf("unexpected audit entries %+v", shown)
+	}
+	if r := run("/build audit --since soon", admin); len(r) != 1 || r[0].Color != chat.ColorYellow {
+		t.Errorf("bad --since accepted: %+v", r)
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	for s, want := range map[string]time.Duration{"1d": 24 * time.Hour, "2w": 14 * 24 * time.Hour, "12h": 12 * time.Hour, "30m": 30 * time.Minute} {
+		if got, err := parseAge(s); err != nil || got != want {
+			t.Errorf("parseAge(%q) = %v, %v, want %v", s, got, err, want)
+		}
+	}
+	for _, s := range []string{"d", "-1d", "soon"} {
+		if _, err := parseAge(s); err == nil {
+			t.Errorf("parseAge(%q) accepted", s)
+		}
+	}
+}
@@ -1,9 +1,38 @@
 // Code generated by go-bindata.
 // sources:
+// templates/artifacts.html
+// templates/artifacts.md
+// templates/artifacts.txt
+// templates/audit.html
+// templates/audit.md
+// templates/audit.txt
+// templates/finished.html
+// templates/finished.md
+// templates/finished.txt
 // templates/help.html
+// templates/help.md
+// templates/help.txt
 // templates/kick.html
+// templates/kick.md
+// templates/kick.txt
 // templates/list.html
+// templates/list.md
+// templates/list.txt
+// templates/log.html
+// templates/log.md
+// templates/log.txt
+// templates/mine.html
+// templates/mine.md
+// templates/mine.txt
+// templates/schedules.html
+// templates/schedules.md
+// templates/schedules.txt
 // templates/status.html
+// templates/status.md
+// templates/status.txt
+// templates/watching.html
+// templates/watching.md
+// templates/watching.txt
 // DO NOT EDIT!
 
 package main
@@ -71,7 +100,187 @@ func (fi bindataFileInfo) Sys() interface{} {
 	return nil
 }
 
-var _templatesHelpHtml = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x7c\x90\xbd\x6e\xeb\x30\x0c\x46\xf7\x00\x79\x07\x22\xd3\xbd\x43\xa0\x3d\x55\x35\xa4\x43\x1b\xb4\x63\xfb\x00\xfa\xa1\x1d\xc1\xb4\x18\x88\x14\xd0\xbc\x7d\xe1\xd8\x41\xd0\x36\xe8\x20\x0d\xe2\xe1\xf9\x44\x5a\x39\xf9\x02\xa2\x67\xc2\xc7\x4d\x64\xe2\xba\x4b\xbe\x0e\x7b\x6a\xf8\xa0\xf8\xa9\xdb\x84\x91\xab\xd7\xcc\x65\xd7\x4a\xc2\x4a\xb9\xe0\xc6\x59\xd1\xca\xa5\x77\xef\xe8\xc7\x98\xf5\x0c\xfb\x96\x29\xc1\x9e\x15\x5e\x90\x4e\x36\x54\x77\x39\x66\xe1\xac\x99\x72\xdc\x7a\xb5\x5e\xfd\x91\xb8\x71\x16\x47\xf7\x21\xbe\x47\x6b\x70\xbc\x75\xd9\x46\xd3\x4d\xd9\xd9\xe0\x4c\xb8\x64\x51\x16\xb5\x26\x38\xf8\xf7\x96\x45\x81\x9b\x82\x27\x82\xb9\x18\xb9\x74\xb9\x6f\xf3\xc7\xe5\xbf\x35\x94\x7f\x09\x86\x1c\x87\x19\x7f\xba\xd0\x87\x04\xa1\xfa\x12\x8f\xb3\xf5\x75\x2a\x73\xd7\x2d\xc6\x8e\xeb\x0f\xb8\x49\x2e\xfd\xd2\x72\x3f\x41\xd4\x6b\x13\x50\x2f\xc3\x21\xcd\xd6\x67\xd4\xeb\x33\x5f\xd5\x15\xa5\x91\x42\x38\x2f\xe4\x7d\xd9\x76\x7b\x9c\x36\x7b\x9b\x38\xf2\x38\xfa\x92\x80\x4f\xdf\x87\x34\x8d\xdc\x57\x00\x00\x00\xff\xff\xfc\xf6\x2a\x46\xd7\x01\x00\x00")
+var _templatesArtifactsHtml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x55\x8f\xc1\x0a\xc2\x30\x10\x44\xef\x85\xfe\xc3\xd2\xbb\xed\x5d\x63\x40\xf1\x22\x88\x07\xd1\x0f\x58\x9b\xad\x06\xd3\x44\x36\x29\x28\xc5\x7f\x77\x5b\xb5\xe0\x6d\xf7\xcd\x4c\x26\xab\xe2\x1d\x3d\xc4\xf4\x74\xb4\x2c\xea\xe0\x02\xcf\x0d\xf2\x6d\xed\x3a\x5a\x24\x7a\xa4\x99\xa1\x3a\x30\x26\x1b\xfc\xbc\xf3\x86\xd8\x59\x4f\x85\x56\x31\x71\xf0\x17\xbd\xee\xac\x33\xb0\xe2\x64\x1b\xac\x53\x84\xbe\x2f\x8f\x18\x6f\xdb\xcd\xeb\xa5\xce\xac\x55\xf5\xf5\xc9\x20\x3d\x3a\xcf\xfa\xde\x36\x50\x4e\x01\xb1\x75\x6e\xc4\x8c\xfe\x42\x7f\x4a\x9e\x01\x80\x72\x56\x7f\x32\xa7\xc3\x4e\xdc\x08\x57\xa6\x66\x59\x48\xd1\x08\x0a\x51\xcb\x3d\xb6\x24\x5a\x85\xb2\x90\x8b\x32\x4f\x50\x80\x37\x83\x26\xef\x0c\x35\xe3\x96\x67\xaa\x92\xda\x9f\x79\xf8\xa9\x20\x6a\xf5\x3e\x00\x4e\xb7\x34\x41\x0e\x56\x95\xe0\x6f\xec\x0d\xbc\x9c\xc3\xe6\x2c\x01\x00\x00")
+
+func templatesArtifactsHtmlBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesArtifactsHtml,
+		"templates/artifacts.html",
+	)
+}
+
+func templatesArtifactsHtml() (*asset, error) {
+	bytes, err := templatesArtifactsHtmlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/artifacts.html", size: 300, mode: os.FileMode(438), modTime: time.Unix(1792383124, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesArtifactsMd = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xd3\xd2\x72\x2a\xcd\xcc\x49\x51\x70\x2c\x2a\xc9\x4c\x4b\x4c\x2e\x29\x56\xa8\xae\xd6\x0b\x49\x2c\xce\xf6\x74\xa9\xad\xd5\xd2\xe2\xe5\xe2\xe5\xaa\xae\x2e\x4a\xcc\x4b\x4f\x55\xd0\x83\xab\xa9\xad\xd5\x05\x2a\xcb\x4c\x53\xd0\x0b\x0d\xf2\xa9\xad\x8d\x06\x6a\xf1\x4b\xcc\x4d\xad\xad\x8d\xd5\x00\x32\xc1\x62\x9a\xd5\xd5\xa9\x39\xc5\x40\x21\xb8\x1c\x50\x20\x2f\xa5\xb6\x16\x64\x1e\x44\x26\xde\x2f\x5f\x21\x11\x6e\x6d\x5a\x7e\x69\x5e\x4a\x3c\x58\x16\xa4\x0c\x00\x17\x26\x38\xfe\x98\x00\x00\x00")
+
+func templatesArtifactsMdBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesArtifactsMd,
+		"templates/artifacts.md",
+	)
+}
+
+func templatesArtifactsMd() (*asset, error) {
+	bytes, err := templatesArtifactsMdBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/artifacts.md", size: 152, mode: os.FileMode(438), modTime: time.Unix(1792384418, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesArtifactsTxt = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x2a\xcd\xcc\x49\x51\x70\x2c\x2a\xc9\x4c\x4b\x4c\x2e\x29\x56\xa8\xae\xd6\x0b\x49\x2c\xce\xf6\x74\xa9\xad\xe5\xe5\xe2\xe5\xaa\xae\x2e\x4a\xcc\x4b\x4f\x55\xd0\x83\xab\xa8\xad\x55\x00\x29\xf2\x4b\xcc\x4d\xad\xad\xad\xae\xce\x4c\x53\xd0\x0b\x0d\xf2\x81\x8a\x82\x59\xd5\xd5\xa9\x79\x29\x20\xed\x40\x46\x4e\x31\x50\x95\x5f\xbe\x42\x22\xdc\x82\xb4\xfc\xd2\xbc\x14\xb0\x1c\x48\x11\x00\xd8\x6b\x66\x01\x7f\x00\x00\x00")
+
+func templatesArtifactsTxtBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesArtifactsTxt,
+		"templates/artifacts.txt",
+	)
+}
+
+func templatesArtifactsTxt() (*asset, error) {
+	bytes, err := templatesArtifactsTxtBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/artifacts.txt", size: 127, mode: os.FileMode(438), modTime: time.Unix(1792384622, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesAuditHtml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x45\x90\xcd\x4e\x03\x31\x0c\x84\xef\x2b\xed\x3b\x58\x3d\xc1\x81\xe6\xbe\x98\x48\xfc\x5c\xb8\x14\x09\x81\x38\xa7\x1b\x77\x1b\x35\x1b\xa3\x24\x2b\x81\xa2\xbc\x3b\xce\xd2\xc2\xcd\xf3\x65\x3c\x1a\x07\xd3\xa7\x09\x90\xf2\xb7\xa7\xbb\xcd\xc8\x9e\xe3\x60\x4d\x3c\x3d\xf8\x85\x6e\x33\x7d\xe5\x1b\x4b\x23\x47\x93\x1d\x87\x61\x09\x96\xa2\x77\x81\x36\x1a\x53\x8e\x1c\x26\x7d\xbf\x58\x97\xc1\xf3\x84\xfb\xa8\x51\x9d\xa9\x0c\x92\xaa\xfb\xae\x14\x77\x80\x6d\xad\xb8\xf8\x55\x45\x13\x26\x6a\xa0\xef\x00\x00\xbd\xd3\xa5\x6c\x3f\x8e\x14\x6a\x05\xdc\x37\xf1\x9e\x28\x8a\x5f\x35\xd1\x76\x5f\x99\xe7\x9d\x99\x49\x0c\x2e\x80\x18\xfe\x41\x29\x14\x6c\xad\x03\xe0\xc8\x96\xda\xf2\x23\xcf\xb3\x69\x0c\xd5\x8a\x9a\xff\x65\xc9\x23\xff\xda\x5b\xde\x9b\x49\xa7\xe7\x27\x49\xbb\xca\x32\x35\xc3\x85\x5c\x9f\xf3\x50\x49\xad\x56\x76\x55\x7d\x87\x4a\xca\x8b\xf2\x49\x42\xda\x99\x82\x68\xd6\x3b\x06\x73\x39\x1e\x28\xe4\xe8\x28\xc1\x81\xe5\x8f\x50\xc9\xf3\xdf\xfa\x0f\x56\xa4\x38\x5c\x61\x01\x00\x00")
+
+func templatesAuditHtmlBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesAuditHtml,
+		"templates/audit.html",
+	)
+}
+
+func templatesAuditHtml() (*asset, error) {
+	bytes, err := templatesAuditHtmlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/audit.html", size: 353, mode: os.FileMode(438), modTime: time.Unix(1792386663, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesAuditMd = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x45\x8e\xc1\x0a\xc2\x30\x10\x44\xef\x85\xfe\xc3\x1e\x35\x60\x3f\xc0\x9b\xe8\xc5\x4b\x05\x51\x3c\xb6\xc1\x6c\x6b\xb0\xc9\x42\x92\x9e\xc2\xfe\xbb\x59\x6b\xf1\x36\x3b\xf3\x76\x18\xa5\x0e\xb3\xb1\x09\x26\x1a\x95\xaa\xab\xba\xca\x39\x68\x3f\x22\x34\xcc\x3b\xc8\xb9\x79\xbc\xd0\x33\x83\x52\x45\xdf\x23\x06\x66\x91\x76\x80\xe6\x4a\xe4\x5a\xed\xb0\xa4\xd6\x0b\xfa\x37\x72\x46\x6f\x98\xf7\xd0\x17\xfb\x48\xce\x69\x39\x7b\x81\x2e\x73\x7a\xd2\xc2\x48\xc9\x4d\xc7\xf7\xf9\x54\x2a\x36\xa9\x28\x01\x56\x67\xfb\x2b\x91\x49\x38\xc5\xf2\xd1\xb5\x04\x7a\x5d\x0b\xe8\x53\xb0\x18\x61\xa0\xd9\x9b\xee\x4b\x2d\xf8\x07\x8a\x3e\x26\xa4\xd3\x00\x00\x00")
+
+func templatesAuditMdBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesAuditMd,
+		"templates/audit.md",
+	)
+}
+
+func templatesAuditMd() (*asset, error) {
+	bytes, err := templatesAuditMdBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/audit.md", size: 211, mode: os.FileMode(438), modTime: time.Unix(1792386663, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesAuditTxt = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x45\x8e\xbb\x0e\xc2\x30\x0c\x45\xf7\x4a\xfd\x07\x8f\xb0\xe4\x03\xd8\x10\x2c\x2c\x45\x42\x54\xcc\x11\x71\x4b\x44\x13\x4b\x79\x4c\x96\xff\x9d\x98\x52\xb1\xdd\xc7\xb1\x75\x8f\xd5\xf9\x02\x0b\xcd\x7d\xd7\x77\xcc\xc9\xc6\x19\xc1\x88\x00\x30\x9b\xc7\x0b\x63\x93\x4d\x8d\x19\x93\x08\xb3\x9f\xc0\xdc\x88\xc2\x60\x03\xb6\xc6\x47\x2d\xff\x01\x33\x46\x27\x72\xd0\xf4\x44\x21\x58\x75\x6a\xae\xb5\x3c\x69\x25\xf4\xc5\xdd\xe6\xf7\xe5\xdc\xaa\x5d\x69\x4a\x81\x2d\xd9\xff\x5e\xe8\x18\x5c\x72\xbb\x18\x08\xec\x36\x12\x30\x96\xe4\x31\xc3\x44\x35\xba\x2f\xb3\xc2\x1f\x19\x11\x59\x79\xc7\x00\x00\x00")
+
+func templatesAuditTxtBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesAuditTxt,
+		"templates/audit.txt",
+	)
+}
+
+func templatesAuditTxt() (*asset, error) {
+	bytes, err := templatesAuditTxtBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/audit.txt", size: 199, mode: os.FileMode(438), modTime: time.Unix(1792386663, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesFinishedHtml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xb3\x49\xb2\x73\x2a\xcd\xcc\x49\x51\x48\xce\xcf\x2d\xc8\x49\x2d\x49\x55\x48\xcb\x2f\x52\xa8\xae\xd6\x73\xce\xcf\x4b\xcb\x4c\xf7\x74\xa9\xad\xb5\xd1\x4f\xb2\x53\x08\x2e\x49\x2c\x49\xb5\x02\x49\x80\x59\xb5\xb5\x60\x91\xd2\x62\x2b\x05\x9b\x24\x3b\xa8\x68\x69\x31\x44\x71\x75\x75\x66\x9a\x82\x5e\x78\x6a\x52\x68\x90\x4f\x6d\x2d\x2f\x97\x4d\x52\x91\x1d\x90\x4c\x54\xc8\x28\x4a\x4d\xb3\x55\x02\xaa\x86\xc9\x29\xd9\xf9\x17\xa4\xe6\x29\x24\x81\x5c\x60\xa3\x9f\x08\xd4\x99\x9a\x97\x02\xd2\x02\x00\x35\x40\xe1\xa9\x97\x00\x00\x00")
+
+func templatesFinishedHtmlBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesFinishedHtml,
+		"templates/finished.html",
+	)
+}
+
+func templatesFinishedHtml() (*asset, error) {
+	bytes, err := templatesFinishedHtmlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/finished.html", size: 151, mode: os.FileMode(438), modTime: time.Unix(1792384622, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesFinishedMd = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xd3\xd2\x72\x2a\xcd\xcc\x49\x51\x48\xce\xcf\x2d\xc8\x49\x2d\x49\x55\x48\xcb\x2f\x52\xa8\xae\xd6\x73\xce\xcf\x4b\xcb\x4c\xf7\x74\xa9\xad\xd5\xd2\x52\x08\x2e\x49\x2c\x49\xb5\x02\x09\x83\x59\xb5\xb5\x60\x91\xd2\x62\x2b\x05\x2d\x2d\xa8\x60\x69\x31\x48\x65\x75\x75\x66\x9a\x82\x5e\x78\x6a\x52\x68\x90\x4f\x6d\x2d\x2f\x57\xb4\x7f\x41\x6a\x9e\x42\x12\xc8\x86\x58\x0d\xa0\x4a\x98\x8c\x66\x75\x75\x6a\x5e\x0a\x48\x05\x00\xdf\x77\x62\x8f\x80\x00\x00\x00")
+
+func templatesFinishedMdBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesFinishedMd,
+		"templates/finished.md",
+	)
+}
+
+func templatesFinishedMd() (*asset, error) {
+	bytes, err := templatesFinishedMdBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/finished.md", size: 128, mode: os.FileMode(438), modTime: time.Unix(1792384622, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesFinishedTxt = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x2a\xcd\xcc\x49\x51\x48\xce\xcf\x2d\xc8\x49\x2d\x49\x55\x48\xcb\x2f\x52\xa8\xae\xd6\x73\xce\xcf\x4b\xcb\x4c\xf7\x74\xa9\xad\x55\x08\x2e\x49\x2c\x49\xb5\x02\x09\x82\x59\x50\x91\xd2\x62\xb8\x50\x69\x71\x6d\x6d\x75\x75\x66\x9a\x82\x5e\x78\x6a\x52\x68\x90\x4f\x6d\x2d\x2f\x17\x50\x0a\xc6\xa9\xae\x4e\xcd\x4b\x01\x89\x01\x00\xb4\x7d\x58\x6d\x6a\x00\x00\x00")
+
+func templatesFinishedTxtBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesFinishedTxt,
+		"templates/finished.txt",
+	)
+}
+
+func templatesFinishedTxt() (*asset, error) {
+	bytes, err := templatesFinishedTxtBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/finished.txt", size: 106, mode: os.FileMode(438), modTime: time.Unix(1792384622, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesHelpHtml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x95\x55\x4d\x6f\xdb\x30\x0c\xbd\x17\xe8\x7f\x20\x72\xd9\x07\xe2\x65\x1d\xb0\x4b\x97\xe6\xd0\x60\xd8\x8a\x0d\xe8\xd0\x6e\xa7\xa2\x07\xc5\xa6\x6d\xc1\xb2\xe4\xe9\x23\xa9\x81\xfd\xf8\x51\x92\x9d\x3a\x9e\xd3\x76\x08\x9a\x2a\x12\xf9\xf8\x48\x3d\x52\x4b\xd3\x30\x09\xc6\xb6\x02\x2f\x66\xa9\x12\x4a\x9f\x67\x4c\x57\x97\xc2\xe1\x27\x8b\x0f\x36\xc9\x30\x55\x9a\x59\xae\xe4\xb9\x93\x19\x6a\xc1\x25\xce\x56\x4b\x63\xb5\x92\xc5\xea\x27\xb2\x3a\xe5\xb6\x85\x4b\xc7\x45\x06\x97\xca\xc2\x57\x14\xcd\x72\xa3\x57\xe1\x6f\xd1\xd9\xd1\x82\xe2\xac\x4e\x4f\x4e\x4f\x96\xc7\x23\x12\x2e\xd6\xab\x5f\x86\x15\xb8\x5c\xd0\x6a\xef\xb5\x74\xc2\x7f\x0b\x4e\xa8\xab\xc5\x26\xc4\x12\xdc\xd8\xe5\x62\xb3\x82\xd7\xdf\x69\x05\xca\x59\x60\x42\x40\x3c\x4c\x95\xcc\x79\xe1\x22\x71\xf3\x66\xb9\x20\xd7\x31\x40\xc5\xd3\x2a\x9a\xaf\x83\xf5\x55\x06\x77\x1b\xcd\x64\x5a\xde\x47\xdc\x6f\xde\x40\xe5\x79\x87\x99\x2b\x3d\x32\x77\x86\xcb\x02\xa2\xcf\x1c\xe8\xd8\x96\x08\x5a\xa9\xfa\x95\x81\x0c\x73\xe6\x84\xed\x4e\x5f\xcc\x20\x9a\x43\x92\x68\xdc\x72\x43\xe4\xc1\x94\xec\x85\x74\x1a\x2e\x25\x66\x60\x15\x0c\x9d\xa7\x43\x87\x02\xe9\x9a\x8c\x2b\x94\x11\x7f\xdd\x6f\x51\x12\x55\x1f\x4b\xe5\xc0\xa0\xd1\xca\x62\x6a\x09\x7b\xa2\xba\xd3\xf8\xc6\x32\xeb\x0c\x58\x66\xaa\xab\x2c\xe2\x7f\x41\xdb\x6f\xab\x3e\x09\x8d\x26\x14\xa9\xed\x2c\x8f\x90\xa5\x9a\xa0\x38\x00\x5b\xc7\x2d\x06\xbf\x1d\x3a\x22\x46\xc5\xd0\x4e\xca\x70\x1f\xde\x67\x1a\x48\xa8\xe2\x00\xe5\xb6\x54\xbb\x90\x2f\xca\xcc\x93\xf2\xcb\xbd\xe5\x34\x04\xd3\x96\xe7\x2c\xb5\x87\xb9\x05\x09\x7a\xef\x9c\x0b\x34\xd0\xb8\x0d\xc9\xb3\xf4\x05\x6b\x1f\x41\xa7\x01\x6b\x6a\xa8\x11\x48\x38\x30\xd0\x2a\x07\x56\xf3\xa2\x40\x8d\x47\x9c\x77\xcc\x92\x5a\x0e\x64\x10\xb1\x7e\x28\xc2\xc2\x2d\xea\x96\x28\xc9\x8e\x4b\x70\xe9\x6b\xbf\x97\x0d\x97\x14\x94\x9b\x20\xdc\xe9\x28\x4e\x1e\x8d\x73\x6b\x55\x03\x0d\x05\xf3\x95\x1f\x70\x1f\x47\x79\x82\x3e\x79\x4e\xe5\x3f\xea\xe1\x68\x8b\x2f\xa1\x6b\xbc\x9d\x13\x38\xdd\x5a\xb3\x94\x26\xd2\x6c\xd4\x52\x6c\x50\x9c\x68\xc6\x22\x15\xcb\x6b\x34\xb1\x0b\xbc\x1f\xe0\x43\x43\x9a\x0d\xbd\x25\x78\x85\x30\x7b\x0f\x1f\xe0\x2d\x7d\xce\x92\x8f\xb3\x67\xe8\x8c\x26\xd6\xa0\x5a\xbd\xc9\x7f\x65\xa7\xb1\x56\x5b\x04\xde\xdd\xc4\x4d\xfc\xc9\x06\x60\x4f\xa8\x8e\xb9\x8c\x5b\xb8\x4b\x12\x67\x50\x83\xbb\xf7\x4b\x9a\x66\x29\xc2\x59\x76\x3f\x6a\x0e\xc1\x2c\x12\xdf\x54\xd5\x35\x93\xc4\x96\xbe\x06\xd7\xec\x2d\x22\x1a\xf5\xcc\x3c\x8c\x25\x96\x91\xa8\x8f\x4c\xdd\x24\x29\xfd\x03\xf1\x58\x86\x0e\x16\x54\x73\x38\xab\x17\x71\xe6\x3f\xf7\x5a\x5c\x47\xb7\x27\xde\x8b\x24\x69\x98\x66\x35\x54\xd8\x5e\x6c\x19\x39\xc6\xe0\x9f\x1f\xac\xee\xaf\x3d\x18\xa0\xa5\x4a\x78\xfa\x7e\xf8\xcd\xfd\xd0\x81\x0d\x42\xc1\xb7\x28\xa1\x56\x9a\xb4\x50\xd2\x96\xa2\x1a\x8d\x32\x4b\x92\x4e\x33\x92\x40\x22\xf8\xb5\x14\x6d\xd7\x9c\x04\xd8\xf7\xcf\xa8\x3f\x1e\x9d\xfe\x01\x54\xde\x3f\x67\x5c\x38\x52\xdb\x9f\x94\x02\x17\xf4\x9f\xde\xb7\x41\x77\xef\x6d\xfa\x9b\x36\xe1\x0d\x0a\xdb\x5d\x94\x5d\xa9\x0c\xf6\x43\x37\xa2\x64\xf3\xa8\xe6\xbe\x9d\x9e\x9f\xe8\x24\x0c\xd4\x34\x49\x06\xe9\xdd\x38\x19\x92\xe9\xef\x8e\x15\x8c\x2e\x3c\x4a\xda\x5b\x65\xb0\xbe\x82\xce\xcb\x1f\x20\xdb\x8f\x57\x2f\xec\xfe\x71\x7c\x17\x5a\x82\x1e\x29\xb5\x33\xdd\xb0\xea\x9c\x9c\xa4\x31\x4a\x55\x92\xa4\x6f\x13\xef\xe0\x40\x18\x7f\x01\xc9\x9e\x61\xef\xb7\x08\x00\x00")
 
 func templatesHelpHtmlBytes() ([]byte, error) {
 	return bindataRead(
@@ -86,12 +295,52 @@ func templatesHelpHtml() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "templates/help.html", size: 471, mode: os.FileMode(438), modTime: time.Unix(1500570133, 0)}
+	info := bindataFileInfo{name: "templates/help.html", size: 2231, mode: os.FileMode(438), modTime: time.Unix(1792386667, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesHelpMd = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x8d\x55\x4d\x6f\xd3\x40\x10\xbd\x57\xea\x7f\x18\xe5\x02\x8d\x62\xa0\x48\x1c\xb9\x34\x42\x50\x81\x54\xd4\x8a\x13\xaa\xda\x89\x3d\xb6\x57\x59\xef\x9a\xfd\x48\x1a\x89\x1f\xcf\xec\xae\x37\xb1\xd3\x00\x55\xa4\xc4\xde\x99\xf7\xe6\xeb\xed\x64\x3e\xbf\xf2\x42\x56\x70\xa5\x1d\x7c\x21\xd9\xcf\xe7\xe7\x67\xe7\x67\x0f\x3f\x2c\x36\xf4\x70\x7e\x56\xc0\xe3\xdb\x55\x74\x90\xc2\xba\x47\x78\xfd\x8d\x7f\x40\x7b\x07\x28\x25\x24\x4b\xa9\x55\x2d\x1a\x6f\xd0\x09\xad\xec\xc5\x18\xb4\x16\xe5\x3a\x79\x2d\xa3\xd3\x75\x05\x3f\x57\x06\x55\xd9\xde\x33\xd7\xd7\x60\xd5\x75\x3d\xf0\xd4\xda\x1c\xf9\x7a\x2b\x54\x03\x09\xb0\x00\x36\xbb\x96\xc0\x68\xdd\xbd\xb2\x50\x51\x8d\x5e\xba\xc1\xfa\xbf\xa8\xc9\x0b\x8a\xc2\xd0\x46\x58\xce\x13\x6c\x8b\x2f\x49\xa1\x17\x4a\x51\x05\x4e\xc3\x18\x39\x09\x17\xeb\x37\x1d\xfb\xac\x49\x31\xe7\x32\xbf\x73\xb2\xeb\xcc\xaf\x6b\x40\xe8\x8d\x76\x54\x3a\xe6\x3b\xd1\xb9\x09\xa7\x75\xe8\xbc\x05\x87\x76\x7d\x5d\x31\xe7\x67\x72\xf9\x4c\xe7\x64\x0d\xd9\xd8\x80\xdd\xe0\x36\x4d\x8a\xeb\x25\x79\x20\x58\xa6\x77\x84\x5f\x9e\x3c\x27\xc0\x85\x1a\xaf\x54\xec\x6f\x00\x4c\xc0\x52\x37\x07\xe4\x5d\xab\xb7\xb1\x16\x52\x55\x08\x1e\x1e\xf7\x6e\x13\x18\x1a\x27\x6a\x2c\xdd\x28\xef\x28\x97\x80\xa8\x85\x24\x0b\xbd\x5f\xb1\x8e\xda\xd0\x80\xdd\x81\x68\x42\xd2\x09\x45\x63\x60\x3c\xb5\xb0\xd3\x1e\x9c\x11\x4d\x43\x86\xa6\x80\x2d\x3a\x9e\xec\x64\x6a\x8c\xff\xae\x19\x4f\x1b\x32\x3b\x0e\xad\x86\x98\xd1\x3f\xf7\x6f\x3f\x62\xa1\x38\x90\xb0\x51\x58\x13\x66\xaf\x4e\x73\xdf\x39\xdd\x43\xcf\x01\x42\xf7\x46\x39\x1e\x33\x3f\x4f\x93\x01\xcf\x6a\x3b\xba\x3f\xc9\x91\xfe\x91\x96\x0d\x66\x2f\xe9\xb4\xc4\x67\xa5\xd1\x6a\x36\x96\x36\x8e\x0a\x4f\x3e\x98\xc2\x3b\xd1\x91\x4d\xca\x0c\x20\xa0\xa7\x9e\x35\x15\x35\x2e\xc5\x9a\x60\xf6\x0e\xde\xc3\x9c\x3f\x97\xc5\x87\xd9\xe9\x14\xc6\x5b\x61\xd4\x89\x6c\x7f\x49\x15\x86\x3a\xbd\x21\x10\xa1\xb3\xb7\xe9\x19\x47\x04\xcf\x15\x82\xbe\x12\x0e\x7e\x16\x85\xb7\x64\xc0\xdf\x87\x47\x5e\x14\x25\xc1\x65\x75\x3f\x16\xac\x44\x47\x9c\x57\xa9\xbb\x0e\x15\x67\xc5\x5f\xa3\x51\x05\x8f\x44\xc5\x3a\x5e\xc4\xab\x8f\x15\xab\x6f\xba\xc1\x8a\xa2\xe5\xad\x98\x4b\x1c\xa8\x40\xf7\x79\xd7\xf1\xae\xbc\x49\x2f\x69\x5b\x16\x45\x8f\x06\x3b\x58\xd3\xee\xe3\x06\xa5\x0f\x5a\xfe\xf4\xe4\x4c\x1e\x42\xb4\x92\xe3\xc4\x43\xc0\xb0\x1e\x16\xe1\xae\xc2\x8a\xa0\x11\x1b\x52\xd0\x69\xc3\x93\x69\xf9\x48\x73\x49\x17\x03\xe9\x30\x38\xc5\x58\x26\xbc\x51\x72\x37\xe8\x9e\x49\xb2\x4c\x8f\x94\x78\x40\x64\x12\x1d\x60\x35\x0a\xe9\x79\xcc\xbf\x4b\x8e\xd1\xf0\x2f\x2f\xf2\x7c\x5f\xf6\x0e\xb9\xef\x36\x6e\xdd\x78\x3c\x30\x6f\x5b\x6d\x29\xaf\xa2\x44\x51\x2d\x92\x86\xb2\x70\xff\xba\xdb\x78\x4c\x64\xf8\x4a\xe6\x32\x6e\xbd\x8a\x49\xe7\xae\x62\x83\xdc\xfe\x24\xa4\xe0\x52\xc1\xf2\x1a\x06\x48\x30\x10\xee\x17\x50\x90\x53\xfe\x0b\x78\x13\x55\xc8\x6b\x59\x6f\xed\x70\xe5\x07\x90\x57\xbc\x74\xb8\x1b\x8a\xe5\x65\x53\x7f\x39\x93\x3f\xad\xcf\xf4\xc1\xf3\x06\x00\x00")
+
+func templatesHelpMdBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesHelpMd,
+		"templates/help.md",
+	)
+}
+
+func templatesHelpMd() (*asset, error) {
+	bytes, err := templatesHelpMdBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/help.md", size: 1779, mode: os.FileMode(438), modTime: time.Unix(1792386667, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesHelpTxt = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x8d\x54\x4d\x6b\x1b\x31\x10\xbd\x1b\xfc\x1f\x06\x5f\x9a\x14\x6f\xdb\x14\x7a\xec\x25\xa6\xb4\xa1\x85\x94\x84\x9e\x4a\x0e\xe3\xdd\xd9\x5d\x61\xad\xb4\xd5\x87\x13\x43\x7f\x7c\x47\xd2\xca\x96\x1d\x87\x06\x83\x57\xbb\x33\xef\xcd\xd7\xd3\x5c\x7b\x21\x1b\xb8\xd6\x0e\xbe\x91\x1c\xe7\xb3\xf9\xec\x97\xc5\x8e\xe6\x33\x80\xf7\xeb\x68\x93\xc2\x3a\x80\x8b\x1f\xe1\xa1\xbd\x03\x94\x12\x92\xa5\xd6\xaa\x15\x9d\x37\xe8\x84\x56\xf6\xb2\xc0\x6c\x44\xbd\x49\x4e\xab\xe8\x73\xd3\xc0\xef\xb5\x41\x55\xf7\x0f\x4c\xf5\x3d\x58\x75\xdb\x4e\x34\xad\x36\x27\xbe\xde\x0a\xd5\x41\x02\x2c\x81\xcd\xae\x27\x30\x5a\x0f\x6f\x2c\x34\xd4\xa2\x97\x6e\xb2\xfe\x27\x68\x72\x82\xaa\x32\xb4\x15\x96\xb3\x04\xdb\xe3\x6b\x32\x18\x85\x52\xd4\x80\xd3\x50\x22\xcb\x68\xb1\x78\x33\xb0\xcb\x86\x14\x53\xae\xf2\x3b\xa7\xba\xc9\xf4\xba\x05\x84\xd1\x68\x47\xb5\x63\xba\x33\x6d\x2b\x29\xad\x43\xe7\x2d\x38\xb4\x1b\x4e\x01\x2e\xbe\x92\xcb\xdf\x74\x4e\xd5\x90\x8d\xd5\xef\x26\xb7\xa3\x94\xb8\x58\x92\x07\xfc\x2a\xbd\x23\xfc\xf1\xe4\x39\x3c\x57\x69\xbc\x52\xb1\xb7\x01\x50\x62\xa5\xee\x0e\xc0\xfb\x5e\x3f\xc6\x42\x48\x35\x21\x74\x38\xee\xdd\x4a\x14\x1a\x27\x5a\xac\x5d\x91\x74\xd4\x49\x00\xb4\x42\x92\x85\xd1\xaf\x59\x40\x7d\x28\x7e\x77\xe0\x29\x39\x06\xa1\xa8\xc4\xc5\xaf\x16\x76\xda\x83\x33\xa2\xeb\xc8\xd0\x91\xff\x23\x3a\x1e\xe9\xf1\xb8\xe0\xe2\xa7\x66\x38\x6d\xc9\xec\x38\xb0\x9a\x22\x46\xff\xdc\xba\xbd\xb3\x50\x1c\x47\xd8\x28\xa8\x92\xd8\xab\xf3\xd4\xf7\x4e\x8f\x30\x32\x7f\x68\x5c\x91\xe1\x29\xf1\xb3\x24\x83\xff\x69\x61\x27\x97\x26\x39\xd2\xcb\x49\xd9\x60\xf5\x92\xce\x0b\x7b\x51\x1b\xad\x16\xa5\xa0\xb1\xa8\x3a\xf9\x60\x8a\xee\xc4\x40\x36\x09\x32\x80\x80\x9e\x46\xd6\x52\x54\xb6\x14\x1b\x82\xc5\x07\xf8\x08\x6f\xf9\x77\x55\x7d\x5a\x9c\xcd\xa0\x5c\x04\x45\x1b\xb2\xfd\x15\x35\x18\x1a\xf4\x96\x40\x84\xae\xde\xa5\x33\x16\xf8\x67\xd2\x40\xdf\x08\x07\xbf\xab\xca\x5b\x32\xe0\x1f\xc2\x91\x57\x43\x4d\x70\xd5\x3c\x94\x3a\x95\xe8\x88\xb3\xaa\xf5\x30\xa0\xe2\x9c\xf8\xaf\x98\x52\xf0\x48\x54\x2c\xdf\x65\xbc\xed\xd8\xb0\xec\x8e\x56\x56\x55\xf5\xbc\x00\x73\x7d\x13\x13\xe8\x31\xef\xb6\xf9\xec\x36\x9d\x03\xa6\xaa\x46\x34\x38\xc0\x86\x76\x9f\xb7\x28\x7d\x10\xf0\x97\x27\x67\x72\xf7\xa3\x95\x1c\x27\x1d\x82\x85\x75\xb0\x0c\xb7\x13\xd6\x04\x9d\xd8\xf2\xbe\x18\xb4\xe1\x91\xf4\xfc\x49\x73\x39\x97\x89\x73\x1a\x98\x62\x28\xf3\xdd\x2a\xb9\x9b\xc4\xce\x1c\x59\x9c\x27\xfa\x3b\x20\x26\x0e\x1d\x50\x2d\x0a\xe9\x79\xba\x7f\x6b\x8e\xd0\xf1\x33\xec\xec\xe9\x8e\xec\x1d\x72\xc3\x6d\xdc\xb0\xf1\xf3\x44\xfc\xd8\x6b\x4b\x79\xf3\x24\x8a\x66\x99\xa4\x93\xe5\xfa\xd2\x26\xe3\xf9\x90\xe1\x5b\x98\x8b\xb8\xf3\x2a\xa6\x9c\xfb\x89\x1d\x72\xdf\x93\x7e\x82\x4b\x03\xab\x1b\x98\x20\xc1\x40\xb8\x5f\x38\x41\x45\x79\xdb\xbf\x4b\xe2\xb3\x3c\x6f\x3b\xdd\xf2\x09\xe4\x15\x6f\x19\xee\x05\xef\x10\x56\x5e\x6c\x2e\x27\xf2\x0f\x33\xc6\x0b\xd4\xd6\x06\x00\x00")
+
+func templatesHelpTxtBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesHelpTxt,
+		"templates/help.txt",
+	)
+}
+
+func templatesHelpTxt() (*asset, error) {
+	bytes, err := templatesHelpTxtBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/help.txt", size: 1750, mode: os.FileMode(438), modTime: time.Unix(1792386667, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
 
-var _templatesKickHtml = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x74\x90\x3d\x6f\xf3\x30\x0c\x84\xf7\x00\xf9\x0f\x84\xf7\x37\xda\xf3\xaa\x1a\xd2\x2e\xdd\xbb\x74\xd4\x07\x65\x0b\x92\xc9\x42\x1f\x68\x0d\x23\xff\xbd\xb0\x2d\x20\xc8\xd0\x41\x03\x71\x77\x8f\xc8\x93\xe5\x4b\x13\x94\xba\x24\x7c\x19\x2c\x27\xce\x57\xa7\x73\xbc\xa5\x86\xff\x2b\xfe\xd4\x7f\x0e\x2d\x67\x5d\x03\xd3\xb5\x91\xc3\x9c\x02\xe1\xa0\x64\xa9\x99\x69\x54\xb7\x16\x92\x83\x18\x6c\x44\x07\xec\xbd\x14\x5d\x90\x62\x03\xab\xf3\x49\x9a\xac\xb6\x77\x3e\x49\x9c\x95\x34\x3d\xf2\xca\xe4\xc3\xd8\x3a\x19\xa4\x30\x6a\x5d\x2f\xbb\x76\x48\xef\x6f\xf7\xbb\x14\x38\x77\xc4\x23\x9e\x35\xd9\xe9\x91\xd8\xc7\x27\xeb\x61\xff\xe4\x06\x56\x13\xd8\x09\x6d\x04\x26\xa8\x13\x42\xa9\xba\xb6\x02\xec\x61\xe1\x96\xc1\xec\xab\x98\x05\x72\x23\x0a\x34\xee\x1e\xcf\x29\xf1\xf7\x36\x59\x9e\x67\x4d\xee\xe9\x86\xbf\xeb\x1a\x94\x38\x78\xfd\x93\x75\xbd\x7c\xe8\x12\x8f\x3b\x7a\x19\xbf\x01\x00\x00\xff\xff\x7d\x79\x45\x5c\x6e\x01\x00\x00")
+var _templatesKickHtml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x75\x91\x3d\x4f\xc3\x30\x10\x86\xf7\x4a\xfd\x0f\x56\xf6\x34\x7b\x09\x19\x02\x03\xac\xc0\xc2\xe8\xd8\x97\xc4\xaa\x73\x87\xfc\x51\x88\xa2\xfc\x77\x9c\x0f\xb7\x4d\x05\x83\xa5\xf3\xeb\xf7\x1e\xdb\xef\xe5\xf6\x8b\x23\xb3\xae\xd7\xf0\x98\x08\xd2\x64\x8e\x92\x9b\x53\xa9\x3d\x3c\x38\xf8\x71\xa9\x04\x41\x86\x3b\x45\x78\xf4\x28\xc1\x68\x85\x90\x14\xb9\x75\x86\xb0\x29\x86\xe1\xf0\x02\x5c\x2a\x6c\xc6\x31\xcf\x56\x31\x14\x01\x5a\xec\x77\x79\x65\x8a\x69\x85\x0a\xba\x50\x15\xa5\x57\x5a\xb2\x27\xc2\x5a\x35\x7e\xa5\xb2\x3c\xab\x26\xce\x7c\xb6\x1c\xbd\x3e\x4f\xb4\xd0\xb2\xdf\x0d\x83\xaa\xd9\xa1\x34\x1c\x45\x1b\xc4\x0d\x6c\x16\xaf\xfd\xd1\x13\x1b\x01\xe5\x38\x2e\xfd\x6f\x70\x56\x36\x5c\x76\x47\x88\xf2\x85\x71\xe3\xfb\x83\xf2\x2e\x5a\x90\x5e\xc3\x1d\x25\xca\x17\xca\x8d\x6f\x43\xb9\xc6\xf1\x49\x9e\x89\x90\x7b\x30\x8a\x13\x23\x64\xae\x85\x30\x04\xee\xbc\x65\x54\xb3\x9e\xbc\x61\xd5\x9c\x55\xd5\x33\xe3\x11\x43\xc0\xb3\xa7\x26\xad\xe9\x7b\xda\x09\xea\x3a\x8e\x72\x13\xf2\xff\xb3\x4c\x8a\x6c\xe1\xad\x97\x84\x57\x7e\x70\x7b\x9a\x82\x5e\xff\x06\xe6\x0c\x66\x1c\x59\x9a\xda\xb9\x9c\x2c\x51\x8c\xef\x8f\x73\xfd\x05\x0b\x5f\xc8\x2d\x35\x02\x00\x00")
 
 func templatesKickHtmlBytes() ([]byte, error) {
 	return bindataRead(
@@ -106,12 +355,52 @@ func templatesKickHtml() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "templates/kick.html", size: 366, mode: os.FileMode(438), modTime: time.Unix(1500571843, 0)}
+	info := bindataFileInfo{name: "templates/kick.html", size: 565, mode: os.FileMode(438), modTime: time.Unix(1792389303, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesKickMd = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x5d\x90\xb1\x0e\xc2\x30\x0c\x44\xf7\x4a\xfd\x07\xcf\x91\x80\x9d\xb1\x30\xc0\x0a\x2c\x6c\x4d\x93\x94\x46\x14\x5b\x4a\x1a\x10\xaa\xf2\xef\xc4\x6d\x0a\x12\xdb\xc5\xf7\xce\x76\x2c\xc4\x38\xae\x0f\x46\x6a\x8b\xb7\x18\x85\x28\x8b\xb2\x10\xa2\x0a\xb6\xd7\xb0\x23\x6c\xed\x2d\x38\x39\x58\xc2\xad\x10\x90\xd0\xc9\x99\x8d\xe3\x3e\xc6\xb2\x18\x47\xdb\xc2\xba\x72\x12\x55\xc7\x0d\x66\xb5\xd0\xb9\xcc\x98\x41\x1d\xe3\x4c\x9f\xcc\xd3\xfa\xd4\x93\xf9\x45\xe7\xc4\xcf\xfa\xcb\x9c\x55\x67\x74\xe8\x0d\x67\x16\xcd\x99\x3a\x85\x7e\x5e\xfd\x4d\x95\xc5\x95\x02\x28\x89\x90\x4c\x75\x07\x42\x18\x3a\x03\x7e\x90\x43\xf0\x40\x2d\xbc\x29\x38\x68\xa6\x8f\x36\x6f\x70\x01\x31\x9d\x60\x62\x5a\xea\x7b\x7a\xf1\x4b\xd1\xe3\x21\x51\xf3\x51\xea\xcd\xcc\xe6\x06\x69\xea\x45\xfa\x3b\xdf\x20\xef\x67\xdc\xd3\xb8\x18\x61\xb5\xf2\x93\x64\x64\x29\xe6\x9d\xd2\x76\x1f\xd4\x8a\xb0\x0e\x70\x01\x00\x00")
+
+func templatesKickMdBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesKickMd,
+		"templates/kick.md",
+	)
+}
+
+func templatesKickMd() (*asset, error) {
+	bytes, err := templatesKickMdBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/kick.md", size: 368, mode: os.FileMode(438), modTime: time.Unix(1792389303, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
 
-var _templatesListHtml = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x2c\xcd\x4d\x4a\xc4\x40\x10\xc5\xf1\x7d\x20\x77\x28\x86\x59\xaa\xbd\x8f\x35\x0d\x8e\x57\xf0\x02\x35\xe9\x32\x14\x29\xab\xa5\x3f\x82\xa1\xe9\xbb\x4b\x8c\xdb\xc7\xfb\xf1\xc7\xfc\x4d\x06\xb9\xec\xca\xb7\xcb\x1c\x35\xa6\x29\x50\x5a\xef\x5a\xf9\xb5\xf0\x4f\x79\x0e\x3c\xc7\x44\x45\xa2\x4d\xd5\x02\x27\x15\xe3\x8b\xc7\x5c\x52\xb4\xc5\x7f\x30\x7d\xcd\x52\x76\x78\xdb\x48\x94\x1e\xca\x70\xaf\xa2\x01\xde\xa3\x7d\xca\x52\x4f\x99\xf1\x91\x3c\xba\x7f\x83\xee\x68\xfa\x71\xc0\xaa\x7e\x1c\x5a\x4b\x64\x0b\xc3\x75\xe5\xfd\x09\xae\x1b\x69\x65\x98\x6e\xf0\xd2\xfb\x38\x00\x00\xaa\xf8\xd6\xce\xbd\x77\x74\x2a\x7f\x88\x2d\x1c\x07\x74\x55\xfd\x6f\x00\x00\x00\xff\xff\x4f\x82\xfe\x5f\xc5\x00\x00\x00")
+var _templatesKickTxt = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x5d\x90\xc1\x0e\xc2\x20\x10\x44\xef\x4d\xfa\x0f\xfb\x03\xd6\xbb\xc7\xea\x41\xaf\xea\xc5\x23\x05\x6a\x49\xeb\x6e\x02\xa5\xa6\x21\xfb\xef\x16\xa8\x36\xf1\xc4\x30\x3c\x96\x19\x42\xa8\xce\x5a\x28\x83\x4f\xe6\xb2\x28\x8b\xda\x9b\x41\xc1\x91\xb0\x35\x4f\x6f\xc5\x68\x08\x0f\x10\x42\x95\xfc\x6c\x5f\x4e\x11\x0d\xc1\xb4\x50\xd5\x56\xa0\xec\x98\xf3\x9a\xc9\xd5\x8a\x88\x46\xc5\x9c\xc9\xab\x9e\x8c\x5b\xa6\x31\x7f\x55\xa2\x37\xfb\x8f\xbf\xc9\x4e\x2b\x3f\x68\xe6\xaf\x4a\xfc\x66\xff\xf8\xb2\x78\x90\x07\x29\x10\x96\x33\xd9\x03\x21\x8c\x9d\x06\x37\x8a\xd1\x3b\xa0\x16\x66\xf2\x16\x9a\x54\xac\x99\xc1\x7a\xc4\xa5\x6e\x62\x5a\x1a\x06\x7a\xc7\x9d\xa4\xd7\x4b\xa0\x8a\x5f\xb0\xcf\xe8\x7a\x7f\x79\xf3\x2e\x5c\x1f\x4b\xaf\xc1\xb4\x9d\xb4\x65\x86\xdd\xce\x25\x99\x62\xad\xe6\x2f\xd2\x07\x64\xa3\x06\xef\x58\x01\x00\x00")
+
+func templatesKickTxtBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesKickTxt,
+		"templates/kick.txt",
+	)
+}
+
+func templatesKickTxt() (*asset, error) {
+	bytes, err := templatesKickTxtBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/kick.txt", size: 344, mode: os.FileMode(438), modTime: time.Unix(1792389303, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesListHtml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x4d\x8f\xcd\x6a\xc3\x30\x0c\xc7\xef\x85\xbe\x83\x28\x3d\x6c\xb0\x26\xf7\x4c\x35\xb4\xdd\x0e\x3b\x6f\x2f\xe0\xc4\x6a\x30\x55\xed\x21\xdb\x61\xc1\xe4\xdd\xe7\xd0\x84\xed\xa6\x9f\xf4\xff\x40\x18\xbe\xb5\x83\x10\x47\xa6\xe3\xae\xf3\xec\xa5\x31\x5a\x6e\x67\x4e\xf4\x1a\xe9\x27\x1e\x0c\x75\x5e\x74\xb4\xde\x35\xc9\x19\x12\xb6\x8e\x76\x0a\x43\x14\xef\x7a\xf5\x45\xfa\xde\xd9\x38\xc2\x69\xd0\x96\x75\xcb\x04\xe7\x64\xd9\xc0\xc5\xbb\xab\xed\xd3\xc3\x19\xb0\x15\x85\xf5\xe2\x29\x43\xe9\x54\xdb\x4d\xce\xa2\x5d\x4f\x50\x4d\x53\xce\xf6\x0a\xd5\x27\xc9\x40\x32\x4d\xd8\xaa\x9c\xff\xa8\x9e\x91\x9c\x59\x65\xef\x22\xbe\xec\x01\xe9\xae\x9e\x92\xd3\x6b\xf5\x33\xd6\x65\xb3\x48\xb7\x1b\x4c\xfc\xaf\x64\x7f\xa3\xf1\x05\xf6\x83\x2e\x9f\x41\x73\x84\xea\xe3\x2d\xcc\x2a\x00\x40\xb6\xc5\xf5\x38\xcd\x75\x05\x67\xdf\x1a\x53\x97\x9c\x85\x7e\x01\x41\xb3\x9d\x90\x2e\x01\x00\x00")
 
 func templatesListHtmlBytes() ([]byte, error) {
 	return bindataRead(
@@ -126,12 +415,232 @@ func templatesListHtml() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "templates/list.html", size: 197, mode: os.FileMode(438), modTime: time.Unix(1500567552, 0)}
+	info := bindataFileInfo{name: "templates/list.html", size: 302, mode: os.FileMode(438), modTime: time.Unix(1792382913, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
 
-var _templatesStatusHtml = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\x74\xce\xb1\x0e\x82\x30\x10\x06\xe0\x9d\x84\x77\x68\xd8\xb5\x3b\x9e\x1d\xd0\xc5\xc5\x41\x7d\x81\x42\x4f\x6c\x2c\x2d\x69\xaf\x89\x84\xf0\xee\x06\x24\x12\x12\x1d\x3a\xfc\xbd\xef\xbf\x1c\x84\x56\x5a\x16\xa8\x33\xb8\xcf\x2a\x67\x9c\xcf\x95\xf4\xcf\xc2\x44\xdc\x11\xbe\x68\xa3\xb0\x72\x5e\x92\x76\x36\x8f\x56\xa1\x37\xda\x62\x26\x20\x90\x77\xb6\x16\x45\xd4\x46\xb1\x0b\x86\x68\x88\x5d\x49\x52\x0c\xc0\xe7\x19\xf0\x71\xb7\x48\x13\x28\xbd\x18\x5f\x9a\x00\x36\x02\xca\xb9\x75\x70\xf6\xae\xeb\x9c\x01\x2f\x45\xdf\x6f\xa7\xcf\x5b\xd7\xe2\xe9\x38\x0c\xc0\xb1\x99\x9b\x4b\xcb\x4b\x5b\x3d\x16\x3f\xc5\xb3\x6c\xf0\x37\x1f\xaf\xc1\xaf\x9e\xd2\x7f\x18\xc3\x4a\xc6\xb0\xa2\x1f\x9e\x26\xef\x00\x00\x00\xff\xff\x6a\x7d\x48\x40\x2d\x01\x00\x00")
+var _templatesListMd = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xd3\xd2\x72\x2c\x4b\xcc\xcc\x49\x4c\xca\x49\x55\x70\x2a\xcd\xcc\x49\x51\x70\xce\xcf\x4b\xcb\x4c\x2f\x2d\x4a\x2c\xc9\xcc\xcf\x2b\xd6\xd2\xe2\xe5\xaa\xae\x2e\x4a\xcc\x4b\x4f\x55\xd0\xab\xad\x05\x71\x32\xd3\x14\xf4\x82\x53\x8b\xca\x52\x8b\x6a\x6b\xb5\xb4\xaa\xab\x51\x38\x20\x49\xd7\xa2\xa2\x7c\x20\x57\x21\x5e\xa3\x34\x2f\x11\x66\xba\x66\x7c\x75\x75\x6a\x5e\x0a\xc4\x88\xd4\x9c\xe2\x54\x05\x24\xa5\x68\x2a\xc1\x4a\x40\x6a\xe1\x36\x7b\xba\x14\xd7\xd6\xea\x2a\x00\x2d\x83\x1a\x00\x91\x05\x53\x00\xa0\xf3\x28\x55\xc3\x00\x00\x00")
+
+func templatesListMdBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesListMd,
+		"templates/list.md",
+	)
+}
+
+func templatesListMd() (*asset, error) {
+	bytes, err := templatesListMdBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/list.md", size: 195, mode: os.FileMode(438), modTime: time.Unix(1792384418, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesListTxt = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x2c\x4b\xcc\xcc\x49\x4c\xca\x49\x55\x70\x2a\xcd\xcc\x49\x51\x70\xce\xcf\x4b\xcb\x4c\x2f\x2d\x4a\x2c\xc9\xcc\xcf\x2b\xe6\xe5\xaa\xae\x2e\x4a\xcc\x4b\x4f\x55\xd0\xab\xad\x05\x71\x32\xd3\x14\xf4\x82\x53\x8b\xca\x52\x8b\x6a\x6b\xab\xab\x91\x98\x20\x09\xd7\xa2\xa2\x7c\x20\x47\x41\xa3\x34\x2f\x11\x66\xac\x66\x75\x75\x6a\x5e\x0a\x44\x73\x6a\x4e\x71\xaa\x02\x92\x42\x14\x75\x60\x05\x20\x95\x70\x1b\x3d\x5d\x8a\x81\x86\x29\x00\xad\x81\x6a\x87\xc8\x82\x29\x00\x03\x9c\xf8\x21\xb7\x00\x00\x00")
+
+func templatesListTxtBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesListTxt,
+		"templates/list.txt",
+	)
+}
+
+func templatesListTxt() (*asset, error) {
+	bytes, err := templatesListTxtBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/list.txt", size: 183, mode: os.FileMode(438), modTime: time.Unix(1792384622, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesLogHtml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xb3\x29\x2e\x48\xcc\x53\x28\x2e\xa9\xcc\x49\xb5\x55\x4a\xce\xcf\xc9\x2f\xb2\x4a\x49\x2c\xca\x76\xca\x29\x4d\xb5\x2e\x49\xad\x28\xd1\x4d\x49\x4d\xce\x2f\x4a\x2c\xc9\xcc\xcf\xb3\x2a\xcd\x4b\x49\x2d\xca\xc9\xcc\x4b\x55\xb2\xb3\x29\x2e\x29\xca\xcf\x4b\xb7\x73\x2a\xcd\xcc\x49\x51\xf0\xc9\x4f\x57\xa8\xae\xd6\x0b\x49\x2c\xce\xf6\x74\xa9\xad\xb5\xd1\x87\xca\x02\x19\x40\xd3\xed\x78\xb9\x6c\x92\x8a\x40\x64\x41\x51\xaa\x1d\x50\x1d\x50\x39\x48\x11\x88\x07\x00\x46\xe8\xaa\xbb\x7f\x00\x00\x00")
+
+func templatesLogHtmlBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesLogHtml,
+		"templates/log.html",
+	)
+}
+
+func templatesLogHtml() (*asset, error) {
+	bytes, err := templatesLogHtmlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/log.html", size: 127, mode: os.FileMode(438), modTime: time.Unix(1792383124, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesLogMd = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xd3\xd2\x72\x2a\xcd\xcc\x49\x51\xf0\xc9\x4f\x57\xa8\xae\xd6\x0b\x49\x2c\xce\xf6\x74\xa9\xad\xd5\xd2\xe2\xe5\xe2\xe5\x4a\x48\x48\xe0\xe5\x02\x8a\x02\x25\x6b\x6b\xa1\x5c\x00\x7a\x6b\x79\x51\x31\x00\x00\x00")
+
+func templatesLogMdBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesLogMd,
+		"templates/log.md",
+	)
+}
+
+func templatesLogMd() (*asset, error) {
+	bytes, err := templatesLogMdBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/log.md", size: 49, mode: os.FileMode(438), modTime: time.Unix(1792384418, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesLogTxt = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x2a\xcd\xcc\x49\x51\xf0\xc9\x4f\x57\xa8\xae\xd6\x0b\x49\x2c\xce\xf6\x74\xa9\xad\xe5\xe5\xe2\xe5\x02\x72\x81\xa2\x20\x36\x00\x85\x88\x66\x9f\x23\x00\x00\x00")
+
+func templatesLogTxtBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesLogTxt,
+		"templates/log.txt",
+	)
+}
+
+func templatesLogTxt() (*asset, error) {
+	bytes, err := templatesLogTxtBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/log.txt", size: 35, mode: os.FileMode(438), modTime: time.Unix(1792384622, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesMineHtml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x45\x4f\xbb\x6e\xc3\x30\x0c\xdc\x03\xe4\x1f\x88\x4c\xed\x50\x6b\x4f\x59\x0d\x6e\x97\x2e\x59\x82\x7e\x80\x64\xd1\xae\x50\x45\x2a\x28\x09\x48\x60\xe8\xdf\x4b\x25\x06\xba\xf1\x8e\xf7\x20\x31\xff\x9a\x08\xb9\xdc\x02\xbd\x1d\xa6\x14\x12\x1f\x9d\xe1\x9f\x31\x54\x7a\x2d\x74\x2d\x2f\x8e\xa6\xc4\xa6\xf8\x14\x8f\x35\x3a\xe2\xe0\x23\x1d\x34\xe6\xc2\x29\x2e\x7a\xac\x3e\xb8\x0c\x85\xfd\xb2\x10\x93\x03\x7b\x83\x75\x1d\xbe\x32\xf1\x70\x32\x17\x6a\x0d\x2d\x6b\x54\x9b\x5c\x06\xa9\xd3\xfb\xdd\xba\xfa\x19\x86\x87\x5b\x34\x35\xdc\x39\x36\x71\xa1\x7f\x7a\xbf\x03\x00\x0c\x5e\x4b\xe2\x7b\x8a\xb3\x5f\x3e\x3f\x5a\x83\x27\x81\xa3\x48\xa7\xef\xd6\x9e\x01\x6d\x5f\x9f\x8b\x29\xbd\x4c\x59\x0d\x1b\xac\x92\x00\x48\x97\xbe\xee\x3e\x54\x32\xa3\x92\xb8\x5e\x45\xd1\xf5\x02\x54\x52\x2d\x28\xe4\xed\x54\xa1\x44\x76\x4a\x60\x1f\x9f\xcd\x49\xbe\xbe\x5b\x37\xcf\x1f\x13\x33\x24\xd8\x31\x01\x00\x00")
+
+func templatesMineHtmlBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesMineHtml,
+		"templates/mine.html",
+	)
+}
+
+func templatesMineHtml() (*asset, error) {
+	bytes, err := templatesMineHtmlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/mine.html", size: 305, mode: os.FileMode(438), modTime: time.Unix(1792383124, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesMineMd = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x2d\x8e\x31\x0e\xc2\x30\x0c\x45\xf7\x4a\xbd\x83\x47\xb0\x44\x0e\x51\x58\x58\xba\x20\xe6\x28\x25\x6e\x88\x54\x12\x29\x4d\x86\x2a\xf2\xdd\x71\x08\xe3\x93\xdf\xfb\x32\xe2\x54\xfc\x66\x77\xc8\xc9\x3b\x47\x89\x2c\x2c\x07\xd4\xaa\x9e\x3b\x25\x35\x9b\x0f\x31\x23\x8e\xc3\x38\xd4\x9a\x4c\x70\x04\xaa\x07\xcc\x97\xa6\x5d\x63\x58\xbd\xbb\xdf\x98\xe1\x24\x38\x89\xf3\x7a\x33\x9f\x01\x51\xf0\x91\x4d\xfe\x0d\xc0\x1f\x8a\x74\xa0\x05\x5a\xa1\xdb\x28\x6d\xbb\x18\x7a\x8e\xb0\xf4\x47\xd6\x58\x82\xed\xa7\x60\x99\xbf\xb5\xd5\xe1\x19\xa1\x00\x00\x00")
+
+func templatesMineMdBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesMineMd,
+		"templates/mine.md",
+	)
+}
+
+func templatesMineMd() (*asset, error) {
+	bytes, err := templatesMineMdBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/mine.md", size: 161, mode: os.FileMode(438), modTime: time.Unix(1792384418, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesMineTxt = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x2a\xcd\xcc\x49\x29\x56\x28\x29\xca\x4c\x4f\x4f\x2d\x4a\x4d\x51\x48\xaa\x54\xa8\xae\xd6\x0b\x2d\x4e\x2d\xd2\xf3\x4b\xcc\x4d\xad\xad\xe5\xe5\xe2\xe5\xaa\xae\x2e\x4a\xcc\x4b\x4f\x55\xd0\x73\x02\x2b\xaf\xad\x55\x00\x29\x72\xce\xcf\x4b\xcb\x4c\xf7\x74\x01\x72\x35\x80\x5c\x27\xa0\x9a\xe4\x8c\xda\x5a\x4d\x90\x5c\x70\x49\x62\x09\x50\x33\x8c\x59\x5a\x0c\x61\x83\x14\x83\x8c\x4b\xcd\x29\x06\xca\xfa\xe5\x2b\x24\x41\xec\x4f\xcb\x2f\xcd\x4b\x01\x4b\xe4\xa5\xd4\xd6\x02\x00\x85\x43\x26\x78\x95\x00\x00\x00")
+
+func templatesMineTxtBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesMineTxt,
+		"templates/mine.txt",
+	)
+}
+
+func templatesMineTxt() (*asset, error) {
+	bytes, err := templatesMineTxtBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/mine.txt", size: 149, mode: os.FileMode(438), modTime: time.Unix(1792384622, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesSchedulesHtml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x3d\x4f\xbb\x6e\xc3\x30\x0c\xdc\x03\xe4\x1f\x88\x4c\x2d\xd0\xc6\x7b\xaa\x6a\x48\xba\x74\xc9\xd0\xa2\x1f\x20\x5b\x4c\x2c\x54\x26\x0b\x4a\xea\x03\x86\xff\xbd\x94\x6d\x64\xe3\x91\xf7\xe0\x99\xf4\xe5\x08\x52\xfe\x8b\xf8\xbc\xeb\x38\xb2\x1c\xbc\x93\xcf\x63\x2c\xf8\x94\xf1\x37\x3f\x7a\xec\x58\x5c\x0e\x4c\x87\x42\x1e\x25\x06\xc2\x9d\x35\x29\x0b\xd3\xd5\xbe\x77\x3d\xfa\x12\xd1\x43\x5b\x42\xf4\xc9\xb4\x62\x4d\xb3\x1e\x75\x50\x73\xbb\xdd\x8c\x63\xb8\xc0\x7e\x9a\x4c\x89\x33\x12\x47\x57\xac\x8b\xed\x06\x00\x4c\x0c\xd6\xb4\x76\x1c\xf7\xaf\x2f\xca\x69\x5a\x0b\x3a\x9f\x98\x2e\xe1\x5a\x37\x70\xa7\xf0\xa8\x9a\xae\x9f\xa6\x7b\x70\x19\x16\xf6\x49\x43\x16\xfe\x03\x90\xbe\x0a\x52\xa8\x2a\xcf\x3a\xab\xca\xe0\x50\x59\x1f\x09\xa5\xb2\x14\x99\x46\x93\x6a\x3e\x92\xaf\xd9\xa6\x99\xff\x79\xc3\x81\xbf\x11\x98\x10\x7e\x42\xee\xa1\x99\xab\x40\x5a\xab\x81\x2c\xf7\xe0\x55\x18\x13\xaa\x99\x96\x54\xb5\x3a\x9e\x79\xed\x0d\x4e\xf0\xa6\xf0\x10\x08\x72\x1f\x12\x08\xf3\x30\x47\xdf\x32\xff\x01\x4a\x6e\x32\xfa\x70\x01\x00\x00")
+
+func templatesSchedulesHtmlBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesSchedulesHtml,
+		"templates/schedules.html",
+	)
+}
+
+func templatesSchedulesHtml() (*asset, error) {
+	bytes, err := templatesSchedulesHtmlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/schedules.html", size: 368, mode: os.FileMode(438), modTime: time.Unix(1792386089, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesSchedulesMd = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x3d\x8e\xc1\x0e\x82\x30\x10\x44\xef\x24\xfc\xc3\x1c\xb5\x51\xfc\x07\xf5\xe2\xc5\x83\xc6\x33\x20\x5d\x68\x13\x68\x93\xb6\xa8\x49\xb3\xff\x6e\x2b\x86\xdb\x9b\xec\xcc\xec\x08\x71\xef\x14\xc9\x79\x24\x89\xe7\xac\x47\xe9\x85\x28\x8b\xb2\x88\xd1\xb5\x66\x20\x54\xcc\x7b\x08\x11\x63\x75\x39\x33\x0b\x81\x44\x27\x6b\x7a\x3d\x64\x8d\x4d\x92\xc7\xe4\xec\x14\xf3\x16\x6d\x40\x93\xef\xce\x1a\xe6\x66\x07\x43\x9f\x00\x37\x9b\x1c\xba\x26\x4e\x81\x3a\xe1\xc3\x93\x63\xae\xf3\x13\x1a\x3d\x25\xbc\xda\xff\x73\xb4\x8e\xe0\xd7\x45\xda\x20\x28\xed\xe1\xac\x9d\x16\xbf\x91\xcc\x31\xea\x3e\x0f\x2b\x8b\x1b\x4d\xf6\x45\xb0\x86\xf0\xd6\x41\xa1\x39\xfc\x6a\xd6\x06\xb8\xc5\xa0\x65\xb3\xa6\xbf\xb2\x06\xb8\xbd\xf2\x00\x00\x00")
+
+func templatesSchedulesMdBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesSchedulesMd,
+		"templates/schedules.md",
+	)
+}
+
+func templatesSchedulesMd() (*asset, error) {
+	bytes, err := templatesSchedulesMdBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/schedules.md", size: 242, mode: os.FileMode(438), modTime: time.Unix(1792386089, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesSchedulesTxt = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x3d\x4e\x3b\x0e\xc2\x30\x0c\xdd\x2b\xf5\x0e\x6f\x04\x09\xc1\x1d\x80\x85\xa5\x03\x88\x03\x94\xc6\x6d\x22\xb5\x8e\xe4\xa4\x80\x14\xf9\xee\xa4\x29\xea\x64\x3f\xbf\x8f\xdf\xa3\xb3\x64\xe6\x91\x0c\x5e\xb3\x1b\x4d\xa8\xab\xba\x4a\x49\x5a\x1e\x08\x47\x55\x20\xa5\xe3\xed\x9a\x97\x3c\x2f\x9e\x7b\x37\x14\xb4\xcb\xf0\x9c\x55\x9d\x55\xdd\xa3\x8d\x85\x16\xcf\xaa\x07\x30\x7d\x23\x64\xe6\xe5\xd6\xe4\x7d\x35\x3f\x03\x89\xea\x12\x4e\x63\x20\xd5\xc6\xff\x3f\xa2\x15\x42\xd8\x6a\x38\x46\xb4\x2e\x40\xbc\x9f\x8a\x9a\x8d\x6a\x4a\xae\x5f\xea\xd4\xd5\x9d\x26\xff\x26\x78\x26\x7c\x5c\xb4\x38\x95\x90\xcd\x0f\x59\x79\x67\x36\xef\x0f\xbc\x56\x5c\x2a\xe2\x00\x00\x00")
+
+func templatesSchedulesTxtBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesSchedulesTxt,
+		"templates/schedules.txt",
+	)
+}
+
+func templatesSchedulesTxt() (*asset, error) {
+	bytes, err := templatesSchedulesTxtBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/schedules.txt", size: 226, mode: os.FileMode(438), modTime: time.Unix(1792386089, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesStatusHtml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x75\xce\xb1\x0a\xc2\x30\x14\x05\xd0\xbd\xd0\x7f\x08\xdd\xb5\x7b\x8d\x19\xaa\x8b\xab\x7e\x41\xda\x3c\x6b\x30\x26\x25\x79\x0f\x94\xd2\x7f\x37\x35\xa5\x52\xd0\x21\x70\x2f\x39\x37\x84\x87\x5e\x5a\x16\xf0\x65\x60\x5f\xb4\xce\x38\x5f\x29\xe9\xef\xb5\x21\xd8\x21\x3c\x71\xa3\xa0\x75\x5e\xa2\x76\xb6\x22\xab\xc0\x1b\x6d\xa1\x10\x3c\xa0\x77\xb6\x13\x35\x69\xa3\xd8\x19\x02\x19\x64\x17\x94\x48\x81\x97\xf3\x5d\x0c\xf1\x6d\x91\x67\xbc\xf1\x62\x3a\x31\xc1\x23\xa6\x79\x75\x70\xf6\xaa\xbb\x8a\xf1\xb2\x11\xc3\xb0\x4d\xf5\x74\x1c\x47\x5e\x46\x96\x66\xdf\x89\x97\xb6\xbd\x2d\x38\xd5\xdf\x74\xfa\x06\x2c\xf2\xd3\xfe\x43\x0a\x2b\x49\x61\x45\x13\xcf\xb3\x37\x5e\x14\x3c\xaa\x26\x01\x00\x00")
 
 func templatesStatusHtmlBytes() ([]byte, error) {
 	return bindataRead(
@@ -146,7 +655,107 @@ func templatesStatusHtml() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "templates/status.html", size: 301, mode: os.FileMode(438), modTime: time.Unix(1500573691, 0)}
+	info := bindataFileInfo{name: "templates/status.html", size: 294, mode: os.FileMode(438), modTime: time.Unix(1792383124, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesStatusMd = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xd3\xd2\x72\x2a\xcd\xcc\x49\x51\x08\x4a\x2d\x2e\xcd\x29\x51\x08\x2e\x49\x2c\x29\x2d\xd6\xd2\xe2\xe5\xe2\xe5\xd2\x82\x4a\x39\xe7\xe7\xa5\x65\xa6\x5b\x69\x69\x29\x54\x57\xeb\x41\x38\x9e\x2e\xb5\xb5\x60\x05\x45\x89\x79\xc9\x19\x50\x29\x08\x07\x22\x01\x32\x27\x15\x2a\x0e\x66\x23\x84\x4b\x8b\x91\xc4\x4b\x8b\x41\x12\x00\x39\xf4\x46\x5c\x85\x00\x00\x00")
+
+func templatesStatusMdBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesStatusMd,
+		"templates/status.md",
+	)
+}
+
+func templatesStatusMd() (*asset, error) {
+	bytes, err := templatesStatusMdBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/status.md", size: 133, mode: os.FileMode(438), modTime: time.Unix(1792384418, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesStatusTxt = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x73\x2a\xcd\xcc\x49\x51\x08\x4a\x2d\x2e\xcd\x29\x51\x08\x2e\x49\x2c\x29\x2d\xe6\xe5\xe2\xe5\x72\x02\x0b\x3b\xe7\xe7\xa5\x65\xa6\x5b\x29\x54\x57\xeb\x41\x98\x9e\x2e\xb5\xb5\x40\xc9\xa2\xc4\xbc\xe4\x0c\xb0\x30\x84\x09\x12\x04\xe9\x4d\x05\x8b\x81\x59\x30\xa1\xd2\x62\xb8\x58\x69\x31\x48\x10\x00\x3d\x87\xa1\xab\x71\x00\x00\x00")
+
+func templatesStatusTxtBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesStatusTxt,
+		"templates/status.txt",
+	)
+}
+
+func templatesStatusTxt() (*asset, error) {
+	bytes, err := templatesStatusTxtBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/status.txt", size: 113, mode: os.FileMode(438), modTime: time.Unix(1792384622, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesWatchingHtml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x6d\x8f\xbd\x6e\xc3\x30\x0c\x84\x77\x03\x7e\x07\x22\x53\x3b\x34\xda\x53\x56\x43\xd2\xa5\x4b\x3a\x76\x96\x25\xc6\x16\xaa\x50\x81\x7e\x90\x06\x82\xdf\xbd\xb4\x9b\x76\xea\xa6\x3b\x7d\xbc\x23\x31\x5f\x0c\x43\x2e\xb7\x40\x2f\x1b\x1b\x43\x4c\x3b\x67\xd2\xe7\x3e\x54\x7a\x2e\xf4\x55\x9e\x1c\xd9\x98\x4c\xf1\x91\x77\x95\x1d\xa5\xe0\x99\x36\x1a\x73\x49\x91\x47\xfd\x61\x8a\x9d\xc8\xc1\x50\x7d\x70\x60\x23\x9f\xfc\x58\x7f\xf0\x8c\x43\xd2\xa8\xee\xa0\x3c\xa4\x48\xf7\x5d\x6b\xfe\x04\xdb\x79\xc6\x1a\x56\x95\x0c\x8f\xb4\x18\x7d\x07\x00\x18\xbc\xc6\x41\xb7\xb6\x3d\xac\x59\x6f\xaf\x42\xaa\xc5\x58\xa6\xf6\x02\xdb\x69\x9e\xe1\x41\x80\x5f\xf1\xd8\x1a\xb1\x13\xf3\x12\x73\xf1\x3c\x82\xfc\xbd\x73\xb8\x89\xb3\x6e\x95\x51\x49\xe8\x52\xb5\x62\x7d\x87\x4a\xaa\x45\x85\x4c\x12\x2e\x4b\x8a\x45\x67\x7d\x8c\xff\x5e\x01\x26\x11\x5c\xef\x67\x7a\x86\x32\xf9\x0c\x29\xc6\x33\x2a\x19\xfa\x0b\xfd\x06\x80\x46\x6a\x64\x48\x01\x00\x00")
+
+func templatesWatchingHtmlBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesWatchingHtml,
+		"templates/watching.html",
+	)
+}
+
+func templatesWatchingHtml() (*asset, error) {
+	bytes, err := templatesWatchingHtmlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/watching.html", size: 328, mode: os.FileMode(438), modTime: time.Unix(1792385764, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesWatchingMd = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x6d\x4e\xbd\x0e\xc2\x20\x10\xde\x9b\xf0\x0e\x37\x2a\x89\x7d\x08\x75\x71\xd1\xd1\xb1\xc1\x42\xdb\x4b\xf0\xce\x00\x4d\x63\xc8\xbd\xbb\x40\x74\x73\xfc\xfe\x3f\xad\xef\x26\x8d\x8b\xb3\xf0\x58\xd1\x5b\x18\x99\x26\x9c\xd7\x60\x12\x32\x45\xad\x55\xa7\xba\x9c\x83\xa1\xd9\x41\x2f\x72\x00\xad\x73\xee\x4f\xcd\x75\x39\x8b\x54\x88\x13\xf4\xc7\x62\x19\x17\x11\xd8\x15\xf9\x07\xf6\x39\x3b\xb2\x85\x7c\x71\x4c\x48\x33\x14\xed\x46\xfe\x5d\x98\xb6\x16\x6b\xb7\xf3\xd1\x89\x0c\x57\xfe\xfb\x00\x4c\x70\xb0\x7d\x2f\x22\x41\x5a\x30\x42\x60\x7e\x0e\x2d\x5b\xdb\x55\xf7\x01\x68\xd9\x07\x3d\xc5\x00\x00\x00")
+
+func templatesWatchingMdBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesWatchingMd,
+		"templates/watching.md",
+	)
+}
+
+func templatesWatchingMd() (*asset, error) {
+	bytes, err := templatesWatchingMdBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/watching.md", size: 197, mode: os.FileMode(438), modTime: time.Unix(1792385764, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _templatesWatchingTxt = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x6d\x8e\xbd\x0e\x42\x21\x0c\x85\xf7\x9b\xf0\x0e\x1d\x75\xe1\x21\xd4\xc5\x45\x47\x67\x84\x5e\x68\x82\xc5\x00\x37\xc6\x34\xbc\xbb\x40\x74\x73\xec\xf9\x7a\x7e\x6e\xa6\xda\x80\x0e\xee\x1b\x45\x07\x36\xf1\x4a\x7e\xcb\xa6\x52\xe2\xa2\x16\xb5\x88\x64\xc3\x1e\x41\xb7\x06\x20\xa2\x8f\xf3\xe3\x7c\x6a\x4d\x84\x56\xd0\x87\x8e\x6d\xe8\x70\xd7\xe1\xef\xd8\x8b\x20\xbb\x2e\x3e\x53\xa9\xc4\x7e\x18\xaf\x1c\xdf\x5d\x99\x3d\x65\xe4\x62\x2c\xd8\xda\x25\xfd\xad\x06\x93\x11\x5e\xdf\x6d\xc4\x50\x03\x15\xc8\x29\x3d\xa6\x73\x64\xab\xe5\x03\x93\x85\xb6\x93\xbb\x00\x00\x00")
+
+func templatesWatchingTxtBytes() ([]byte, error) {
+	return bindataRead(
+		_templatesWatchingTxt,
+		"templates/watching.txt",
+	)
+}
+
+func templatesWatchingTxt() (*asset, error) {
+	bytes, err := templatesWatchingTxtBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "templates/watching.txt", size: 187, mode: os.FileMode(438), modTime: time.Unix(1792385764, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
@@ -203,10 +812,39 @@ func AssetNames() []string {
 
 // _bindata is a table, holding each asset generator, mapped to its name.
 var _bindata = map[string]func() (*asset, error){
+	"templates/artifacts.html": templatesArtifactsHtml,
+	"templates/artifacts.md": templatesArtifactsMd,
+	"templates/artifacts.txt": templatesArtifactsTxt,
+	"templates/audit.html": templatesAuditHtml,
+	"templates/audit.md": templatesAuditMd,
+	"templates/audit.txt": templatesAuditTxt,
+	"templates/finished.html": templatesFinishedHtml,
+	"templates/finished.md": templatesFinishedMd,
+	"templates/finished.txt": templatesFinishedTxt,
 	"templates/help.html": templatesHelpHtml,
+	"templates/help.md": templatesHelpMd,
+	"templates/help.txt": templatesHelpTxt,
 	"templates/kick.html": templatesKickHtml,
+	"templates/kick.md": templatesKickMd,
+	"templates/kick.txt": templatesKickTxt,
 	"templates/list.html": templatesListHtml,
+	"templates/list.md": templatesListMd,
+	"templates/list.txt": templatesListTxt,
+	"templates/log.html": templatesLogHtml,
+	"templates/log.md": templatesLogMd,
+	"templates/log.txt": templatesLogTxt,
+	"templates/mine.html": templatesMineHtml,
+	"templates/mine.md": templatesMineMd,
+	"templates/mine.txt": templatesMineTxt,
+	"templates/schedules.html": templatesSchedulesHtml,
+	"templates/schedules.md": templatesSchedulesMd,
+	"templates/schedules.txt": templatesSchedulesTxt,
 	"templates/status.html": templatesStatusHtml,
+	"templates/status.md": templatesStatusMd,
+	"templates/status.txt": templatesStatusTxt,
+	"templates/watching.html": templatesWatchingHtml,
+	"templates/watching.md": templatesWatchingMd,
+	"templates/watching.txt": templatesWatchingTxt,
 }
 
 // AssetDir returns the file names below a certain
@@ -250,10 +888,39 @@ type bintree struct {
 }
 var _bintree = &bintree{nil, map[string]*bintree{
 	"templates": &bintree{nil, map[string]*bintree{
+		"artifacts.html": &bintree{templatesArtifactsHtml, map[string]*bintree{}},
+		"artifacts.md": &bintree{templatesArtifactsMd, map[string]*bintree{}},
+		"artifacts.txt": &bintree{templatesArtifactsTxt, map[string]*bintree{}},
+		"audit.html": &bintree{templatesAuditHtml, map[string]*bintree{}},
+		"audit.md": &bintree{templatesAuditMd, map[string]*bintree{}},
+		"audit.txt": &bintree{templatesAuditTxt, map[string]*bintree{}},
+		"finished.html": &bintree{templatesFinishedHtml, map[string]*bintree{}},
+		"finished.md": &bintree{templatesFinishedMd, map[string]*bintree{}},
+		"finished.txt": &bintree{templatesFinishedTxt, map[string]*bintree{}},
 		"help.html": &bintree{templatesHelpHtml, map[string]*bintree{}},
+		"help.md": &bintree{templatesHelpMd, map[string]*bintree{}},
+		"help.txt": &bintree{templatesHelpTxt, map[string]*bintree{}},
 		"kick.html": &bintree{templatesKickHtml, map[string]*bintree{}},
+		"kick.md": &bintree{templatesKickMd, map[string]*bintree{}},
+		"kick.txt": &bintree{templatesKickTxt, map[string]*bintree{}},
 		"list.html": &bintree{templatesListHtml, map[string]*bintree{}},
+		"list.md": &bintree{templatesListMd, map[string]*bintree{}},
+		"list.txt": &bintree{templatesListTxt, map[string]*bintree{}},
+		"log.html": &bintree{templatesLogHtml, map[string]*bintree{}},
+		"log.md": &bintree{templatesLogMd, map[string]*bintree{}},
+		"log.txt": &bintree{templatesLogTxt, map[string]*bintree{}},
+		"mine.html": &bintree{templatesMineHtml, map[string]*bintree{}},
+		"mine.md": &bintree{templatesMineMd, map[string]*bintree{}},
+		"mine.txt": &bintree{templatesMineTxt, map[string]*bintree{}},
+		"schedules.html": &bintree{templatesSchedulesHtml, map[string]*bintree{}},
+		"schedules.md": &bintree{templatesSchedulesMd, map[string]*bintree{}},
+		"schedules.txt": &bintree{templatesSchedulesTxt, map[string]*bintree{}},
 		"status.html": &bintree{templatesStatusHtml, map[string]*bintree{}},
+		"status.md": &bintree{templatesStatusMd, map[string]*bintree{}},
+		"status.txt": &bintree{templatesStatusTxt, map[string]*bintree{}},
+		"watching.html": &bintree{templatesWatchingHtml, map[string]*bintree{}},
+		"watching.md": &bintree{templatesWatchingMd, map[string]*bintree{}},
+		"watching.txt": &bintree{templatesWatchingTxt, map[string]*bintree{}},
 	}},
 }}
 
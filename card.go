@@ -0,0 +1,100 @@
+package main
+
+import (
+	"html/template"
+	"time"
+
+	"github.com/mkobaly/hipchatBot/chat"
+	"github.com/mkobaly/hipchatBot/ci"
+	"github.com/tbruyelle/hipchat-go/hipchat"
+)
+
+// resultIcon is the name of the static icon for the state of a build
+func resultIcon(b *ci.Build) string {
+	switch {
+	case !b.Finished():
+		return "build-running"
+	case b.Status == ci.StatusSuccess:
+		return "build-success"
+	}
+	return "build-failure"
+}
+
+// lozenge is the card attribute style for the state of a build
+func lozenge(b *ci.Build) string {
+	switch {
+	case !b.Finished():
+		return "lozenge-current"
+	case b.Status == ci.StatusSuccess:
+		return "lozenge-success"
+	case b.Status == ci.StatusFailure:
+		return "lozenge-error"
+	}
+	return "lozenge"
+}
+
+// buildCard lays out the kick, status and finished replies as a HipChat
+// application card. Icons are served from the bot's static folder below
+// baseURL. Other replies have no card.
+func buildCard(m chat.Message, baseURL string) *hipchat.Card {
+	var b *ci.Build
+	switch data := m.Data.(type) {
+	case *ci.Build:
+		if m.Template != "status" && m.Template != "finished" {
+			return nil
+		}
+		b = data
+	case chat.KickReply:
+		if m.Template != "kick" {
+			return nil
+		}
+		b = &ci.Build{ID: data.TaskID, ConfigID: data.BuildConfigID, Branch: data.Branch, State: ci.StateQueued, Status: ci.StatusUnknown, WebURL: data.WebURL}
+	default:
+		return nil
+	}
+
+	icon := &hipchat.Icon{
+		URL:   baseURL + "/" + resultIcon(b) + ".png",
+		URL2x: baseURL + "/" + resultIcon(b) + "@2x.png",
+	}
+	title := b.ConfigID
+	if b.Number != "" {
+		title += " #" + b.Number
+	}
+	card := &hipchat.Card{
+		Style:       hipchat.CardStyleApplication,
+		ID:          "build-" + b.ID,
+		Title:       title,
+		URL:         b.WebURL,
+		Description: hipchat.CardDescription{Format: "html", Value: renderTemplate(FormatHTML, m.Template, m.Data)},
+		Icon:        icon,
+	}
+	activity := "<b>" + template.HTMLEscapeString(b.ConfigID) + "</b> "
+	switch m.Template {
+	case "kick":
+		activity += "kicked off"
+	case "finished":
+		activity += "finished: " + template.HTMLEscapeString(b.Status)
+	default:
+		activity += template.HTMLEscapeString(b.State) + ": " + template.HTMLEscapeString(b.Status)
+	}
+	if b.Branch != "" {
+		activity += " on " + template.HTMLEscapeString(b.Branch)
+	}
+	if b.WebURL != "" {
+		activity = `<a href="` + template.HTMLEscapeString(b.WebURL) + `">` + activity + "</a>"
+	}
+	card.Activity = &hipchat.Activity{HTML: activity, Icon: icon}
+
+	card.AddAttribute("Config", b.ConfigID, b.WebURL, icon.URL)
+	card.Attributes = append(card.Attributes,
+		hipchat.Attribute{Label: "Branch", Value: hipchat.AttributeValue{Label: b.Branch}},
+		hipchat.Attribute{Label: "State", Value: hipchat.AttributeValue{Label: b.State}},
+		hipchat.Attribute{Label: "Status", Value: hipchat.AttributeValue{Label: b.Status, Style: lozenge(b)}},
+	)
+	if d := b.Duration(); d > 0 {
+		card.Attributes = append(card.Attributes,
+			hipchat.Attribute{Label: "Duration", Value: hipchat.AttributeValue{Label: d.Round(time.Second).String()}})
+	}
+	return card
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mkobaly/hipchatBot/chat"
+	"github.com/mkobaly/hipchatBot/ci"
+)
+
+func TestBuildCard(t *testing.T) {
+	started := time.Date(2017, 7, 14, 15, 0, 0, 0, time.UTC)
+	b := &ci.Build{
+		ID: "42", ConfigID: "Web_CI", Number: "12", Branch: "main",
+		State: ci.StateFinished, Status: ci.StatusFailure, WebURL: "https://ci/42",
+		Started: started, Ended: started.Add(95 * time.Second),
+	}
+	card := buildCard(chat.Message{Template: "finished", Data: b, Color: chat.ColorRed}, "https://bot")
+	if card == nil {
+		t.Fatal("no card for a finished build")
+	}
+	if card.Title != "Web_CI #12" || card.URL != "https://ci/42" || card.Icon.URL != "https://bot/build-failure.png" {
+		t.Errorf("unexpected card %+v", card)
+	}
+	if !strings.Contains(card.Activity.HTML, `href="https://ci/42"`) || !strings.Contains(card.Activity.HTML, "finished: FAILURE") {
+		t.Errorf("unexpected activity %q", card.Activity.HTML)
+	}
+	attrs := make(map[string]string)
+	for _, a := range card.Attributes {
+		attrs[a.Label] = a.Value.Label + " " + a.Value.Style
+	}
+	want := map[string]string{
+		"Config":   "Web_CI ",
+		"Branch":   "main ",
+		"State":    "finished ",
+		"Status":   "FAILURE lozenge-error",
+		"Duration": "1m35s ",
+	}
+	for label, v := range want {
+		if attrs[label] != v {
+			t.Errorf("attribute %v = %q, want %q", label, attrs[label], v)
+		}
+	}
+
+	kick := chat.KickReply{BuildConfigID: "Web_CI", Branch: "main", TaskID: "42"}
+	if card := buildCard(chat.Message{Template: "kick", Data: kick}, "https://bot"); card == nil || card.Icon.URL != "https://bot/build-running.png" {
+		t.Errorf("unexpected kick card %+v", card)
+	}
+	if card := buildCard(chat.Message{Template: "help"}, "https://bot"); card != nil {
+		t.Errorf("help should not be a card")
+	}
+}
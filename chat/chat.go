@@ -0,0 +1,90 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+)
+
+// Message colors. They started out as HipChat's notification colors and are
+// used by the other chat platforms to pick an accent or icon.
+const (
+	ColorGreen  = "green"
+	ColorYellow = "yellow"
+	ColorRed    = "red"
+	ColorGray   = "gray"
+)
+
+// User is the person that sent a command
+type User struct {
+	ID          string
+	Name        string
+	MentionName string
+}
+
+// String formats the user the way it is recorded on builds
+func (u User) String() string {
+	return fmt.Sprintf("%s (@%s)", u.Name, u.MentionName)
+}
+
+// Command is a bot command received from a chat platform
+type Command struct {
+	// Text is the full command, starting with "/build"
+	Text string
+	User User
+	// RoomID is the room or channel the command was sent in. Room settings
+	// in config.yaml are keyed by it.
+	RoomID   string
+	RoomName string
+	// Source names the chat platform, e.g. "HipChat" or "Slack"
+	Source string
+}
+
+// Message is a reply to a command. Template names the reply template
+// rendered with Data. Messages without a template are a short Text notice.
+type Message struct {
+	Template string
+	Data     interface{}
+	Text     string
+	Color    string
+}
+
+// Replier sends the replies to one command back to where it came from
+type Replier interface {
+	Reply(ctx context.Context, m Message) error
+}
+
+// Notifier is implemented by Repliers that can also send messages after the
+// command has been answered, like the result of a build it kicked off
+type Notifier interface {
+	Notify(ctx context.Context, m Message) error
+}
+
+// Dispatcher runs a command, sending its replies through r
+type Dispatcher func(ctx context.Context, cmd Command, r Replier)
+
+// ServerConfigs is one CI server's part of the list reply
+type ServerConfigs struct {
+	Server string
+	IDs    []string
+	Error  bool
+}
+
+// KickReply is the data of the kick reply. Schedule is the cron expression
+// of a scheduled build.
+type KickReply struct {
+	BuildConfigID string
+	Branch        string
+	Revision      string
+	TaskID        string
+	Server        string
+	WebURL        string
+	Schedule      string
+}
+
+// Heading is the title of the kick reply
+func (k KickReply) Heading() string {
+	if k.Schedule != "" {
+		return "Scheduled build kicked off"
+	}
+	return "Build kicked off"
+}
@@ -0,0 +1,109 @@
+package ci
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrReadOnly is returned when a build is started or cancelled on a server
+// the bot only has read access to
+var ErrReadOnly = errors.New("CI server is configured read-only so builds can not be started or cancelled")
+
+// ChatUserParam is the build parameter holding the chat user that asked for the build
+const ChatUserParam = "triggeredBy.chatUser"
+
+// Build states shared by every provider
+const (
+	StateQueued   = "queued"
+	StateRunning  = "running"
+	StateFinished = "finished"
+)
+
+// Build statuses shared by every provider. They follow TeamCity's naming
+// since that was the first backend.
+const (
+	StatusSuccess = "SUCCESS"
+	StatusFailure = "FAILURE"
+	StatusUnknown = "UNKNOWN"
+)
+
+// BuildRequest is what the bot asks a CI server to build
+type BuildRequest struct {
+	ConfigID string
+	Branch   string
+	Revision string
+	Comment  string
+	// User is the chat user the build is run on behalf of
+	User   string
+	Params map[string]string
+}
+
+// Build is a queued, running or finished build on any CI server
+type Build struct {
+	// ID is what the chat user passes back to status, cancel, log and artifacts
+	ID         string
+	ConfigID   string
+	Branch     string
+	Number     string
+	State      string
+	Status     string
+	StatusText string
+	WebURL     string
+	// Started and Ended are zero when the build has not started or ended, or
+	// the server does not say
+	Started time.Time
+	Ended   time.Time
+}
+
+// Finished reports whether the build is done
+func (b *Build) Finished() bool {
+	return b.State == StateFinished
+}
+
+// Duration is how long the build ran, or has been running so far
+func (b *Build) Duration() time.Duration {
+	if b.Started.IsZero() {
+		return 0
+	}
+	if b.Ended.IsZero() {
+		return time.Since(b.Started)
+	}
+	return b.Ended.Sub(b.Started)
+}
+
+// Artifact is a file published by a build
+type Artifact struct {
+	Name string
+	Size int64
+	URL  string
+}
+
+// Provider is a CI server the bot can drive
+type Provider interface {
+	// List returns the IDs of the build configurations that can be kicked off
+	List(ctx context.Context) ([]string, error)
+	Trigger(ctx context.Context, req BuildRequest) (*Build, error)
+	Status(ctx context.Context, id string) (*Build, error)
+	Cancel(ctx context.Context, id string, comment string) error
+	Log(ctx context.Context, id string) (string, error)
+	Artifacts(ctx context.Context, id string) ([]Artifact, error)
+}
+
+// UserBuilds is implemented by providers that can find the builds a chat
+// user triggered
+type UserBuilds interface {
+	BuildsTriggeredBy(ctx context.Context, user string, count int) ([]*Build, error)
+}
+
+// Queue is implemented by providers that can list the builds waiting to
+// start
+type Queue interface {
+	Queued(ctx context.Context) ([]*Build, error)
+}
+
+// History is implemented by providers that can list the latest finished
+// builds of a configuration, newest first. An empty branch means any branch.
+type History interface {
+	FinishedBuilds(ctx context.Context, configID string, branch string, count int) ([]*Build, error)
+}
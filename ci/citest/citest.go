@@ -0,0 +1,41 @@
+// Package citest has the stand-in servers the CI providers are tested against
+package citest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// StandIn is a minimal CI server. Tests add the API it answers with to the
+// ServeMux and Record the calls they want to check.
+type StandIn struct {
+	*http.ServeMux
+	URL   string
+	mu    sync.Mutex
+	calls []string
+}
+
+// NewStandIn starts a stand-in server that is closed when the test ends
+func NewStandIn(t *testing.T) *StandIn {
+	s := &StandIn{ServeMux: http.NewServeMux()}
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+	s.URL = ts.URL
+	return s
+}
+
+// Record adds a call to the ones made so far
+func (s *StandIn) Record(call string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, call)
+}
+
+// Calls returns the calls recorded so far, in order
+func (s *StandIn) Calls() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.calls...)
+}
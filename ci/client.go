@@ -0,0 +1,179 @@
+package ci
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"time"
+)
+
+// How long a request to a CI server may take, how often a failed one is
+// retried and how long the first retry waits
+const (
+	DefaultTimeout = 30 * time.Second
+	DefaultRetries = 3
+	DefaultBackoff = 500 * time.Millisecond
+)
+
+// Error is returned for every non 2xx response from a CI server. Server is
+// the kind of server that answered, like "jenkins".
+type Error struct {
+	Server     string
+	Method     string
+	URL        string
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+	return fmt.Sprintf("%s: %s %s: %d %s", e.Server, e.Method, e.URL, e.StatusCode, msg)
+}
+
+// Client sends requests to the HTTP API of a CI server. Failed requests are
+// retried with exponential backoff as long as that can not run anything
+// twice: GETs on any 5xx or transport error, other requests only when they
+// can not have reached the server, on a failed dial or a 503.
+type Client struct {
+	// Server is the kind of server, as the errors name it
+	Server  string
+	HTTP    *http.Client
+	Retries int
+	Backoff time.Duration
+	// Prepare sets the credentials and headers the server wants on every
+	// request
+	Prepare func(req *http.Request)
+	// ErrorMessage reads the reason out of a failed response and its body
+	ErrorMessage func(resp *http.Response, body []byte) string
+}
+
+// NewClient returns a client with the default timeout, retries and backoff
+func NewClient(server string) *Client {
+	return &Client{
+		Server:  server,
+		HTTP:    &http.Client{Timeout: DefaultTimeout},
+		Retries: DefaultRetries,
+		Backoff: DefaultBackoff,
+	}
+}
+
+// Do sends the request with body and header, retrying it while that is safe.
+// It returns the response to a 2xx answer, whose body the caller closes, or
+// the error, an *Error when the server answered.
+func (c *Client) Do(ctx context.Context, method string, url string, body []byte, header http.Header) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, method, url, body, header)
+		if err == nil && resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+			return resp, nil
+		}
+		retry := false
+		if err == nil {
+			err = c.decodeError(method, url, resp)
+			retry = retryable(method, resp.StatusCode)
+		} else {
+			retry = method == "GET" || dialError(err)
+		}
+		if !retry || attempt >= c.Retries || ctx.Err() != nil {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(c.Backoff << uint(attempt)):
+		}
+	}
+}
+
+// JSON sends in, unless it is nil, as JSON and decodes the response into out.
+// out may be nil to drop the response, or a *bytes.Buffer to receive it as
+// text.
+func (c *Client) JSON(ctx context.Context, method string, url string, in interface{}, out interface{}) error {
+	header := make(http.Header)
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return err
+		}
+		header.Set("Content-Type", "application/json")
+	}
+	buf, text := out.(*bytes.Buffer)
+	if text {
+		header.Set("Accept", "text/plain")
+	} else {
+		header.Set("Accept", "application/json")
+	}
+	resp, err := c.Do(ctx, method, url, body, header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch {
+	case out == nil:
+		_, err = io.Copy(ioutil.Discard, resp.Body)
+	case text:
+		_, err = io.Copy(buf, resp.Body)
+	default:
+		err = json.NewDecoder(resp.Body).Decode(out)
+	}
+	return err
+}
+
+func (c *Client) send(ctx context.Context, method string, url string, body []byte, header http.Header) (*http.Response, error) {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, url, r)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if c.Prepare != nil {
+		c.Prepare(req)
+	}
+	return c.HTTP.Do(req)
+}
+
+// decodeError builds the Error of a failed response
+func (c *Client) decodeError(method string, url string, resp *http.Response) error {
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+	e := &Error{Server: c.Server, Method: method, URL: url, StatusCode: resp.StatusCode}
+	if c.ErrorMessage != nil {
+		e.Message = c.ErrorMessage(resp, body)
+	}
+	return e
+}
+
+// retryable reports whether a request answered with status may be sent again.
+// A 503 is refused before the request is handled. Any other 5xx, a 502 from a
+// proxy included, may come after a POST was acted on.
+func retryable(method string, status int) bool {
+	switch {
+	case status == http.StatusServiceUnavailable:
+		return true
+	case status >= 500:
+		return method == "GET"
+	}
+	return false
+}
+
+// dialError reports whether the request failed before a connection to the
+// server was made
+func dialError(err error) bool {
+	var op *net.OpError
+	return errors.As(err, &op) && op.Op == "dial"
+}
@@ -0,0 +1,62 @@
+package ci
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// transport answers every request with status, or fails it with err
+type transport struct {
+	status int
+	err    error
+	calls  int
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.calls++
+	if t.err != nil {
+		return nil, t.err
+	}
+	return &http.Response{StatusCode: t.status, Body: http.NoBody, Header: make(http.Header), Request: req}, nil
+}
+
+func TestClientRetries(t *testing.T) {
+	dial := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	read := &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset")}
+	for _, tc := range []struct {
+		method string
+		status int
+		err    error
+		calls  int
+	}{
+		{"GET", http.StatusInternalServerError, nil, 4},
+		{"GET", http.StatusNotFound, nil, 1},
+		{"GET", http.StatusBadGateway, nil, 4},
+		{"GET", 0, read, 4},
+		{"POST", http.StatusInternalServerError, nil, 1},
+		{"POST", http.StatusBadGateway, nil, 1},
+		{"POST", http.StatusGatewayTimeout, nil, 1},
+		{"POST", http.StatusServiceUnavailable, nil, 4},
+		{"POST", 0, read, 1},
+		{"POST", 0, dial, 4},
+	} {
+		tr := &transport{status: tc.status, err: tc.err}
+		c := NewClient("test")
+		c.HTTP.Transport = tr
+		c.Backoff = time.Millisecond
+		_, err := c.Do(context.Background(), tc.method, "http://ci/api", nil, nil)
+		if err == nil {
+			t.Errorf("%v %v %v: expected error", tc.method, tc.status, tc.err)
+		}
+		if tr.calls != tc.calls {
+			t.Errorf("%v %v %v: expected %v calls got %v", tc.method, tc.status, tc.err, tc.calls, tr.calls)
+		}
+		if e, ok := err.(*Error); tc.err == nil && (!ok || e.StatusCode != tc.status || e.Server != "test") {
+			t.Errorf("%v %v: unexpected error %v", tc.method, tc.status, err)
+		}
+	}
+}
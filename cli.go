@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"strings"
+
+	"github.com/mkobaly/hipchatBot/chat"
+)
+
+// terminalReplier prints replies for the repl and exec modes
+type terminalReplier struct {
+	w      io.Writer
+	failed bool
+}
+
+func (t *terminalReplier) Reply(ctx context.Context, m chat.Message) error {
+	if m.Color == chat.ColorRed {
+		t.failed = true
+	}
+	text := m.Text
+	if m.Template != "" {
+		text = renderTemplate(FormatText, m.Template, m.Data)
+	} else if m.Color == chat.ColorRed {
+		text = "error: " + text
+	}
+	_, err := fmt.Fprintln(t.w, text)
+	return err
+}
+
+// localUser is the chat user commands typed at the terminal run as
+func localUser() chat.User {
+	u := chat.User{Name: "local", MentionName: "local"}
+	if cur, err := user.Current(); err == nil {
+		u.ID = cur.Uid
+		u.MentionName = cur.Username
+		u.Name = cur.Name
+		if u.Name == "" {
+			u.Name = cur.Username
+		}
+	}
+	return u
+}
+
+// terminalCommand makes "/build" optional for commands typed at the terminal
+func terminalCommand(line string) string {
+	line = strings.TrimSpace(line)
+	if line == "/build" || strings.HasPrefix(line, "/build ") {
+		return line
+	}
+	return strings.TrimSpace("/build " + strings.TrimPrefix(line, "build "))
+}
+
+// exec runs one command, reporting whether it failed
+func (c *Context) exec(ctx context.Context, text string, roomID string, w io.Writer) bool {
+	cmd := chat.Command{
+		Text:   terminalCommand(text),
+		User:   localUser(),
+		RoomID: roomID,
+		Source: "the command line",
+	}
+	r := &terminalReplier{w: w}
+	c.dispatch(ctx, cmd, r)
+	return !r.failed
+}
+
+// repl runs the commands read from in until it ends or "exit" is typed
+func (c *Context) repl(ctx context.Context, in io.Reader, roomID string, w io.Writer) {
+	s := bufio.NewScanner(in)
+	fmt.Fprint(w, "> ")
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		switch line {
+		case "":
+		case "exit", "quit":
+			return
+		default:
+			c.exec(ctx, line, roomID, w)
+		}
+		fmt.Fprint(w, "> ")
+	}
+	fmt.Fprintln(w)
+}
+
+// runLocal runs the repl or exec mode given on the command line, returning
+// the process exit code
+func (c *Context) runLocal(args []string, roomID string) int {
+	ctx := context.Background()
+	switch args[0] {
+	case "repl":
+		c.repl(ctx, os.Stdin, roomID, os.Stdout)
+		return 0
+	case "exec":
+		if len(args) < 2 {
+			fmt.Fprintln(os.Stderr, `usage: hipchatBot exec "/build status 123"`)
+			return 2
+		}
+		// the process is gone before a kick could be confirmed
+		ctx = context.WithValue(ctx, oneShotKey{}, true)
+		if !c.exec(ctx, strings.Join(args[1:], " "), roomID, os.Stdout) {
+			return 1
+		}
+		return 0
+	}
+	fmt.Fprintf(os.Stderr, "unknown mode %q, use repl or exec\n", args[0])
+	return 2
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mkobaly/hipchatBot/config"
+)
+
+func TestTerminalCommand(t *testing.T) {
+	for in, want := range map[string]string{
+		"/build status 123": "/build status 123",
+		"build status 123":  "/build status 123",
+		"status 123":        "/build status 123",
+		"  --help ":         "/build --help",
+	} {
+		if got := terminalCommand(in); got != want {
+			t.Errorf("terminalCommand(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestExec(t *testing.T) {
+	cfg := &config.Config{
+		DefaultServer: "default",
+		TeamcityServers: map[string]config.UserCredential{
+			"default": {URL: "http://teamcity.invalid/", Username: "bot", Password: "secret"},
+		},
+	}
+	c := &Context{
+		rooms:     make(map[string]*RoomConfig),
+		providers: newProviders(cfg),
+		cfg:       cfg,
+	}
+	ctx := context.Background()
+
+	var out bytes.Buffer
+	if !c.exec(ctx, "/build --help", "", &out) || !strings.Contains(out.String(), "/build list") {
+		t.Errorf("help not printed: %q", out.String())
+	}
+
+	out.Reset()
+	if c.exec(ctx, "status 123 --server nope", "", &out) {
+		t.Errorf("unknown server did not fail")
+	}
+	if !strings.HasPrefix(out.String(), "error: unknown CI server") {
+		t.Errorf("unexpected output %q", out.String())
+	}
+
+	out.Reset()
+	c.repl(ctx, strings.NewReader("--help\nexit\nstatus 1 --server nope\n"), "", &out)
+	if !strings.Contains(out.String(), "/build list") || strings.Contains(out.String(), "unknown CI server") {
+		t.Errorf("unexpected repl output %q", out.String())
+	}
+}
+
+func TestExecProtected(t *testing.T) {
+	c, p := settingsContext(t)
+	c.cfg.Protected = []string{"*_RC"}
+	oneShot := context.WithValue(context.Background(), oneShotKey{}, true)
+
+	var out bytes.Buffer
+	if c.exec(oneShot, "kick Web_RC main", "", &out) {
+		t.Errorf("protected kick did not fail")
+	}
+	if !strings.Contains(out.String(), "kick it off from the repl or a chat room") || len(p.kicked) != 0 {
+		t.Errorf("unexpected output %q and builds %+v", out.String(), p.kicked)
+	}
+	out.Reset()
+	if !c.exec(oneShot, "kick Web_CI main", "", &out) || len(p.kicked) != 1 {
+		t.Errorf("unprotected kick failed: %q", out.String())
+	}
+
+	// the repl stays around to take the confirmation
+	out.Reset()
+	c.repl(context.Background(), strings.NewReader("kick Web_RC main\n"), "", &out)
+	text := out.String()
+	i := strings.LastIndex(text, "confirm ")
+	j := strings.Index(text, " within")
+	if i < 0 || j < i {
+		t.Fatalf("no confirmation asked for: %q", text)
+	}
+	out.Reset()
+	if !c.exec(context.Background(), "/build confirm "+text[i+len("confirm "):j], "", &out) || len(p.kicked) != 2 {
+		t.Errorf("confirmed kick failed: %q", out.String())
+	}
+}
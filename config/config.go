@@ -1,22 +1,370 @@
 package config
 
 import (
+	"fmt"
 	"io/ioutil"
+	"path"
+	"strings"
 
 	yaml "gopkg.in/yaml.v2"
 )
 
+//CI server types
+const (
+	TypeTeamcity = "teamcity"
+	TypeJenkins  = "jenkins"
+	TypeGitlab   = "gitlab"
+	TypeGithub   = "github"
+)
+
+//UserCredential is how the bot logs into a CI server. Token takes precedence
+//over Username/Password and Guest uses TeamCity guest access with no login.
+//ReadOnly is an optional second credential used for commands that only read
+//from TeamCity. Type picks the kind of server and defaults to TeamCity.
+//ListFilter holds the build configuration patterns shown by list. Repos are
+//the "owner/repo" repositories whose workflows a GitHub server lists.
+//WebhookSecret is the shared secret of the build events a TeamCity server
+//posts to /teamcity/webhook.
 type UserCredential struct {
-	URL      string
-	Username string
-	Password string
+	Type          string
+	URL           string
+	Username      string
+	Password      string
+	Token         string
+	Guest         bool
+	ReadOnly      *UserCredential `yaml:"readonly"`
+	ListFilter    []string        `yaml:"listfilter"`
+	Repos         []string
+	WebhookSecret string `yaml:"webhooksecret"`
+}
+
+//ServerType returns Type, defaulting to TeamCity
+func (u UserCredential) ServerType() string {
+	if u.Type == "" {
+		return TypeTeamcity
+	}
+	return u.Type
+}
+
+//Listed reports whether list should show the build configuration id. TeamCity
+//servers without a filter keep showing only the _RC and _CI configurations.
+func (u UserCredential) Listed(id string) bool {
+	patterns := u.ListFilter
+	if patterns == nil && u.ServerType() == TypeTeamcity {
+		patterns = []string{"*_RC", "*_CI"}
+	}
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, id); ok {
+			return true
+		}
+	}
+	return false
+}
+
+//Route sends build configurations matching the Match pattern to Server
+type Route struct {
+	Match  string
+	Server string
+}
+
+//Notify settings of a room
+const (
+	NotifyAll      = "all"
+	NotifyFailures = "failures"
+	NotifyNone     = "none"
+)
+
+//RoomSettings are the per room overrides, keyed by HipChat room ID or Slack
+//channel ID. Projects and Filters limit the build configurations the room
+//can see and kick to those in the projects or matching the patterns. Branch
+//is kicked when no branch is given, Notify picks which build results are
+//posted and AllowedUsers, when set, are the only users who can run commands
+//in the room. Users are named the way Permissions names them.
+type RoomSettings struct {
+	Server       string   `json:"server,omitempty"`
+	Projects     []string `json:"projects,omitempty"`
+	Filters      []string `json:"filters,omitempty"`
+	Branch       string   `json:"branch,omitempty"`
+	Notify       string   `json:"notify,omitempty"`
+	AllowedUsers []string `yaml:"allowedusers" json:"allowedUsers,omitempty"`
+}
+
+//Visible reports whether the room can see the build configuration id. A
+//TeamCity configuration is in a project when its id starts with the project
+//id and an underscore.
+func (r RoomSettings) Visible(id string) bool {
+	if len(r.Projects) > 0 {
+		found := false
+		for _, p := range r.Projects {
+			if strings.HasPrefix(id, p+"_") {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(r.Filters) == 0 {
+		return true
+	}
+	for _, p := range r.Filters {
+		if ok, _ := path.Match(p, id); ok {
+			return true
+		}
+	}
+	return false
+}
+
+//Allowed reports whether the user of the chat source may run commands in
+//the room
+func (r RoomSettings) Allowed(source string, userID string, mentionName string) bool {
+	if len(r.AllowedUsers) == 0 {
+		return true
+	}
+	for _, u := range r.AllowedUsers {
+		if namesUser(u, source, userID, mentionName) {
+			return true
+		}
+	}
+	return false
+}
+
+//Notifies reports whether the result of a finished build is posted to the
+//room
+func (r RoomSettings) Notifies(success bool) bool {
+	switch r.Notify {
+	case NotifyNone:
+		return false
+	case NotifyFailures:
+		return !success
+	}
+	return true
+}
+
+//SlackSettings connect the bot to a Slack app. SigningSecret verifies the
+//requests Slack sends and BotToken posts the replies to app mentions.
+type SlackSettings struct {
+	SigningSecret string `yaml:"signingsecret"`
+	BotToken      string `yaml:"bottoken"`
+}
+
+//MattermostSettings connect the bot to a Mattermost slash command. Token is
+//the slash command's token and WebhookURL an optional incoming webhook used
+//to post build results, as Username when the webhook allows it.
+type MattermostSettings struct {
+	Token      string
+	WebhookURL string `yaml:"webhookurl"`
+	Username   string
+}
+
+//TeamsSettings connect the bot to a Microsoft Teams outgoing webhook. Secret
+//is the security token Teams shows when the webhook is created. WebhookURL
+//is an optional incoming webhook used to post build results.
+type TeamsSettings struct {
+	Secret     string
+	WebhookURL string `yaml:"webhookurl"`
+}
+
+//Role is what the users given it can do. Commands are patterns of the
+//commands they can run, like "kick" or "schedule list", and Configs patterns
+//of the build configurations they can run them on. "*" allows everything.
+//Only Admin roles can read the audit log, no command pattern allows it.
+type Role struct {
+	Commands []string
+	Configs  []string
+	Admin    bool
+}
+
+//Permissions give users a role. HipChat users are named by user ID or
+//mention name, users of the other chats by "source:user ID", like
+//"slack:U123" or "mattermost:8x4q". Mention names of the other chats are
+//picked by the users themselves so they are never matched. Users not listed
+//get the Default role. Without any roles everyone can do
+//everything.
+type Permissions struct {
+	Default string
+	Roles   map[string]Role
+	Users   map[string]string
+}
+
+//Enabled reports whether commands are limited by role
+func (p Permissions) Enabled() bool {
+	return len(p.Roles) > 0
+}
+
+//RoleOf returns the name of the role of the user of the chat source
+func (p Permissions) RoleOf(source string, userID string, mentionName string) string {
+	for user, role := range p.Users {
+		if namesUser(user, source, userID, "") {
+			return role
+		}
+	}
+	for user, role := range p.Users {
+		if namesUser(user, source, "", mentionName) {
+			return role
+		}
+	}
+	return p.Default
+}
+
+//Allows reports whether the role lets its users run the command on the
+//build configuration. configID is empty for commands not about one.
+func (p Permissions) Allows(role string, command string, configID string) bool {
+	if !p.Enabled() {
+		return true
+	}
+	r, ok := p.Roles[role]
+	if !ok || !matchAny(r.Commands, command) {
+		return false
+	}
+	return configID == "" || matchAny(r.Configs, configID)
+}
+
+//IsAdmin reports whether the role is an admin role
+func (p Permissions) IsAdmin(role string) bool {
+	return p.Roles[role].Admin
+}
+
+//Validate checks that the users and the default get roles that exist
+func (p Permissions) Validate() error {
+	if !p.Enabled() {
+		return nil
+	}
+	if _, ok := p.Roles[p.Default]; p.Default != "" && !ok {
+		return fmt.Errorf("default role %q is not defined", p.Default)
+	}
+	for user, role := range p.Users {
+		if _, ok := p.Roles[role]; !ok {
+			return fmt.Errorf("role %q of %v is not defined", role, user)
+		}
+	}
+	return nil
+}
+
+//namesUser reports whether name, from a users list, is the user of the chat
+//source. Names without a source are HipChat user IDs or mention names.
+func namesUser(name string, source string, userID string, mentionName string) bool {
+	if i := strings.Index(name, ":"); i >= 0 {
+		return userID != "" && strings.EqualFold(name[:i], source) && name[i+1:] == userID
+	}
+	if !strings.EqualFold(source, "HipChat") {
+		return false
+	}
+	name = strings.TrimPrefix(name, "@")
+	return (userID != "" && name == userID) || (mentionName != "" && strings.EqualFold(name, mentionName))
+}
+
+//matchAny reports whether s matches one of the patterns
+func matchAny(patterns []string, s string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, s); ok {
+			return true
+		}
+	}
+	return false
 }
 
+//Limits cap the builds kicked off through the bot, so one person can not
+//flood the agents. UserKicksPerHour and RoomKicksPerHour are the kicks a user
+//and a room can make in an hour, ConcurrentBuilds the builds of a build
+//configuration kicked off by the bot that can be queued or running at once.
+//Zero is no limit.
+type Limits struct {
+	UserKicksPerHour int `yaml:"userkicksperhour"`
+	RoomKicksPerHour int `yaml:"roomkicksperhour"`
+	ConcurrentBuilds int `yaml:"concurrentbuilds"`
+}
+
+//AuditLog is the file every command and every build the bot kicks off is
+//logged to, as JSON lines. It is rotated once it grows over MaxSize
+//megabytes, keeping Backups old files.
+type AuditLog struct {
+	File    string
+	MaxSize int `yaml:"maxsize"`
+	Backups int
+}
+
+//Config is the bot configuration read from config.yaml. TemplatesDir is an
+//optional folder of reply templates overriding the built in ones. StateFile
+//is where the settings changed from the chat rooms are saved. Protected are
+//patterns of the build configurations whose kick has to be confirmed.
 type Config struct {
-	HipchatURL string
-	Port       int
-	NgrokURL   string
-	Teamcity   UserCredential
+	HipchatURL      string
+	Port            int
+	NgrokURL        string
+	TemplatesDir    string `yaml:"templates_dir"`
+	StateFile       string `yaml:"statefile"`
+	Teamcity        UserCredential
+	TeamcityServers map[string]UserCredential `yaml:"teamcityservers"`
+	DefaultServer   string                    `yaml:"defaultserver"`
+	Rooms           map[string]RoomSettings
+	Routes          []Route
+	Slack           SlackSettings
+	Mattermost      MattermostSettings
+	Teams           TeamsSettings
+	Permissions     Permissions
+	Protected       []string
+	Limits          Limits
+	Audit           AuditLog
+}
+
+//IsProtected reports whether kicking off the build configuration has to be
+//confirmed
+func (c *Config) IsProtected(buildConfigID string) bool {
+	return matchAny(c.Protected, buildConfigID)
+}
+
+//LegacyServerName is the name given to the single "teamcity" server entry
+const LegacyServerName = "default"
+
+//Servers returns every configured TeamCity server by name. The single
+//"teamcity" entry from older config files is included as "default".
+func (c *Config) Servers() map[string]UserCredential {
+	servers := make(map[string]UserCredential)
+	for name, creds := range c.TeamcityServers {
+		servers[name] = creds
+	}
+	if c.Teamcity.URL != "" {
+		if _, ok := servers[LegacyServerName]; !ok {
+			servers[LegacyServerName] = c.Teamcity
+		}
+	}
+	return servers
+}
+
+//Route returns the server of the first route matching the build
+//configuration, or "" when none does
+func (c *Config) Route(buildConfigID string) string {
+	for _, r := range c.Routes {
+		if ok, _ := path.Match(r.Match, buildConfigID); ok {
+			return r.Server
+		}
+	}
+	return ""
+}
+
+//ServerForRoom returns the name of the TeamCity server a room uses when a
+//command does not pick one
+func (c *Config) ServerForRoom(roomID string) string {
+	if rs, ok := c.Rooms[roomID]; ok && rs.Server != "" {
+		return rs.Server
+	}
+	if c.DefaultServer != "" {
+		return c.DefaultServer
+	}
+	servers := c.Servers()
+	if _, ok := servers[LegacyServerName]; ok {
+		return LegacyServerName
+	}
+	if len(servers) == 1 {
+		for name := range servers {
+			return name
+		}
+	}
+	return ""
 }
 
 //NewConfig creates a new Configuration object needed
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/mkobaly/hipchatBot/chat"
+)
+
+// confirmTimeout is how long a kick of a protected build configuration waits
+// for its confirmation
+var confirmTimeout = 2 * time.Minute
+
+// confirmedKey marks the context of a kick that has been confirmed
+type confirmedKey struct{}
+
+// oneShotKey marks the context of a command run by exec. Its process is gone
+// before a confirmation could be sent, so protected kicks are refused.
+type oneShotKey struct{}
+
+// pendingKick is a kick waiting for its confirmation
+type pendingKick struct {
+	command chat.Command
+	expires time.Time
+}
+
+// confirmations are the kicks waiting for a confirmation, by token
+type confirmations struct {
+	mu      sync.Mutex
+	pending map[string]pendingKick
+}
+
+func newConfirmations() *confirmations {
+	return &confirmations{pending: make(map[string]pendingKick)}
+}
+
+// add keeps the kick until it is confirmed, returning the token confirming it
+func (cs *confirmations) add(command chat.Command, now time.Time) (string, error) {
+	b := make([]byte, 3)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(b)
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	for t, p := range cs.pending {
+		if now.After(p.expires) {
+			delete(cs.pending, t)
+		}
+	}
+	cs.pending[token] = pendingKick{command: command, expires: now.Add(confirmTimeout)}
+	return token, nil
+}
+
+// confirm hands out the kick of the token when it is confirmed in time, by
+// the user who asked for it, in the same room. A kick is only handed out
+// once. The reply explains why the confirmation was turned down.
+func (cs *confirmations) confirm(token string, by chat.Command, now time.Time) (chat.Command, *chat.Message) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	p, ok := cs.pending[token]
+	switch {
+	case !ok:
+		return chat.Command{}, &chat.Message{Text: "There is no build waiting for confirmation " + token, Color: chat.ColorYellow}
+	case now.After(p.expires):
+		delete(cs.pending, token)
+		return chat.Command{}, &chat.Message{Text: "Confirmation " + token + " has expired, kick off the build again", Color: chat.ColorYellow}
+	case !sameUser(p.command.User, by.User) || p.command.RoomID != by.RoomID:
+		return chat.Command{}, &chat.Message{Text: "Only " + p.command.User.Name + " can confirm " + token + ", in the room it was asked in", Color: chat.ColorRed}
+	}
+	delete(cs.pending, token)
+	return p.command, nil
+}
+
+// sameUser reports whether a and b are the same chat user
+func sameUser(a chat.User, b chat.User) bool {
+	if a.ID != "" || b.ID != "" {
+		return a.ID == b.ID
+	}
+	return a.MentionName == b.MentionName
+}
+
+// confirmed reports whether the kick being run has been confirmed
+func confirmed(ctx context.Context) bool {
+	ok, _ := ctx.Value(confirmedKey{}).(bool)
+	return ok
+}
+
+// oneShot reports whether the command is run by exec, where a kick can not
+// be confirmed
+func oneShot(ctx context.Context) bool {
+	ok, _ := ctx.Value(oneShotKey{}).(bool)
+	return ok
+}
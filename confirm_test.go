@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/mkobaly/hipchatBot/chat"
+)
+
+var tokenPattern = regexp.MustCompile(`confirm ([0-9a-f]{6}) `)
+
+func TestConfirm(t *testing.T) {
+	c, p := settingsContext(t)
+	c.cfg.Protected = []string{"*_RC"}
+	ctx := context.Background()
+	ada := chat.User{ID: "2", Name: "Ada Lovelace", MentionName: "Ada"}
+	grace := chat.User{ID: "1", Name: "Grace", MentionName: "grace"}
+	run := func(text string, user chat.User, roomID string) replies {
+		var r replies
+		c.dispatch(ctx, chat.Command{Text: text, User: user, RoomID: roomID, Source: "test"}, &r)
+		return r
+	}
+
+	run("/build kick Web_CI main", ada, "1")
+	if len(p.kicked) != 1 {
+		t.Fatalf("unprotected build not kicked off: %+v", p.kicked)
+	}
+	r := run("/build kick Web_RC main", ada, "1")
+	if len(p.kicked) != 1 {
+		t.Fatalf("protected build kicked off without confirmation")
+	}
+	if len(r) != 1 || r[0].Color != chat.ColorYellow || !tokenPattern.MatchString(r[0].Text) {
+		t.Fatalf("no confirmation token: %+v", r)
+	}
+	token := tokenPattern.FindStringSubmatch(r[0].Text)[1]
+
+	if r := run("/build confirm "+token, grace, "1"); len(r) != 1 || r[0].Color != chat.ColorRed {
+		t.Errorf("another user confirmed: %+v", r)
+	}
+	if r := run("/build confirm "+token, ada, "2"); len(r) != 1 || r[0].Color != chat.ColorRed {
+		t.Errorf("confirmed in another room: %+v", r)
+	}
+	if len(p.kicked) != 1 {
+		t.Fatalf("kicked off without the right confirmation: %+v", p.kicked)
+	}
+	if r := run("/build confirm "+token, ada, "1"); len(r) != 1 || r[0].Template != "kick" {
+		t.Errorf("confirmation not kicked off: %+v", r)
+	}
+	if len(p.kicked) != 2 || p.kicked[1].ConfigID != "Web_RC" || p.kicked[1].Branch != "main" {
+		t.Errorf("unexpected builds %+v", p.kicked)
+	}
+	if r := run("/build confirm "+token, ada, "1"); len(r) != 1 || r[0].Color != chat.ColorYellow || len(p.kicked) != 2 {
+		t.Errorf("confirmed twice: %+v", r)
+	}
+}
+
+func TestConfirmExpired(t *testing.T) {
+	cs := newConfirmations()
+	kick := chat.Command{Text: "/build kick Web_RC main", User: chat.User{ID: "2"}, RoomID: "1"}
+	now := time.Now()
+	token, err := cs.add(kick, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, refusal := cs.confirm(token, kick, now.Add(confirmTimeout+time.Second)); refusal == nil {
+		t.Errorf("expired confirmation accepted")
+	}
+	token, _ = cs.add(kick, now)
+	if got, refusal := cs.confirm(token, kick, now.Add(confirmTimeout)); refusal != nil || got.Text != kick.Text {
+		t.Errorf("confirmation refused: %+v", refusal)
+	}
+}
@@ -0,0 +1,172 @@
+// Package cron parses the five field cron expressions builds are scheduled
+// with: minute, hour, day of month, month and day of week
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// field is the range of values of a cron field and the names they can be
+// given by
+type field struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var fields = []field{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: []string{"", "jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	{name: "day of week", min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+// macros are the shorthands for common schedules
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@nightly":  "0 2 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Schedule is a parsed cron expression
+type Schedule struct {
+	// sets are the allowed values of each field, by value
+	sets [5][]bool
+	// anyDOM and anyDOW record whether the day of month and day of week
+	// fields are "*". When both are restricted a day matching either runs.
+	anyDOM, anyDOW bool
+}
+
+// Parse reads a cron expression like "0 2 * * 1-5" or a macro like "@daily"
+func Parse(expr string) (*Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if m, ok := macros[strings.ToLower(expr)]; ok {
+		expr = m
+	}
+	parts := strings.Fields(expr)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("cron: %q has %d fields, want 5", expr, len(parts))
+	}
+	s := &Schedule{anyDOM: parts[2] == "*", anyDOW: parts[4] == "*"}
+	for i, f := range fields {
+		set, err := f.parse(strings.ToLower(parts[i]))
+		if err != nil {
+			return nil, err
+		}
+		s.sets[i] = set
+	}
+	// Sunday is both 0 and 7
+	if s.sets[4][7] {
+		s.sets[4][0] = true
+	}
+	return s, nil
+}
+
+// parse reads a field's comma separated list of values, ranges and steps
+func (f field) parse(s string) ([]bool, error) {
+	set := make([]bool, f.max+1)
+	for _, item := range strings.Split(s, ",") {
+		step := 1
+		if i := strings.Index(item, "/"); i >= 0 {
+			n, err := strconv.Atoi(item[i+1:])
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("cron: bad step %q in %v", item[i+1:], f.name)
+			}
+			step = n
+			item = item[:i]
+		}
+		lo, hi := f.min, f.max
+		switch {
+		case item == "*":
+		case strings.Contains(item, "-"):
+			bounds := strings.SplitN(item, "-", 2)
+			var err error
+			if lo, err = f.value(bounds[0]); err != nil {
+				return nil, err
+			}
+			if hi, err = f.value(bounds[1]); err != nil {
+				return nil, err
+			}
+			if lo > hi {
+				return nil, fmt.Errorf("cron: bad range %q in %v", item, f.name)
+			}
+		default:
+			v, err := f.value(item)
+			if err != nil {
+				return nil, err
+			}
+			lo = v
+			if step == 1 {
+				hi = v
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			set[v] = true
+		}
+	}
+	return set, nil
+}
+
+// value reads a number or name of the field
+func (f field) value(s string) (int, error) {
+	for i, name := range f.names {
+		if name != "" && s == name {
+			return i, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("cron: bad %v %q", f.name, s)
+	}
+	return v, nil
+}
+
+// Matches reports whether the schedule runs in the minute of t
+func (s *Schedule) Matches(t time.Time) bool {
+	return s.sets[0][t.Minute()] && s.sets[1][t.Hour()] && s.sets[3][int(t.Month())] && s.dayMatches(t)
+}
+
+// Next returns the first minute after t the schedule runs in, or the zero
+// time when it does not run in the next five years
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	end := t.AddDate(5, 0, 0)
+	for t.Before(end) {
+		switch {
+		case !s.sets[3][int(t.Month())]:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case !s.sets[1][t.Hour()]:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case !s.sets[0][t.Minute()]:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches reports whether the schedule runs on the day of t
+func (s *Schedule) dayMatches(t time.Time) bool {
+	dom, dow := s.sets[2][t.Day()], s.sets[4][int(t.Weekday())]
+	switch {
+	case s.anyDOM && s.anyDOW:
+		return true
+	case s.anyDOM:
+		return dow
+	case s.anyDOW:
+		return dom
+	}
+	return dom || dow
+}
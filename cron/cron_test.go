@@ -0,0 +1,59 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	// a Wednesday
+	from := time.Date(2026, time.October, 14, 10, 30, 0, 0, time.UTC)
+	for expr, want := range map[string]string{
+		"* * * * *":           "2026-10-14 10:31",
+		"0 2 * * *":           "2026-10-15 02:00",
+		"@nightly":            "2026-10-15 02:00",
+		"*/15 * * * *":        "2026-10-14 10:45",
+		"0 9-17/4 * * *":      "2026-10-14 13:00",
+		"30 10 * * mon-fri":   "2026-10-15 10:30",
+		"0 0 * * sun":         "2026-10-18 00:00",
+		"0 0 * * 7":           "2026-10-18 00:00",
+		"0 0 1 jan *":         "2027-01-01 00:00",
+		"0 12 13 * fri":       "2026-10-16 12:00",
+		"0 0 31 2 *":          "",
+		"5,35 10,22 14 oct *": "2026-10-14 10:35",
+	} {
+		s, err := Parse(expr)
+		if err != nil {
+			t.Errorf("%q: %v", expr, err)
+			continue
+		}
+		next := s.Next(from)
+		got := ""
+		if !next.IsZero() {
+			got = next.Format("2006-01-02 15:04")
+		}
+		if got != want {
+			t.Errorf("%q: next run %q, want %q", expr, got, want)
+		}
+		if !next.IsZero() && !s.Matches(next) {
+			t.Errorf("%q: does not match its next run %v", expr, next)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "* * * foo *", "@sometimes"} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("%q parsed", expr)
+		}
+	}
+}
+
+func TestNextInHalfHourZone(t *testing.T) {
+	india := time.FixedZone("IST", 5*60*60+30*60)
+	s, _ := Parse("0 * * * *")
+	from := time.Date(2026, time.October, 14, 10, 10, 0, 0, india)
+	if got := s.Next(from); got.Hour() != 11 || got.Minute() != 0 {
+		t.Errorf("next run %v", got)
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/mkobaly/hipchatBot/ci"
+)
+
+// dashboardBuild is a build as the sidebar dashboard shows it
+type dashboardBuild struct {
+	ID       string `json:"id"`
+	Config   string `json:"config"`
+	Branch   string `json:"branch"`
+	Number   string `json:"number"`
+	State    string `json:"state"`
+	Status   string `json:"status"`
+	URL      string `json:"url,omitempty"`
+	Duration string `json:"duration,omitempty"`
+}
+
+// dashboard is the data behind the room's sidebar
+type dashboard struct {
+	Recent     []dashboardBuild `json:"recent"`
+	Watched    []dashboardBuild `json:"watched"`
+	Queue      []dashboardBuild `json:"queue"`
+	QueueError string           `json:"queueError,omitempty"`
+}
+
+func dashboardBuilds(builds []*ci.Build) []dashboardBuild {
+	res := make([]dashboardBuild, 0, len(builds))
+	for _, b := range builds {
+		db := dashboardBuild{
+			ID:     b.ID,
+			Config: b.ConfigID,
+			Branch: b.Branch,
+			Number: b.Number,
+			State:  b.State,
+			Status: b.Status,
+			URL:    b.WebURL,
+		}
+		if d := b.Duration(); d > 0 {
+			db.Duration = d.Round(time.Second).String()
+		}
+		res = append(res, db)
+	}
+	return res
+}
+
+// sidebar serves the room's build dashboard web panel
+func (c *Context) sidebar(w http.ResponseWriter, r *http.Request) {
+	if _, err := c.signedRoom(r); err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	c.page(w, r, "sidebar.hbs", nil)
+}
+
+// sidebarBuilds is the JSON API the dashboard polls: the room's recent
+// builds, its watched configurations and the queue of the room's server
+func (c *Context) sidebarBuilds(w http.ResponseWriter, r *http.Request) {
+	roomID, err := c.signedRoom(r)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	d := dashboard{
+		Recent:  dashboardBuilds(c.health.latest(roomID)),
+		Watched: dashboardBuilds(c.health.builds(roomID)),
+		Queue:   []dashboardBuild{},
+	}
+	if _, p, err := c.providerFor(roomID, nil, ""); err != nil {
+		d.QueueError = err.Error()
+	} else if q, ok := p.(ci.Queue); !ok {
+		d.QueueError = "The server can not list its queue"
+	} else if queued, err := q.Queued(r.Context()); err != nil {
+		log.Printf("Error getting build queue: %v", err)
+		d.QueueError = "Error getting build queue"
+	} else {
+		d.Queue = dashboardBuilds(queued)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(d)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mkobaly/hipchatBot/ci"
+	"github.com/mkobaly/hipchatBot/config"
+)
+
+// queueProvider is a provider that only knows its build queue
+type queueProvider struct {
+	ci.Provider
+	queue []*ci.Build
+}
+
+func (p queueProvider) Queued(ctx context.Context) ([]*ci.Build, error) {
+	return p.queue, nil
+}
+
+func TestSidebarBuilds(t *testing.T) {
+	c := &Context{
+		rooms:     map[string]*RoomConfig{"4008322": {oauthID: "client-1", oauthSecret: "s3cret"}},
+		providers: map[string]ci.Provider{"tc": queueProvider{queue: []*ci.Build{{ID: "9", ConfigID: "Web_CI", State: "queued"}}}},
+		cfg:       &config.Config{DefaultServer: "tc"},
+		health:    newRoomHealth(),
+	}
+	started := time.Now().Add(-time.Minute)
+	c.health.update("4008322", "1", &ci.Build{ID: "1", ConfigID: "Web_CI", State: ci.StateRunning, Started: started}, true)
+	c.health.update("4008322", "2", &ci.Build{ID: "2", ConfigID: "Api_CI", State: ci.StateRunning}, false)
+	c.health.update("4008322", "1", &ci.Build{ID: "1", ConfigID: "Web_CI", State: ci.StateFinished, Status: ci.StatusSuccess,
+		Started: started, Ended: started.Add(90 * time.Second)}, true)
+	// kicked off from the room, which watches nothing
+	c.buildChanged("4008322", "3", &ci.Build{ID: "3", ConfigID: "Web_RC", State: ci.StateQueued})
+
+	claims := map[string]interface{}{
+		"iss":     "client-1",
+		"exp":     time.Now().Add(time.Minute).Unix(),
+		"context": map[string]interface{}{"room_id": 4008322},
+	}
+	req := httptest.NewRequest("GET", "/sidebar/builds", nil)
+	req.Header.Set("Authorization", "JWT "+signJWT(claims, "s3cret"))
+	w := httptest.NewRecorder()
+	c.sidebarBuilds(w, req)
+
+	var d dashboard
+	json.NewDecoder(w.Body).Decode(&d)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d", w.Code)
+	}
+	if len(d.Recent) != 3 || d.Recent[0].ID != "3" || d.Recent[2].Status != ci.StatusSuccess || d.Recent[2].Duration != "1m30s" {
+		t.Errorf("unexpected recent builds %+v", d.Recent)
+	}
+	if len(d.Watched) != 1 || d.Watched[0].ID != "1" || len(d.Queue) != 1 || d.Queue[0].ID != "9" || d.QueueError != "" {
+		t.Errorf("unexpected dashboard %+v", d)
+	}
+
+	req = httptest.NewRequest("GET", "/sidebar/builds", nil)
+	req.Header.Set("Authorization", "JWT "+signJWT(claims, "wrong"))
+	w = httptest.NewRecorder()
+	if c.sidebarBuilds(w, req); w.Code != http.StatusUnauthorized {
+		t.Errorf("bad signature got status %d", w.Code)
+	}
+}
+
+func TestSidebar(t *testing.T) {
+	c := &Context{
+		rooms:   map[string]*RoomConfig{"4008322": {oauthID: "client-1", oauthSecret: "s3cret"}},
+		baseURL: "https://bot.example.com",
+	}
+	claims := map[string]interface{}{
+		"iss":     "client-1",
+		"exp":     time.Now().Add(time.Minute).Unix(),
+		"context": map[string]interface{}{"room_id": 4008322},
+	}
+	w := httptest.NewRecorder()
+	c.sidebar(w, httptest.NewRequest("GET", "/sidebar?signed_request="+signJWT(claims, "s3cret"), nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `<ul id="queue"`) {
+		t.Errorf("unexpected sidebar %d %q", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	if c.sidebar(w, httptest.NewRequest("GET", "/sidebar", nil)); w.Code != http.StatusUnauthorized {
+		t.Errorf("unsigned request got status %d", w.Code)
+	}
+}
@@ -0,0 +1,105 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/mkobaly/hipchatBot/ci"
+	"github.com/mkobaly/hipchatBot/config"
+)
+
+const (
+	defaultURL = "https://api.github.com"
+	apiVersion = "2022-11-28"
+)
+
+// Error is returned for every non 2xx response from GitHub
+type Error = ci.Error
+
+// client talks JSON to the GitHub REST API with a personal access token.
+// URL is only needed for GitHub Enterprise, as https://host/api/v3.
+type client struct {
+	*ci.Client
+	baseURL string
+	token   string
+}
+
+func newClient(creds config.UserCredential) *client {
+	base := creds.URL
+	if base == "" {
+		base = defaultURL
+	}
+	c := &client{
+		Client:  ci.NewClient("github"),
+		baseURL: strings.TrimRight(base, "/"),
+		token:   creds.Token,
+	}
+	c.HTTP.CheckRedirect = dropAuth
+	c.Prepare = c.prepare
+	c.ErrorMessage = errorMessage
+	return c
+}
+
+func (c *client) get(ctx context.Context, path string, out interface{}) error {
+	return c.JSON(ctx, "GET", c.baseURL+path, nil, out)
+}
+
+func (c *client) post(ctx context.Context, path string, in, out interface{}) error {
+	return c.JSON(ctx, "POST", c.baseURL+path, in, out)
+}
+
+// prepare asks for the API version the provider is written against and adds
+// the token
+func (c *client) prepare(req *http.Request) {
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", apiVersion)
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+}
+
+// dropAuth keeps the token from being sent on to the storage URLs GitHub
+// redirects downloads to
+func dropAuth(req *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+	req.Header.Del("Authorization")
+	return nil
+}
+
+// getText follows GitHub's redirect to the log download
+func (c *client) getText(ctx context.Context, path string) (string, error) {
+	var buf bytes.Buffer
+	err := c.get(ctx, path, &buf)
+	return buf.String(), err
+}
+
+// errorMessage reads GitHub's {"message": ..., "errors": [...]} bodies
+func errorMessage(resp *http.Response, body []byte) string {
+	var j struct {
+		Message string `json:"message"`
+		Errors  []struct {
+			Message string `json:"message"`
+			Code    string `json:"code"`
+			Field   string `json:"field"`
+		} `json:"errors"`
+	}
+	if json.Unmarshal(body, &j) != nil || j.Message == "" {
+		return ""
+	}
+	msg := j.Message
+	for _, e := range j.Errors {
+		switch {
+		case e.Message != "":
+			msg += "; " + e.Message
+		case e.Field != "":
+			msg += "; " + e.Field + " " + e.Code
+		}
+	}
+	return msg
+}
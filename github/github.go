@@ -0,0 +1,411 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mkobaly/hipchatBot/ci"
+	"github.com/mkobaly/hipchatBot/config"
+)
+
+const (
+	defaultFindWait     = 10 * time.Second
+	defaultFindInterval = time.Second
+	// clockSkew allows for GitHub's clock being behind ours when looking
+	// for the run a dispatch created
+	clockSkew = 5 * time.Second
+)
+
+// GitHub runs GitHub Actions workflows through workflow_dispatch. Build
+// configuration IDs are "owner/repo/workflow" where workflow is the workflow
+// file name. Build IDs are "owner/repo#run" for a run and "owner/repo#run/job"
+// for one job in it. A dispatch whose run GitHub has not created yet is
+// "owner/repo/workflow@ref" until it shows up.
+type GitHub struct {
+	client *client
+	repos  []string
+
+	findWait     time.Duration
+	findInterval time.Duration
+}
+
+var _ ci.Provider = (*GitHub)(nil)
+
+// New will create a new GitHub Actions provider. creds.Repos lists the
+// "owner/repo" repositories whose workflows list shows.
+func New(creds config.UserCredential) *GitHub {
+	return &GitHub{
+		client:       newClient(creds),
+		repos:        creds.Repos,
+		findWait:     defaultFindWait,
+		findInterval: defaultFindInterval,
+	}
+}
+
+type workflow struct {
+	ID    int64  `json:"id"`
+	Name  string `json:"name"`
+	Path  string `json:"path"`
+	State string `json:"state"`
+}
+
+type dispatchRequest struct {
+	Ref    string            `json:"ref"`
+	Inputs map[string]string `json:"inputs,omitempty"`
+}
+
+type run struct {
+	ID         int64     `json:"id"`
+	RunNumber  int64     `json:"run_number"`
+	HeadBranch string    `json:"head_branch"`
+	Path       string    `json:"path"`
+	Status     string    `json:"status"`
+	Conclusion string    `json:"conclusion"`
+	HTMLURL    string    `json:"html_url"`
+	CreatedAt  time.Time `json:"created_at"`
+	StartedAt  time.Time `json:"run_started_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type job struct {
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Conclusion string `json:"conclusion"`
+	HTMLURL    string `json:"html_url"`
+}
+
+type artifact struct {
+	Name        string `json:"name"`
+	SizeInBytes int64  `json:"size_in_bytes"`
+	Expired     bool   `json:"expired"`
+}
+
+// workflowRef is a parsed build configuration ID
+type workflowRef struct {
+	repo     string
+	workflow string
+}
+
+func parseConfigID(id string) (workflowRef, error) {
+	parts := strings.Split(id, "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return workflowRef{}, fmt.Errorf("github: %q is not owner/repo/workflow", id)
+	}
+	return workflowRef{repo: parts[0] + "/" + parts[1], workflow: parts[2]}, nil
+}
+
+func (w workflowRef) String() string {
+	return w.repo + "/" + w.workflow
+}
+
+// runRef is a parsed build ID. Pending dispatches have a workflow and ref
+// instead of a run.
+type runRef struct {
+	repo    string
+	run     int64
+	job     string
+	pending workflowRef
+	ref     string
+}
+
+func parseID(id string) (runRef, error) {
+	if i := strings.LastIndex(id, "@"); i > 0 && !strings.Contains(id, "#") {
+		w, err := parseConfigID(id[:i])
+		return runRef{repo: w.repo, pending: w, ref: id[i+1:]}, err
+	}
+	i := strings.LastIndex(id, "#")
+	if i <= 0 {
+		return runRef{}, fmt.Errorf("github: build ID %q is not owner/repo#run", id)
+	}
+	r := runRef{repo: id[:i]}
+	rest := id[i+1:]
+	if j := strings.Index(rest, "/"); j >= 0 {
+		r.job = rest[j+1:]
+		rest = rest[:j]
+	}
+	var err error
+	r.run, err = strconv.ParseInt(rest, 10, 64)
+	return r, err
+}
+
+func runID(repo string, id int64) string {
+	return repo + "#" + strconv.FormatInt(id, 10)
+}
+
+func repoPath(repo string) string {
+	return "/repos/" + repo
+}
+
+func (r runRef) runPath() string {
+	return repoPath(r.repo) + "/actions/runs/" + strconv.FormatInt(r.run, 10)
+}
+
+// state maps a run or job status and conclusion onto the ci states
+func state(status, conclusion string) (string, string) {
+	switch status {
+	case "completed":
+	case "in_progress":
+		return ci.StateRunning, ci.StatusUnknown
+	default:
+		// queued, requested, waiting, pending
+		return ci.StateQueued, ci.StatusUnknown
+	}
+	switch conclusion {
+	case "success":
+		return ci.StateFinished, ci.StatusSuccess
+	case "failure", "timed_out", "startup_failure":
+		return ci.StateFinished, ci.StatusFailure
+	}
+	return ci.StateFinished, ci.StatusUnknown
+}
+
+func toBuild(repo string, r *run) *ci.Build {
+	b := &ci.Build{
+		ID:         runID(repo, r.ID),
+		ConfigID:   repo + "/" + path.Base(r.Path),
+		Branch:     r.HeadBranch,
+		Number:     strconv.FormatInt(r.RunNumber, 10),
+		StatusText: r.Status,
+		WebURL:     r.HTMLURL,
+		Started:    r.StartedAt,
+	}
+	if r.Status == "completed" {
+		b.Ended = r.UpdatedAt
+	}
+	if r.Conclusion != "" {
+		b.StatusText = r.Conclusion
+	}
+	b.State, b.Status = state(r.Status, r.Conclusion)
+	return b
+}
+
+// List returns the active workflows of the configured repositories
+func (g *GitHub) List(ctx context.Context) ([]string, error) {
+	var ids []string
+	for _, repo := range g.repos {
+		var list struct {
+			Workflows []workflow `json:"workflows"`
+		}
+		if err := g.client.get(ctx, repoPath(repo)+"/actions/workflows?per_page=100", &list); err != nil {
+			return nil, err
+		}
+		for _, w := range list.Workflows {
+			if w.State == "active" {
+				ids = append(ids, repo+"/"+path.Base(w.Path))
+			}
+		}
+	}
+	return ids, nil
+}
+
+// Trigger dispatches the workflow on the request's branch with Params as
+// the workflow inputs. GitHub does not answer with the run it creates so the
+// newest workflow_dispatch run on the branch is looked for for a little while.
+func (g *GitHub) Trigger(ctx context.Context, req ci.BuildRequest) (*ci.Build, error) {
+	w, err := parseConfigID(req.ConfigID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Revision != "" {
+		return nil, fmt.Errorf("github: workflow_dispatch can only run on a branch or tag")
+	}
+	dispatched := time.Now()
+	dr := dispatchRequest{Ref: req.Branch, Inputs: req.Params}
+	if len(dr.Inputs) == 0 {
+		dr.Inputs = nil
+	}
+	wfPath := repoPath(w.repo) + "/actions/workflows/" + url.PathEscape(w.workflow)
+	if err := g.client.post(ctx, wfPath+"/dispatches", dr, nil); err != nil {
+		return nil, err
+	}
+
+	pending := &ci.Build{
+		ID:       w.String() + "@" + req.Branch,
+		ConfigID: w.String(),
+		Branch:   req.Branch,
+		State:    ci.StateQueued,
+		Status:   ci.StatusUnknown,
+	}
+	deadline := time.Now().Add(g.findWait)
+	for {
+		r, err := g.latestDispatch(ctx, w, req.Branch, dispatched.Add(-clockSkew))
+		if err != nil {
+			return nil, err
+		}
+		if r != nil {
+			return toBuild(w.repo, r), nil
+		}
+		if time.Now().After(deadline) {
+			return pending, nil
+		}
+		select {
+		case <-ctx.Done():
+			return pending, nil
+		case <-time.After(g.findInterval):
+		}
+	}
+}
+
+// latestDispatch returns the newest workflow_dispatch run of the workflow on
+// ref created after since, or nil when there is none yet
+func (g *GitHub) latestDispatch(ctx context.Context, w workflowRef, ref string, since time.Time) (*run, error) {
+	q := url.Values{"event": {"workflow_dispatch"}, "branch": {ref}, "per_page": {"10"}}
+	var list struct {
+		WorkflowRuns []*run `json:"workflow_runs"`
+	}
+	p := repoPath(w.repo) + "/actions/workflows/" + url.PathEscape(w.workflow) + "/runs?" + q.Encode()
+	if err := g.client.get(ctx, p, &list); err != nil {
+		return nil, err
+	}
+	var latest *run
+	for _, r := range list.WorkflowRuns {
+		if r.CreatedAt.Before(since) {
+			continue
+		}
+		if latest == nil || r.CreatedAt.After(latest.CreatedAt) {
+			latest = r
+		}
+	}
+	return latest, nil
+}
+
+// resolve turns a pending dispatch ID into its run
+func (g *GitHub) resolve(ctx context.Context, id string) (runRef, error) {
+	r, err := parseID(id)
+	if err != nil || r.pending.workflow == "" {
+		return r, err
+	}
+	found, err := g.latestDispatch(ctx, r.pending, r.ref, time.Time{})
+	if err != nil {
+		return r, err
+	}
+	if found == nil {
+		return r, fmt.Errorf("github: no run found yet for %s", id)
+	}
+	r.run = found.ID
+	return r, nil
+}
+
+// Status returns the state of the run, or of a single job in it
+func (g *GitHub) Status(ctx context.Context, id string) (*ci.Build, error) {
+	pr, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+	r, err := g.resolve(ctx, id)
+	if err != nil {
+		if pr.pending.workflow != "" {
+			if _, ok := err.(*Error); !ok {
+				// dispatched but GitHub has not created the run yet
+				return &ci.Build{ID: id, ConfigID: pr.pending.String(), Branch: pr.ref, State: ci.StateQueued, Status: ci.StatusUnknown}, nil
+			}
+		}
+		return nil, err
+	}
+	var wr run
+	if err := g.client.get(ctx, r.runPath(), &wr); err != nil {
+		return nil, err
+	}
+	b := toBuild(r.repo, &wr)
+	if r.job == "" {
+		return b, nil
+	}
+	j, err := g.job(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	b.ID += "/" + r.job
+	b.WebURL = j.HTMLURL
+	b.StatusText = j.Status
+	if j.Conclusion != "" {
+		b.StatusText = j.Conclusion
+	}
+	b.State, b.Status = state(j.Status, j.Conclusion)
+	return b, nil
+}
+
+// job finds the job a build ID points at. For a whole run that is the first
+// failed job, or else the latest job that has started.
+func (g *GitHub) job(ctx context.Context, r runRef) (*job, error) {
+	var list struct {
+		Jobs []*job `json:"jobs"`
+	}
+	if err := g.client.get(ctx, r.runPath()+"/jobs?per_page=100", &list); err != nil {
+		return nil, err
+	}
+	var latest *job
+	for _, j := range list.Jobs {
+		if r.job != "" {
+			if j.Name == r.job {
+				return j, nil
+			}
+			continue
+		}
+		if j.Conclusion == "failure" {
+			return j, nil
+		}
+		if j.Status != "queued" {
+			latest = j
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("github: no job found for %s", runID(r.repo, r.run))
+	}
+	return latest, nil
+}
+
+// Cancel cancels the whole run. GitHub can not cancel single jobs and has
+// nowhere to keep the comment.
+func (g *GitHub) Cancel(ctx context.Context, id string, comment string) error {
+	r, err := g.resolve(ctx, id)
+	if err != nil {
+		return err
+	}
+	return g.client.post(ctx, r.runPath()+"/cancel", nil, nil)
+}
+
+// Log downloads the log of the job the build ID points at
+func (g *GitHub) Log(ctx context.Context, id string) (string, error) {
+	r, err := g.resolve(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	j, err := g.job(ctx, r)
+	if err != nil {
+		return "", err
+	}
+	return g.client.getText(ctx, fmt.Sprintf("%s/actions/jobs/%d/logs", repoPath(r.repo), j.ID))
+}
+
+// Artifacts lists the artifacts uploaded by the run. They link to the run
+// page since the download URLs need an API token.
+func (g *GitHub) Artifacts(ctx context.Context, id string) ([]ci.Artifact, error) {
+	r, err := g.resolve(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	var list struct {
+		Artifacts []artifact `json:"artifacts"`
+	}
+	if err := g.client.get(ctx, r.runPath()+"/artifacts?per_page=100", &list); err != nil {
+		return nil, err
+	}
+	var wr run
+	if err := g.client.get(ctx, r.runPath(), &wr); err != nil {
+		return nil, err
+	}
+	var artifacts []ci.Artifact
+	for _, a := range list.Artifacts {
+		if a.Expired {
+			continue
+		}
+		artifacts = append(artifacts, ci.Artifact{Name: a.Name, Size: a.SizeInBytes, URL: wr.HTMLURL + "#artifacts"})
+	}
+	return artifacts, nil
+}
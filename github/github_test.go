@@ -0,0 +1,168 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mkobaly/hipchatBot/ci"
+	"github.com/mkobaly/hipchatBot/ci/citest"
+	"github.com/mkobaly/hipchatBot/config"
+)
+
+// standIn is a minimal GitHub API for acme/web whose deploy.yml dispatches
+// become run 555
+func standIn(t *testing.T) (*GitHub, *citest.StandIn) {
+	s := citest.NewStandIn(t)
+	dispatched := false
+	s.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" && !strings.HasPrefix(r.URL.Path, "/download/") {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"message":"Bad credentials"}`))
+			return
+		}
+		s.Record(r.Method + " " + r.URL.Path)
+		created := time.Now().UTC().Format(time.RFC3339)
+		runJSON := `{"id":555,"run_number":12,"head_branch":"main","path":".github/workflows/deploy.yml",
+			"status":"completed","conclusion":"failure","html_url":"https://github.com/acme/web/actions/runs/555","created_at":"` + created + `"}`
+		switch r.URL.Path {
+		case "/repos/acme/web/actions/workflows":
+			w.Write([]byte(`{"workflows":[{"id":1,"path":".github/workflows/deploy.yml","state":"active"},
+				{"id":2,"path":".github/workflows/old.yml","state":"disabled_manually"}]}`))
+		case "/repos/acme/web/actions/workflows/deploy.yml/dispatches":
+			var req dispatchRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			if req.Ref != "main" || req.Inputs["env"] != "staging" {
+				t.Errorf("unexpected dispatch %+v", req)
+			}
+			dispatched = true
+			w.WriteHeader(http.StatusNoContent)
+		case "/repos/acme/web/actions/workflows/deploy.yml/runs":
+			if r.URL.Query().Get("event") != "workflow_dispatch" || r.URL.Query().Get("branch") != "main" {
+				t.Errorf("unexpected run query %v", r.URL.RawQuery)
+			}
+			if !dispatched {
+				w.Write([]byte(`{"workflow_runs":[]}`))
+				return
+			}
+			w.Write([]byte(`{"workflow_runs":[` + runJSON + `]}`))
+		case "/repos/acme/web/actions/runs/555":
+			w.Write([]byte(runJSON))
+		case "/repos/acme/web/actions/runs/555/jobs":
+			w.Write([]byte(`{"jobs":[{"id":71,"name":"build","status":"completed","conclusion":"success"},
+				{"id":72,"name":"deploy","status":"completed","conclusion":"failure","html_url":"https://github.com/acme/web/runs/72"}]}`))
+		case "/repos/acme/web/actions/jobs/72/logs":
+			http.Redirect(w, r, s.URL+"/download/72.txt", http.StatusFound)
+		case "/download/72.txt":
+			if r.Header.Get("Authorization") != "" {
+				t.Errorf("token leaked to log download")
+			}
+			w.Write([]byte("deploying\nerror: no credentials\n"))
+		case "/repos/acme/web/actions/runs/555/artifacts":
+			w.Write([]byte(`{"artifacts":[{"name":"site","size_in_bytes":10},{"name":"old","expired":true}]}`))
+		case "/repos/acme/web/actions/runs/555/cancel":
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"Not Found"}`))
+		}
+	})
+
+	g := New(config.UserCredential{Type: config.TypeGithub, URL: s.URL, Token: "secret", Repos: []string{"acme/web"}})
+	g.client.Backoff = time.Millisecond
+	g.findWait = 50 * time.Millisecond
+	g.findInterval = time.Millisecond
+	return g, s
+}
+
+func TestList(t *testing.T) {
+	g, _ := standIn(t)
+	ids, err := g.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(ids, ",") != "acme/web/deploy.yml" {
+		t.Errorf("unexpected workflows %v", ids)
+	}
+}
+
+func TestTriggerFindsRun(t *testing.T) {
+	g, _ := standIn(t)
+	b, err := g.Trigger(context.Background(), ci.BuildRequest{
+		ConfigID: "acme/web/deploy.yml",
+		Branch:   "main",
+		Params:   map[string]string{"env": "staging"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.ID != "acme/web#555" || b.ConfigID != "acme/web/deploy.yml" {
+		t.Errorf("unexpected run %+v", b)
+	}
+	if !b.Finished() || b.Status != ci.StatusFailure {
+		t.Errorf("expected failed run got %v %v", b.State, b.Status)
+	}
+}
+
+func TestStatusOfJob(t *testing.T) {
+	g, _ := standIn(t)
+	b, err := g.Status(context.Background(), "acme/web#555/build")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.ID != "acme/web#555/build" || b.Status != ci.StatusSuccess {
+		t.Errorf("unexpected job %+v", b)
+	}
+}
+
+func TestPendingDispatch(t *testing.T) {
+	g, _ := standIn(t)
+	b, err := g.Status(context.Background(), "acme/web/deploy.yml@main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.State != ci.StateQueued {
+		t.Errorf("expected queued got %+v", b)
+	}
+}
+
+func TestLogFollowsRedirect(t *testing.T) {
+	g, _ := standIn(t)
+	text, err := g.Log(context.Background(), "acme/web#555")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text, "error: no credentials") {
+		t.Errorf("unexpected log %q", text)
+	}
+}
+
+func TestArtifactsAndCancel(t *testing.T) {
+	g, s := standIn(t)
+	ctx := context.Background()
+	artifacts, err := g.Artifacts(ctx, "acme/web#555")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(artifacts) != 1 || artifacts[0].Name != "site" || artifacts[0].URL != "https://github.com/acme/web/actions/runs/555#artifacts" {
+		t.Errorf("unexpected artifacts %+v", artifacts)
+	}
+	if err := g.Cancel(ctx, "acme/web#555", ""); err != nil {
+		t.Fatal(err)
+	}
+	if last := s.Calls()[len(s.Calls())-1]; last != "POST /repos/acme/web/actions/runs/555/cancel" {
+		t.Errorf("unexpected last call %v", last)
+	}
+}
+
+func TestErrorMessage(t *testing.T) {
+	g, _ := standIn(t)
+	g.client.token = "wrong"
+	_, err := g.List(context.Background())
+	if e, ok := err.(*Error); !ok || e.StatusCode != http.StatusUnauthorized || e.Message != "Bad credentials" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
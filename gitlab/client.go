@@ -0,0 +1,75 @@
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/mkobaly/hipchatBot/ci"
+	"github.com/mkobaly/hipchatBot/config"
+)
+
+// Error is returned for every non 2xx response from GitLab
+type Error = ci.Error
+
+// client talks JSON to the GitLab v4 API using a personal or project
+// access token
+type client struct {
+	*ci.Client
+	baseURL string
+	token   string
+}
+
+func newClient(creds config.UserCredential) *client {
+	token := creds.Token
+	if token == "" {
+		token = creds.Password
+	}
+	c := &client{
+		Client:  ci.NewClient("gitlab"),
+		baseURL: strings.TrimRight(creds.URL, "/") + "/api/v4",
+		token:   token,
+	}
+	c.Prepare = func(req *http.Request) { req.Header.Set("PRIVATE-TOKEN", c.token) }
+	c.ErrorMessage = errorMessage
+	return c
+}
+
+func (c *client) get(ctx context.Context, path string, out interface{}) error {
+	return c.JSON(ctx, "GET", c.baseURL+path, nil, out)
+}
+
+func (c *client) post(ctx context.Context, path string, in, out interface{}) error {
+	return c.JSON(ctx, "POST", c.baseURL+path, in, out)
+}
+
+func (c *client) getText(ctx context.Context, path string) (string, error) {
+	var buf bytes.Buffer
+	err := c.get(ctx, path, &buf)
+	return buf.String(), err
+}
+
+// errorMessage reads GitLab's {"message": ...} or {"error": ...} bodies.
+// Validation errors come back with message as an object of field errors.
+func errorMessage(resp *http.Response, body []byte) string {
+	var j struct {
+		Message interface{} `json:"message"`
+		Error   string      `json:"error"`
+	}
+	if json.Unmarshal(body, &j) == nil {
+		switch m := j.Message.(type) {
+		case string:
+			return m
+		case nil:
+		default:
+			b, _ := json.Marshal(m)
+			return string(b)
+		}
+		if j.Error != "" {
+			return j.Error
+		}
+	}
+	return ""
+}
@@ -0,0 +1,325 @@
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mkobaly/hipchatBot/ci"
+	"github.com/mkobaly/hipchatBot/config"
+)
+
+// ChatUserVariable is the pipeline variable holding the chat user that asked
+// for the pipeline. GitLab variable keys can not contain ci.ChatUserParam's dot.
+const ChatUserVariable = "TRIGGERED_BY_CHAT_USER"
+
+// ErrRevision is returned when a pipeline is asked for at a commit, which
+// the pipeline API does not support
+var ErrRevision = errors.New("gitlab: pipelines can only be run on a branch or tag")
+
+var variableKeyRE = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// GitLab runs pipelines on a GitLab server. Build configuration IDs are
+// project paths such as "group/project". Build IDs are "project#pipeline"
+// for a whole pipeline and "project#pipeline/job" for one job in it.
+type GitLab struct {
+	client *client
+}
+
+var _ ci.Provider = (*GitLab)(nil)
+
+// New will create a new GitLab provider
+func New(creds config.UserCredential) *GitLab {
+	return &GitLab{client: newClient(creds)}
+}
+
+type project struct {
+	PathWithNamespace string `json:"path_with_namespace"`
+}
+
+type variable struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type pipelineRequest struct {
+	Ref       string     `json:"ref"`
+	Variables []variable `json:"variables,omitempty"`
+}
+
+type pipeline struct {
+	ID         int64      `json:"id"`
+	Ref        string     `json:"ref"`
+	Status     string     `json:"status"`
+	WebURL     string     `json:"web_url"`
+	StartedAt  *time.Time `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+}
+
+type job struct {
+	ID            int64      `json:"id"`
+	Name          string     `json:"name"`
+	Stage         string     `json:"stage"`
+	Status        string     `json:"status"`
+	Ref           string     `json:"ref"`
+	WebURL        string     `json:"web_url"`
+	StartedAt     *time.Time `json:"started_at"`
+	FinishedAt    *time.Time `json:"finished_at"`
+	ArtifactsFile *struct {
+		Filename string `json:"filename"`
+		Size     int64  `json:"size"`
+	} `json:"artifacts_file"`
+	Pipeline struct {
+		ID int64 `json:"id"`
+	} `json:"pipeline"`
+}
+
+// buildRef is a parsed build ID
+type buildRef struct {
+	project  string
+	pipeline int64
+	job      string
+}
+
+func parseID(id string) (buildRef, error) {
+	i := strings.LastIndex(id, "#")
+	if i <= 0 {
+		return buildRef{}, fmt.Errorf("gitlab: build ID %q is not project#pipeline", id)
+	}
+	ref := buildRef{project: id[:i]}
+	rest := id[i+1:]
+	if j := strings.Index(rest, "/"); j >= 0 {
+		ref.job = rest[j+1:]
+		rest = rest[:j]
+	}
+	var err error
+	ref.pipeline, err = strconv.ParseInt(rest, 10, 64)
+	return ref, err
+}
+
+func (r buildRef) String() string {
+	id := r.project + "#" + strconv.FormatInt(r.pipeline, 10)
+	if r.job != "" {
+		id += "/" + r.job
+	}
+	return id
+}
+
+func projectPath(p string) string {
+	return "/projects/" + url.PathEscape(p)
+}
+
+func pipelinePath(r buildRef) string {
+	return projectPath(r.project) + "/pipelines/" + strconv.FormatInt(r.pipeline, 10)
+}
+
+// state maps a GitLab pipeline or job status onto the ci states
+func state(status string) (string, string) {
+	switch status {
+	case "running":
+		return ci.StateRunning, ci.StatusUnknown
+	case "success":
+		return ci.StateFinished, ci.StatusSuccess
+	case "failed":
+		return ci.StateFinished, ci.StatusFailure
+	case "canceled", "skipped":
+		return ci.StateFinished, ci.StatusUnknown
+	}
+	// created, waiting_for_resource, preparing, pending, scheduled, manual
+	return ci.StateQueued, ci.StatusUnknown
+}
+
+// List returns the projects the token is a member of
+func (g *GitLab) List(ctx context.Context) ([]string, error) {
+	var projects []project
+	if err := g.client.get(ctx, "/projects?membership=true&simple=true&per_page=100&order_by=path&sort=asc", &projects); err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, p := range projects {
+		ids = append(ids, p.PathWithNamespace)
+	}
+	return ids, nil
+}
+
+// Trigger runs a pipeline for the project on the request's branch. Params
+// become pipeline variables and the chat user is passed as ChatUserVariable.
+func (g *GitLab) Trigger(ctx context.Context, req ci.BuildRequest) (*ci.Build, error) {
+	if req.Revision != "" {
+		return nil, ErrRevision
+	}
+	pr := pipelineRequest{Ref: req.Branch}
+	var keys []string
+	for k := range req.Params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if !variableKeyRE.MatchString(k) {
+			return nil, fmt.Errorf("gitlab: %q is not a valid variable name", k)
+		}
+		pr.Variables = append(pr.Variables, variable{Key: k, Value: req.Params[k]})
+	}
+	if req.User != "" {
+		pr.Variables = append(pr.Variables, variable{Key: ChatUserVariable, Value: req.User})
+	}
+
+	var p pipeline
+	if err := g.client.post(ctx, projectPath(req.ConfigID)+"/pipeline", pr, &p); err != nil {
+		return nil, err
+	}
+	return g.toBuild(buildRef{project: req.ConfigID, pipeline: p.ID}, &p), nil
+}
+
+func (g *GitLab) toBuild(r buildRef, p *pipeline) *ci.Build {
+	b := &ci.Build{
+		ID:         r.String(),
+		ConfigID:   r.project,
+		Branch:     p.Ref,
+		Number:     strconv.FormatInt(p.ID, 10),
+		StatusText: p.Status,
+		WebURL:     p.WebURL,
+	}
+	if p.StartedAt != nil {
+		b.Started = *p.StartedAt
+	}
+	if p.FinishedAt != nil {
+		b.Ended = *p.FinishedAt
+	}
+	b.State, b.Status = state(p.Status)
+	return b
+}
+
+// jobs returns the jobs of the pipeline, oldest first
+func (g *GitLab) jobs(ctx context.Context, r buildRef) ([]*job, error) {
+	var jobs []*job
+	if err := g.client.get(ctx, pipelinePath(r)+"/jobs?per_page=100", &jobs); err != nil {
+		return nil, err
+	}
+	sort.Slice(jobs, func(i, k int) bool { return jobs[i].ID < jobs[k].ID })
+	return jobs, nil
+}
+
+// job finds the job a build ID points at. For a whole pipeline that is the
+// first failed job, or else the latest job that has started.
+func (g *GitLab) job(ctx context.Context, r buildRef) (*job, error) {
+	jobs, err := g.jobs(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	var latest *job
+	for _, j := range jobs {
+		if r.job != "" {
+			if j.Name == r.job {
+				latest = j
+			}
+			continue
+		}
+		if j.Status == "failed" {
+			return j, nil
+		}
+		if s, _ := state(j.Status); s != ci.StateQueued {
+			latest = j
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("gitlab: no job found for %s", r)
+	}
+	return latest, nil
+}
+
+// Status returns the state of the pipeline, or of a single job in it. The
+// status text of a pipeline lists the status of each of its jobs.
+func (g *GitLab) Status(ctx context.Context, id string) (*ci.Build, error) {
+	r, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+	if r.job != "" {
+		j, err := g.job(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		b := g.toBuild(r, &pipeline{ID: r.pipeline, Ref: j.Ref, Status: j.Status, WebURL: j.WebURL, StartedAt: j.StartedAt, FinishedAt: j.FinishedAt})
+		return b, nil
+	}
+
+	var p pipeline
+	if err := g.client.get(ctx, pipelinePath(r), &p); err != nil {
+		return nil, err
+	}
+	b := g.toBuild(r, &p)
+	jobs, err := g.jobs(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	var parts []string
+	for _, j := range jobs {
+		parts = append(parts, j.Name+": "+j.Status)
+	}
+	if len(parts) > 0 {
+		b.StatusText = strings.Join(parts, ", ")
+	}
+	return b, nil
+}
+
+// Cancel cancels the pipeline or the single job. GitLab has nowhere to keep
+// the comment.
+func (g *GitLab) Cancel(ctx context.Context, id string, comment string) error {
+	r, err := parseID(id)
+	if err != nil {
+		return err
+	}
+	if r.job == "" {
+		return g.client.post(ctx, pipelinePath(r)+"/cancel", nil, nil)
+	}
+	j, err := g.job(ctx, r)
+	if err != nil {
+		return err
+	}
+	return g.client.post(ctx, fmt.Sprintf("%s/jobs/%d/cancel", projectPath(r.project), j.ID), nil, nil)
+}
+
+// Log returns the trace of the job the build ID points at
+func (g *GitLab) Log(ctx context.Context, id string) (string, error) {
+	r, err := parseID(id)
+	if err != nil {
+		return "", err
+	}
+	j, err := g.job(ctx, r)
+	if err != nil {
+		return "", err
+	}
+	return g.client.getText(ctx, fmt.Sprintf("%s/jobs/%d/trace", projectPath(r.project), j.ID))
+}
+
+// Artifacts lists the artifact archive of every job in the pipeline, or of
+// the single job
+func (g *GitLab) Artifacts(ctx context.Context, id string) ([]ci.Artifact, error) {
+	r, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+	jobs, err := g.jobs(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	var artifacts []ci.Artifact
+	for _, j := range jobs {
+		if j.ArtifactsFile == nil || (r.job != "" && j.Name != r.job) {
+			continue
+		}
+		artifacts = append(artifacts, ci.Artifact{
+			Name: j.Name + "/" + j.ArtifactsFile.Filename,
+			Size: j.ArtifactsFile.Size,
+			URL:  j.WebURL + "/artifacts/download",
+		})
+	}
+	return artifacts, nil
+}
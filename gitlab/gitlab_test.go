@@ -0,0 +1,164 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mkobaly/hipchatBot/ci"
+	"github.com/mkobaly/hipchatBot/ci/citest"
+	"github.com/mkobaly/hipchatBot/config"
+)
+
+const jobsJSON = `[
+	{"id":902,"name":"test","stage":"test","status":"failed","ref":"master","web_url":"http://gitlab/web/site/-/jobs/902"},
+	{"id":901,"name":"build","stage":"build","status":"success","ref":"master","web_url":"http://gitlab/web/site/-/jobs/901",
+	 "artifacts_file":{"filename":"artifacts.zip","size":2048}}
+]`
+
+// standIn is a minimal GitLab API for project web/site with pipeline 77
+func standIn(t *testing.T) (*GitLab, *citest.StandIn) {
+	s := citest.NewStandIn(t)
+	s.HandleFunc("/api/v4/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"message":"401 Unauthorized"}`))
+			return
+		}
+		path := strings.TrimPrefix(r.URL.EscapedPath(), "/api/v4")
+		s.Record(r.Method + " " + path)
+		switch path {
+		case "/projects":
+			w.Write([]byte(`[{"path_with_namespace":"web/site"},{"path_with_namespace":"tools/bot"}]`))
+		case "/projects/web%2Fsite/pipeline":
+			var req pipelineRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			if req.Ref != "master" || len(req.Variables) != 2 || req.Variables[1].Key != ChatUserVariable {
+				t.Errorf("unexpected pipeline request %+v", req)
+			}
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":77,"ref":"master","status":"created","web_url":"http://gitlab/web/site/-/pipelines/77"}`))
+		case "/projects/web%2Fsite/pipelines/77":
+			w.Write([]byte(`{"id":77,"ref":"master","status":"failed","web_url":"http://gitlab/web/site/-/pipelines/77"}`))
+		case "/projects/web%2Fsite/pipelines/77/jobs":
+			w.Write([]byte(jobsJSON))
+		case "/projects/web%2Fsite/jobs/902/trace":
+			w.Write([]byte("$ make test\nFAIL\n"))
+		case "/projects/web%2Fsite/pipelines/77/cancel", "/projects/web%2Fsite/jobs/901/cancel":
+			w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"404 Project Not Found"}`))
+		}
+	})
+
+	g := New(config.UserCredential{Type: config.TypeGitlab, URL: s.URL, Token: "secret"})
+	g.client.Backoff = time.Millisecond
+	return g, s
+}
+
+func TestList(t *testing.T) {
+	g, _ := standIn(t)
+	ids, err := g.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(ids, ",") != "web/site,tools/bot" {
+		t.Errorf("unexpected projects %v", ids)
+	}
+}
+
+func TestTriggerAndStatus(t *testing.T) {
+	g, _ := standIn(t)
+	ctx := context.Background()
+
+	b, err := g.Trigger(ctx, ci.BuildRequest{
+		ConfigID: "web/site",
+		Branch:   "master",
+		User:     "Jo (@jo)",
+		Params:   map[string]string{"Branch": "master"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.ID != "web/site#77" || b.State != ci.StateQueued {
+		t.Errorf("unexpected pipeline %+v", b)
+	}
+
+	b, err = g.Status(ctx, b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !b.Finished() || b.Status != ci.StatusFailure {
+		t.Errorf("expected failed pipeline got %+v", b)
+	}
+	if b.StatusText != "build: success, test: failed" {
+		t.Errorf("unexpected status text %q", b.StatusText)
+	}
+
+	b, err = g.Status(ctx, "web/site#77/build")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Status != ci.StatusSuccess || b.WebURL != "http://gitlab/web/site/-/jobs/901" {
+		t.Errorf("unexpected job %+v", b)
+	}
+}
+
+func TestTriggerAtRevision(t *testing.T) {
+	g, _ := standIn(t)
+	_, err := g.Trigger(context.Background(), ci.BuildRequest{ConfigID: "web/site", Branch: "master", Revision: "abc123"})
+	if err != ErrRevision {
+		t.Errorf("expected ErrRevision got %v", err)
+	}
+}
+
+func TestLogPicksFailedJob(t *testing.T) {
+	g, _ := standIn(t)
+	text, err := g.Log(context.Background(), "web/site#77")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "$ make test\nFAIL\n" {
+		t.Errorf("unexpected trace %q", text)
+	}
+}
+
+func TestArtifacts(t *testing.T) {
+	g, _ := standIn(t)
+	artifacts, err := g.Artifacts(context.Background(), "web/site#77")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(artifacts) != 1 || artifacts[0].Name != "build/artifacts.zip" || artifacts[0].Size != 2048 {
+		t.Errorf("unexpected artifacts %+v", artifacts)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	g, s := standIn(t)
+	ctx := context.Background()
+	if err := g.Cancel(ctx, "web/site#77", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Cancel(ctx, "web/site#77/build", ""); err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Join(s.Calls(), ",")
+	want := "POST /projects/web%2Fsite/pipelines/77/cancel,GET /projects/web%2Fsite/pipelines/77/jobs,POST /projects/web%2Fsite/jobs/901/cancel"
+	if got != want {
+		t.Errorf("unexpected calls %v", got)
+	}
+}
+
+func TestErrorMessage(t *testing.T) {
+	g, _ := standIn(t)
+	_, err := g.Status(context.Background(), "web/nope#1")
+	e, ok := err.(*Error)
+	if !ok || e.StatusCode != http.StatusNotFound || e.Message != "404 Project Not Found" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mkobaly/hipchatBot/ci"
+	"github.com/mkobaly/hipchatBot/util"
+	"github.com/tbruyelle/hipchat-go/hipchat"
+)
+
+// glanceKey is the key of the room glance in atlassian-connect.json
+const glanceKey = "build-health"
+
+// maxRecent is how many of a room's latest builds are kept for its dashboard
+const maxRecent = 20
+
+// roomHealth keeps the latest build of each configuration watched in a room
+// and the room's most recent builds, watched or kicked off from the room
+type roomHealth struct {
+	mu     sync.Mutex
+	rooms  map[string]map[string]*ci.Build
+	recent map[string][]*ci.Build
+}
+
+func newRoomHealth() *roomHealth {
+	return &roomHealth{
+		rooms:  make(map[string]map[string]*ci.Build),
+		recent: make(map[string][]*ci.Build),
+	}
+}
+
+// update records b among the room's recent builds and, when the room watches
+// its configuration, as the configuration's latest build. id is the ID the
+// build was first recorded under, which differs from b.ID once a queued
+// Jenkins or GitHub build has started. It reports whether the watched
+// configuration's state or status changed.
+func (h *roomHealth) update(roomID string, id string, b *ci.Build, watched bool) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	recent := h.recent[roomID][:0]
+	found := false
+	for _, r := range h.recent[roomID] {
+		if r.ID != id && r.ID != b.ID {
+			recent = append(recent, r)
+		} else if !found {
+			recent, found = append(recent, b), true
+		}
+	}
+	h.recent[roomID] = recent
+	if !found {
+		recent = append([]*ci.Build{b}, recent...)
+		if len(recent) > maxRecent {
+			recent = recent[:maxRecent]
+		}
+		h.recent[roomID] = recent
+	}
+
+	if !watched {
+		return false
+	}
+	builds, ok := h.rooms[roomID]
+	if !ok {
+		builds = make(map[string]*ci.Build)
+		h.rooms[roomID] = builds
+	}
+	old := builds[b.ConfigID]
+	builds[b.ConfigID] = b
+	return old == nil || old.State != b.State || old.Status != b.Status
+}
+
+// forget drops the configuration the room no longer watches
+func (h *roomHealth) forget(roomID string, configID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.rooms[roomID], configID)
+}
+
+// latest returns the room's most recent builds, newest first
+func (h *roomHealth) latest(roomID string) []*ci.Build {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]*ci.Build(nil), h.recent[roomID]...)
+}
+
+// builds returns the latest builds watched in the room by configuration
+func (h *roomHealth) builds(roomID string) []*ci.Build {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var builds []*ci.Build
+	for _, b := range h.rooms[roomID] {
+		builds = append(builds, b)
+	}
+	sort.Slice(builds, func(i, j int) bool { return builds[i].ConfigID < builds[j].ConfigID })
+	return builds
+}
+
+// glance sums up the room's builds for its glance: how many pass, fail and
+// are running, with a lozenge for the worst of them
+func (h *roomHealth) glance(roomID string) hipchat.GlanceContent {
+	var passing, failing, running int
+	for _, b := range h.builds(roomID) {
+		switch {
+		case !b.Finished():
+			running++
+		case b.Status == ci.StatusSuccess:
+			passing++
+		default:
+			failing++
+		}
+	}
+	content := hipchat.GlanceContent{
+		Label:    hipchat.AttributeValue{Type: "html", Value: "No builds watched"},
+		Metadata: map[string]int{"passing": passing, "failing": failing, "running": running},
+	}
+	if passing+failing+running == 0 {
+		return content
+	}
+	content.Label.Value = fmt.Sprintf("<b>%d</b> passing, <b>%d</b> failing", passing, failing)
+	lozenge := hipchat.AttributeValue{Label: "PASSING", Type: "success"}
+	switch {
+	case failing > 0:
+		lozenge = hipchat.AttributeValue{Label: "FAILING", Type: "error"}
+	case running > 0:
+		lozenge = hipchat.AttributeValue{Label: "RUNNING", Type: "current"}
+	}
+	content.Status = &hipchat.GlanceStatus{Type: "lozenge", Value: lozenge}
+	return content
+}
+
+// buildChanged records a build's new state in the room and pushes the room's
+// glance to HipChat when it changed. Only the configurations the room watches
+// count towards the glance, builds kicked off from the room are just recent.
+// id is the ID the build was kicked off as, see roomHealth.update.
+func (c *Context) buildChanged(roomID string, id string, b *ci.Build) {
+	if c.health == nil || !c.health.update(roomID, id, b, c.watching(roomID, b.ConfigID, b.Branch)) {
+		return
+	}
+	rc := c.room(roomID)
+	if rc == nil || rc.hc == nil {
+		return
+	}
+	update := &hipchat.GlanceUpdateRequest{
+		Glance: []*hipchat.GlanceUpdate{{Key: glanceKey, Content: c.health.glance(roomID)}},
+	}
+	if _, err := rc.hc.Room.UpdateGlance(roomID, update); err != nil {
+		log.Printf("Error updating glance of room %v: %v", roomID, err)
+	}
+}
+
+// watching reports whether the room watches the branch of the configuration
+func (c *Context) watching(roomID string, configID string, branch string) bool {
+	if c.store == nil {
+		return false
+	}
+	for _, w := range c.store.RoomWatches(roomID) {
+		if w.ConfigID == configID && (w.Branch == "" || w.Branch == branch) {
+			return true
+		}
+	}
+	return false
+}
+
+// room returns the installation of the add-on in the room, nil when the
+// room has none
+func (c *Context) room(roomID string) *RoomConfig {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rooms[roomID]
+}
+
+// oauthSecret returns the shared secret of the installation with the OAuth
+// client id, which HipChat puts in the iss claim of a signed_request
+func (c *Context) oauthSecret(clientID string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, rc := range c.rooms {
+		if rc.oauthID == clientID {
+			return rc.oauthSecret, true
+		}
+	}
+	return "", false
+}
+
+// signedRoom verifies the request's signed_request, returning the room it
+// was made from. Scripts in add-on pages pass the token in an
+// "Authorization: JWT" header instead.
+func (c *Context) signedRoom(r *http.Request) (string, error) {
+	token := r.URL.Query().Get("signed_request")
+	if token == "" {
+		token = strings.TrimPrefix(r.Header.Get("Authorization"), "JWT ")
+	}
+	claims, err := util.ParseJWT(token, c.oauthSecret)
+	if err != nil {
+		return "", err
+	}
+	ctx, _ := claims["context"].(map[string]interface{})
+	switch id := ctx["room_id"].(type) {
+	case float64:
+		return fmt.Sprint(int64(id)), nil
+	case string:
+		return id, nil
+	}
+	return "", util.ErrInvalidJWT
+}
+
+// glance serves the glance data HipChat asks for when the room is opened
+func (c *Context) glance(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	roomID, err := c.signedRoom(r)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c.health.glance(roomID))
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/mkobaly/hipchatBot/ci"
+	"github.com/mkobaly/hipchatBot/store"
+	"github.com/tbruyelle/hipchat-go/hipchat"
+)
+
+// signJWT creates a signed_request the way HipChat does
+func signJWT(claims map[string]interface{}, secret string) string {
+	header, _ := json.Marshal(map[string]string{"alg": "HS256", "typ": "JWT"})
+	body, _ := json.Marshal(claims)
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(body)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestGlance(t *testing.T) {
+	var updates []hipchat.GlanceUpdateRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/addon/ui/room/4008322" {
+			t.Errorf("unexpected call %v %v", r.Method, r.URL.Path)
+		}
+		var u hipchat.GlanceUpdateRequest
+		json.NewDecoder(r.Body).Decode(&u)
+		updates = append(updates, u)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	hc := hipchat.NewClient("token")
+	hc.BaseURL, _ = url.Parse(ts.URL + "/v2/")
+	st, err := store.Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.AddWatch(store.Watch{RoomID: "4008322", ConfigID: "Web_CI", Only: store.OnlyAll})
+	st.AddWatch(store.Watch{RoomID: "4008322", ConfigID: "Api_CI", Branch: "main", Only: store.OnlyAll})
+	c := &Context{
+		rooms:  map[string]*RoomConfig{"4008322": {hc: hc, oauthID: "client-1", oauthSecret: "s3cret"}},
+		health: newRoomHealth(),
+		store:  st,
+	}
+
+	c.buildChanged("4008322", "", &ci.Build{ConfigID: "Web_CI", State: ci.StateRunning})
+	c.buildChanged("4008322", "", &ci.Build{ConfigID: "Web_CI", State: ci.StateRunning})
+	// builds kicked off from the room without a watch leave the glance alone
+	c.buildChanged("4008322", "", &ci.Build{ConfigID: "Web_RC", State: ci.StateFinished, Status: ci.StatusFailure})
+	c.buildChanged("4008322", "", &ci.Build{ConfigID: "Api_CI", Branch: "feature/x", State: ci.StateFinished, Status: ci.StatusFailure})
+	c.buildChanged("4008322", "", &ci.Build{ConfigID: "Api_CI", Branch: "main", State: ci.StateFinished, Status: ci.StatusFailure})
+	if len(updates) != 2 {
+		t.Fatalf("got %d glance updates, want 2", len(updates))
+	}
+	if g := updates[1].Glance[0]; g.Key != glanceKey || g.Content.Label.Value != "<b>0</b> passing, <b>1</b> failing" {
+		t.Errorf("unexpected glance update %+v", g)
+	}
+
+	get := func(token string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c.glance(w, httptest.NewRequest("GET", "/glance?signed_request="+token, nil))
+		return w
+	}
+	claims := map[string]interface{}{
+		"iss":     "client-1",
+		"exp":     time.Now().Add(time.Minute).Unix(),
+		"context": map[string]interface{}{"room_id": 4008322},
+	}
+	w := get(signJWT(claims, "s3cret"))
+	var content struct {
+		Label  hipchat.AttributeValue
+		Status struct {
+			Type  string
+			Value hipchat.AttributeValue
+		}
+	}
+	json.NewDecoder(w.Body).Decode(&content)
+	if w.Code != http.StatusOK || content.Status.Value.Label != "FAILING" || content.Status.Value.Type != "error" {
+		t.Errorf("unexpected glance %d %+v", w.Code, content)
+	}
+
+	if w := get(signJWT(claims, "wrong")); w.Code != http.StatusUnauthorized {
+		t.Errorf("bad signature got status %d", w.Code)
+	}
+	claims["exp"] = time.Now().Add(-time.Minute).Unix()
+	if w := get(signJWT(claims, "s3cret")); w.Code != http.StatusUnauthorized {
+		t.Errorf("expired token got status %d", w.Code)
+	}
+}
+
+// startingProvider is a provider whose queued builds, like Jenkins', get a
+// new ID once they start
+type startingProvider struct {
+	ci.Provider
+}
+
+func (startingProvider) Status(ctx context.Context, id string) (*ci.Build, error) {
+	return &ci.Build{ID: "web#45", ConfigID: "web", State: ci.StateFinished, Status: ci.StatusSuccess}, nil
+}
+
+func TestRecentBuildStarted(t *testing.T) {
+	defer func(d time.Duration) { watchInterval = d }(watchInterval)
+	watchInterval = time.Millisecond
+	c := &Context{health: newRoomHealth()}
+	b := &ci.Build{ID: "queue#17", ConfigID: "web", State: ci.StateQueued}
+	c.buildChanged("1", b.ID, b)
+	c.followBuild("1", startingProvider{}, ci.BuildRequest{ConfigID: "web"}, b, nil)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		recent := c.health.latest("1")
+		if len(recent) == 1 && recent[0].ID == "web#45" && recent[0].Finished() {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("queued build not replaced by the build it became: %+v", recent)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
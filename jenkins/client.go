@@ -0,0 +1,100 @@
+package jenkins
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/mkobaly/hipchatBot/ci"
+	"github.com/mkobaly/hipchatBot/config"
+)
+
+// Error is returned for every non 2xx response from Jenkins
+type Error = ci.Error
+
+// client talks to the Jenkins remote access API. Requests authenticate with
+// the user's API token which Jenkins exempts from CSRF crumbs.
+type client struct {
+	*ci.Client
+	baseURL string
+	creds   config.UserCredential
+}
+
+func newClient(creds config.UserCredential) *client {
+	c := &client{
+		Client:  ci.NewClient("jenkins"),
+		baseURL: strings.TrimRight(creds.URL, "/"),
+		creds:   creds,
+	}
+	c.Prepare = c.authorize
+	c.ErrorMessage = errorMessage
+	return c
+}
+
+// getJSON decodes the api/json page below path into out
+func (c *client) getJSON(ctx context.Context, path string, query url.Values, out interface{}) error {
+	resp, err := c.get(ctx, path+"/api/json", query)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (c *client) getText(ctx context.Context, path string) (string, error) {
+	resp, err := c.get(ctx, path, nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	return string(b), err
+}
+
+func (c *client) get(ctx context.Context, path string, query url.Values) (*http.Response, error) {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return c.Do(ctx, "GET", u, nil, nil)
+}
+
+// post sends form, handing back the response for its headers. ci.Client only
+// retries it when Jenkins can not have handled it, so a job is not started
+// twice.
+func (c *client) post(ctx context.Context, path string, form url.Values) (*http.Response, error) {
+	var body []byte
+	header := make(http.Header)
+	if len(form) > 0 {
+		body = []byte(form.Encode())
+		header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	resp, err := c.Do(ctx, "POST", c.baseURL+path, body, header)
+	if err != nil {
+		return nil, err
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+	return resp, nil
+}
+
+// authorize logs in with the API token, or the password when there is none
+func (c *client) authorize(req *http.Request) {
+	if c.creds.Username != "" {
+		secret := c.creds.Token
+		if secret == "" {
+			secret = c.creds.Password
+		}
+		req.SetBasicAuth(c.creds.Username, secret)
+	}
+}
+
+// errorMessage reads the reason of a failed response. Jenkins answers with
+// an HTML page, the short reason is in the X-Error header when there is one.
+func errorMessage(resp *http.Response, body []byte) string {
+	return resp.Header.Get("X-Error")
+}
@@ -0,0 +1,392 @@
+package jenkins
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mkobaly/hipchatBot/ci"
+	"github.com/mkobaly/hipchatBot/config"
+)
+
+// queuePrefix marks build IDs that still point at a Jenkins queue item
+const queuePrefix = "queue#"
+
+var queueItemRE = regexp.MustCompile(`/queue/item/(\d+)/?$`)
+
+// ErrNotStarted is returned for logs and artifacts of builds still queued
+var ErrNotStarted = errors.New("jenkins: build has not started yet")
+
+// Jenkins drives jobs on a Jenkins server. Build configuration IDs are job
+// names, with "/" between folders. Build IDs are "job#number" once a build
+// runs and "queue#id" while it waits in the queue.
+type Jenkins struct {
+	client *client
+}
+
+var _ ci.Provider = (*Jenkins)(nil)
+
+// New will create a new Jenkins provider
+func New(creds config.UserCredential) *Jenkins {
+	return &Jenkins{client: newClient(creds)}
+}
+
+type jobList struct {
+	Jobs []*job `json:"jobs"`
+}
+
+type job struct {
+	Name string `json:"name"`
+	Jobs []*job `json:"jobs"`
+}
+
+// jobParams lists the parameters a job declares and, for jobs checking out
+// from Git, the branches they build
+type jobParams struct {
+	Property []struct {
+		ParameterDefinitions []struct {
+			Name string `json:"name"`
+		} `json:"parameterDefinitions"`
+	} `json:"property"`
+	SCM struct {
+		Branches []struct {
+			Name string `json:"name"`
+		} `json:"branches"`
+	} `json:"scm"`
+}
+
+// declares reports whether the job has the parameter
+func (p *jobParams) declares(name string) bool {
+	for _, prop := range p.Property {
+		for _, d := range prop.ParameterDefinitions {
+			if d.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// builds reports whether the job builds the branch and whether the branches
+// it builds are known at all. Branch specifiers like "*/main" or
+// "refs/heads/main" name the branch without its remote.
+func (p *jobParams) builds(branch string) (ok bool, known bool) {
+	for _, b := range p.SCM.Branches {
+		spec := strings.TrimPrefix(b.Name, "refs/heads/")
+		spec = strings.TrimPrefix(strings.TrimPrefix(spec, "*/"), "origin/")
+		if spec == "**" || spec == branch {
+			return true, true
+		}
+		if m, _ := path.Match(spec, branch); m {
+			return true, true
+		}
+	}
+	return false, len(p.SCM.Branches) > 0
+}
+
+type queueItem struct {
+	ID        int64  `json:"id"`
+	Cancelled bool   `json:"cancelled"`
+	Why       string `json:"why"`
+	Task      struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"task"`
+	Executable *struct {
+		Number int64 `json:"number"`
+	} `json:"executable"`
+}
+
+type build struct {
+	Number      int64  `json:"number"`
+	Building    bool   `json:"building"`
+	Result      string `json:"result"`
+	URL         string `json:"url"`
+	DisplayName string `json:"displayName"`
+	// Timestamp is when the build started and Duration how long it took,
+	// both in milliseconds
+	Timestamp int64 `json:"timestamp"`
+	Duration  int64 `json:"duration"`
+	Actions   []struct {
+		Parameters []struct {
+			Name  string      `json:"name"`
+			Value interface{} `json:"value"`
+		} `json:"parameters"`
+	} `json:"actions"`
+	Artifacts []struct {
+		FileName     string `json:"fileName"`
+		RelativePath string `json:"relativePath"`
+	} `json:"artifacts"`
+}
+
+// jobPath turns "folder/name" into the "/job/folder/job/name" URL path
+func jobPath(name string) string {
+	var p string
+	for _, part := range strings.Split(strings.Trim(name, "/"), "/") {
+		p += "/job/" + url.PathEscape(part)
+	}
+	return p
+}
+
+// jobFromURL is the inverse of jobPath for the job URLs Jenkins hands back
+func jobFromURL(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return ""
+	}
+	path := parsed.Path
+	i := strings.Index(path, "/job/")
+	if i < 0 {
+		return ""
+	}
+	var parts []string
+	for _, seg := range strings.Split(strings.Trim(path[i:], "/"), "/") {
+		if seg != "job" {
+			parts = append(parts, seg)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// parseID splits a build ID into either a queue item or a job and number
+func parseID(id string) (queue int64, job string, number int64, err error) {
+	if strings.HasPrefix(id, queuePrefix) {
+		queue, err = strconv.ParseInt(strings.TrimPrefix(id, queuePrefix), 10, 64)
+		return queue, "", 0, err
+	}
+	i := strings.LastIndex(id, "#")
+	if i <= 0 {
+		return 0, "", 0, fmt.Errorf("jenkins: build ID %q is not job#number", id)
+	}
+	number, err = strconv.ParseInt(id[i+1:], 10, 64)
+	return 0, id[:i], number, err
+}
+
+func buildID(job string, number int64) string {
+	return job + "#" + strconv.FormatInt(number, 10)
+}
+
+func (j *Jenkins) buildPath(job string, number int64) string {
+	return jobPath(job) + "/" + strconv.FormatInt(number, 10)
+}
+
+// List returns every job on the server, including the ones inside folders
+func (j *Jenkins) List(ctx context.Context) ([]string, error) {
+	var list jobList
+	q := url.Values{"tree": {"jobs[name,jobs[name,jobs[name]]]"}}
+	if err := j.client.getJSON(ctx, "", q, &list); err != nil {
+		return nil, err
+	}
+	var ids []string
+	var walk func(prefix string, jobs []*job)
+	walk = func(prefix string, jobs []*job) {
+		for _, jb := range jobs {
+			if jb.Jobs != nil {
+				walk(prefix+jb.Name+"/", jb.Jobs)
+				continue
+			}
+			ids = append(ids, prefix+jb.Name)
+		}
+	}
+	walk("", list.Jobs)
+	return ids, nil
+}
+
+// Trigger starts the job with the request's parameters. The branch, revision
+// and chat user are passed as the Branch, Revision and ci.ChatUserParam
+// parameters when the job declares them. Jobs left without parameters are
+// started through /build, which is the only endpoint they accept.
+func (j *Jenkins) Trigger(ctx context.Context, req ci.BuildRequest) (*ci.Build, error) {
+	form := url.Values{}
+	for k, v := range req.Params {
+		form.Set(k, v)
+	}
+	job, err := j.params(ctx, req.ConfigID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Revision != "" && !job.declares("Revision") {
+		return nil, fmt.Errorf("jenkins: %s has no Revision parameter, it can only build the head of a branch", req.ConfigID)
+	}
+	branch := req.Branch
+	if branch != "" && !job.declares("Branch") {
+		// the job builds the branches of its own configuration
+		ok, known := job.builds(branch)
+		if known && !ok {
+			return nil, fmt.Errorf("jenkins: %s has no Branch parameter and does not build %s", req.ConfigID, branch)
+		}
+		if !known {
+			branch = ""
+		}
+	}
+	for name, value := range map[string]string{"Branch": req.Branch, "Revision": req.Revision, ci.ChatUserParam: req.User} {
+		if value != "" && job.declares(name) {
+			form.Set(name, value)
+		}
+	}
+
+	endpoint := "/build"
+	if len(form) > 0 {
+		endpoint = "/buildWithParameters"
+	}
+	resp, err := j.client.post(ctx, jobPath(req.ConfigID)+endpoint, form)
+	if err != nil {
+		return nil, err
+	}
+	m := queueItemRE.FindStringSubmatch(resp.Header.Get("Location"))
+	if m == nil {
+		return nil, fmt.Errorf("jenkins: no queue item returned for %s", req.ConfigID)
+	}
+	return &ci.Build{
+		ID:       queuePrefix + m[1],
+		ConfigID: req.ConfigID,
+		Branch:   branch,
+		State:    ci.StateQueued,
+		Status:   ci.StatusUnknown,
+	}, nil
+}
+
+// params returns the parameters the job declares and the branches it builds
+func (j *Jenkins) params(ctx context.Context, job string) (*jobParams, error) {
+	var p jobParams
+	q := url.Values{"tree": {"property[parameterDefinitions[name]],scm[branches[name]]"}}
+	if err := j.client.getJSON(ctx, jobPath(job), q, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Status returns the build. A queue item that has started is resolved to
+// the build it became.
+func (j *Jenkins) Status(ctx context.Context, id string) (*ci.Build, error) {
+	queue, jobName, number, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+	if queue != 0 {
+		var item queueItem
+		if err := j.client.getJSON(ctx, "/queue/item/"+strconv.FormatInt(queue, 10), nil, &item); err != nil {
+			return nil, err
+		}
+		jobName = jobFromURL(item.Task.URL)
+		if item.Executable == nil {
+			b := &ci.Build{ID: id, ConfigID: jobName, State: ci.StateQueued, Status: ci.StatusUnknown, StatusText: item.Why}
+			if item.Cancelled {
+				b.State = ci.StateFinished
+				b.StatusText = "Cancelled"
+			}
+			return b, nil
+		}
+		number = item.Executable.Number
+	}
+
+	var b build
+	if err := j.client.getJSON(ctx, j.buildPath(jobName, number), nil, &b); err != nil {
+		return nil, err
+	}
+	return toBuild(jobName, &b), nil
+}
+
+func toBuild(jobName string, b *build) *ci.Build {
+	res := &ci.Build{
+		ID:         buildID(jobName, b.Number),
+		ConfigID:   jobName,
+		Number:     strconv.FormatInt(b.Number, 10),
+		State:      ci.StateFinished,
+		StatusText: b.Result,
+		WebURL:     b.URL,
+	}
+	if b.Timestamp > 0 {
+		res.Started = time.Unix(0, b.Timestamp*int64(time.Millisecond))
+		if !b.Building && b.Duration > 0 {
+			res.Ended = res.Started.Add(time.Duration(b.Duration) * time.Millisecond)
+		}
+	}
+	for _, a := range b.Actions {
+		for _, p := range a.Parameters {
+			if p.Name == "Branch" {
+				res.Branch = fmt.Sprint(p.Value)
+			}
+		}
+	}
+	switch {
+	case b.Building:
+		res.State = ci.StateRunning
+		res.Status = ci.StatusUnknown
+	case b.Result == "SUCCESS":
+		res.Status = ci.StatusSuccess
+	case b.Result == "FAILURE", b.Result == "UNSTABLE":
+		res.Status = ci.StatusFailure
+	default:
+		res.Status = ci.StatusUnknown
+	}
+	return res
+}
+
+// Cancel removes a queued build from the queue or aborts a running one.
+// Jenkins has nowhere to keep the comment.
+func (j *Jenkins) Cancel(ctx context.Context, id string, comment string) error {
+	queue, jobName, number, err := parseID(id)
+	if err != nil {
+		return err
+	}
+	if queue != 0 {
+		_, err = j.client.post(ctx, "/queue/cancelItem", url.Values{"id": {strconv.FormatInt(queue, 10)}})
+		return err
+	}
+	_, err = j.client.post(ctx, j.buildPath(jobName, number)+"/stop", nil)
+	return err
+}
+
+// started resolves id to a job and build number, failing while it is queued
+func (j *Jenkins) started(ctx context.Context, id string) (string, int64, error) {
+	queue, jobName, number, err := parseID(id)
+	if err != nil || queue == 0 {
+		return jobName, number, err
+	}
+	b, err := j.Status(ctx, id)
+	if err != nil {
+		return "", 0, err
+	}
+	if b.State == ci.StateQueued || b.Number == "" {
+		return "", 0, ErrNotStarted
+	}
+	number, err = strconv.ParseInt(b.Number, 10, 64)
+	return b.ConfigID, number, err
+}
+
+// Log returns the console output of the build
+func (j *Jenkins) Log(ctx context.Context, id string) (string, error) {
+	jobName, number, err := j.started(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	return j.client.getText(ctx, j.buildPath(jobName, number)+"/consoleText")
+}
+
+// Artifacts lists the files archived by the build
+func (j *Jenkins) Artifacts(ctx context.Context, id string) ([]ci.Artifact, error) {
+	jobName, number, err := j.started(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	var b build
+	q := url.Values{"tree": {"artifacts[fileName,relativePath]"}}
+	if err := j.client.getJSON(ctx, j.buildPath(jobName, number), q, &b); err != nil {
+		return nil, err
+	}
+	var artifacts []ci.Artifact
+	for _, a := range b.Artifacts {
+		artifacts = append(artifacts, ci.Artifact{
+			Name: a.FileName,
+			URL:  j.client.baseURL + j.buildPath(jobName, number) + "/artifact/" + a.RelativePath,
+		})
+	}
+	return artifacts, nil
+}
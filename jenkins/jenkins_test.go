@@ -0,0 +1,201 @@
+package jenkins
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mkobaly/hipchatBot/ci"
+	"github.com/mkobaly/hipchatBot/ci/citest"
+	"github.com/mkobaly/hipchatBot/config"
+)
+
+// standIn is a minimal Jenkins answering for a job "web/deploy" whose
+// queue item 17 has become build 45, a job "tools_CI" without parameters
+// building master and a job "docs" without parameters or Git branches
+func standIn(t *testing.T) (*Jenkins, *citest.StandIn) {
+	s := citest.NewStandIn(t)
+	s.HandleFunc("/api/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jobs":[{"name":"web","jobs":[{"name":"deploy"}]},{"name":"tools_CI"}]}`))
+	})
+	s.HandleFunc("/job/web/job/deploy/api/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"property":[{},{"parameterDefinitions":[{"name":"Branch"},{"name":"` + ci.ChatUserParam + `"}]}]}`))
+	})
+	s.HandleFunc("/job/tools_CI/api/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"property":[],"scm":{"branches":[{"name":"*/master"}]}}`))
+	})
+	s.HandleFunc("/job/tools_CI/build", func(w http.ResponseWriter, r *http.Request) {
+		s.Record("build tools_CI")
+		w.Header().Set("Location", "http://"+r.Host+"/queue/item/18/")
+		w.WriteHeader(http.StatusCreated)
+	})
+	s.HandleFunc("/job/docs/api/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"property":[],"scm":{}}`))
+	})
+	s.HandleFunc("/job/docs/build", func(w http.ResponseWriter, r *http.Request) {
+		s.Record("build docs")
+		w.Header().Set("Location", "http://"+r.Host+"/queue/item/19/")
+		w.WriteHeader(http.StatusCreated)
+	})
+	s.HandleFunc("/job/tools_CI/buildWithParameters", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	})
+	s.HandleFunc("/job/web/job/deploy/buildWithParameters", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			t.Errorf("expected POST got %v", r.Method)
+		}
+		r.ParseForm()
+		s.Record("trigger " + r.Form.Get("Branch") + " " + r.Form.Get(ci.ChatUserParam))
+		w.Header().Set("Location", "http://"+r.Host+"/queue/item/17/")
+		w.WriteHeader(http.StatusCreated)
+	})
+	s.HandleFunc("/queue/item/17/api/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":17,"task":{"name":"deploy","url":"http://jenkins/job/web/job/deploy/"},"executable":{"number":45}}`))
+	})
+	s.HandleFunc("/job/web/job/deploy/45/api/json", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("tree") != "" {
+			w.Write([]byte(`{"artifacts":[{"fileName":"site.zip","relativePath":"out/site.zip"}]}`))
+			return
+		}
+		w.Write([]byte(`{"number":45,"building":false,"result":"FAILURE","url":"http://jenkins/job/web/job/deploy/45/",
+			"actions":[{"parameters":[{"name":"Branch","value":"master"}]}]}`))
+	})
+	s.HandleFunc("/job/web/job/deploy/45/consoleText", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("Started\nFinished: FAILURE\n"))
+	})
+	s.HandleFunc("/job/web/job/deploy/45/stop", func(w http.ResponseWriter, r *http.Request) {
+		s.Record("stop")
+	})
+	s.HandleFunc("/queue/cancelItem", func(w http.ResponseWriter, r *http.Request) {
+		s.Record("cancel " + r.FormValue("id"))
+	})
+
+	j := New(config.UserCredential{Type: config.TypeJenkins, URL: s.URL, Username: "bot", Token: "api"})
+	j.client.Backoff = time.Millisecond
+	return j, s
+}
+
+func TestList(t *testing.T) {
+	j, _ := standIn(t)
+	ids, err := j.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(ids, ",") != "web/deploy,tools_CI" {
+		t.Errorf("unexpected jobs %v", ids)
+	}
+}
+
+func TestTriggerAndStatus(t *testing.T) {
+	j, s := standIn(t)
+	ctx := context.Background()
+
+	b, err := j.Trigger(ctx, ci.BuildRequest{ConfigID: "web/deploy", Branch: "master", User: "Jo (@jo)"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.ID != "queue#17" || b.State != ci.StateQueued {
+		t.Errorf("unexpected queued build %+v", b)
+	}
+	if len(s.Calls()) != 1 || s.Calls()[0] != "trigger master Jo (@jo)" {
+		t.Errorf("unexpected calls %v", s.Calls())
+	}
+
+	b, err = j.Status(ctx, b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.ID != "web/deploy#45" || b.ConfigID != "web/deploy" || b.Branch != "master" {
+		t.Errorf("unexpected build %+v", b)
+	}
+	if !b.Finished() || b.Status != ci.StatusFailure {
+		t.Errorf("expected finished failure got %v %v", b.State, b.Status)
+	}
+}
+
+func TestTriggerWithoutParameters(t *testing.T) {
+	j, s := standIn(t)
+	b, err := j.Trigger(context.Background(), ci.BuildRequest{ConfigID: "tools_CI", Branch: "master", User: "Jo (@jo)"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.ID != "queue#18" || b.Branch != "master" {
+		t.Errorf("unexpected queued build %+v", b)
+	}
+
+	// the branches docs builds are not known, so the build does not claim one
+	b, err = j.Trigger(context.Background(), ci.BuildRequest{ConfigID: "docs", Branch: "main"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.ID != "queue#19" || b.Branch != "" {
+		t.Errorf("unexpected queued build %+v", b)
+	}
+	if strings.Join(s.Calls(), ",") != "build tools_CI,build docs" {
+		t.Errorf("unexpected calls %v", s.Calls())
+	}
+}
+
+func TestTriggerWhatTheJobCanNotBuild(t *testing.T) {
+	j, s := standIn(t)
+	for _, req := range []ci.BuildRequest{
+		{ConfigID: "tools_CI", Branch: "master", Revision: "abc123"},
+		{ConfigID: "tools_CI", Branch: "feature/x"},
+		{ConfigID: "docs", Branch: "main", Revision: "abc123"},
+		{ConfigID: "web/deploy", Branch: "main", Revision: "abc123"},
+	} {
+		if b, err := j.Trigger(context.Background(), req); err == nil {
+			t.Errorf("%+v kicked off %+v", req, b)
+		}
+	}
+	if len(s.Calls()) != 0 {
+		t.Errorf("unexpected calls %v", s.Calls())
+	}
+}
+
+func TestLogAndArtifacts(t *testing.T) {
+	j, _ := standIn(t)
+	ctx := context.Background()
+
+	text, err := j.Log(ctx, "queue#17")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text, "Finished: FAILURE") {
+		t.Errorf("unexpected log %q", text)
+	}
+
+	artifacts, err := j.Artifacts(ctx, "web/deploy#45")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(artifacts) != 1 || artifacts[0].Name != "site.zip" ||
+		!strings.HasSuffix(artifacts[0].URL, "/job/web/job/deploy/45/artifact/out/site.zip") {
+		t.Errorf("unexpected artifacts %+v", artifacts)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	j, s := standIn(t)
+	ctx := context.Background()
+
+	if err := j.Cancel(ctx, "queue#17", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Cancel(ctx, "web/deploy#45", ""); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(s.Calls(), ",") != "cancel 17,stop" {
+		t.Errorf("unexpected calls %v", s.Calls())
+	}
+}
+
+func TestUnknownJob(t *testing.T) {
+	j, _ := standIn(t)
+	_, err := j.Status(context.Background(), "nope#1")
+	if e, ok := err.(*Error); !ok || e.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 error got %v", err)
+	}
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mkobaly/hipchatBot/chat"
+	"github.com/mkobaly/hipchatBot/ci"
+	"github.com/mkobaly/hipchatBot/config"
+)
+
+// Which limit turned a kick down, as the rejections are counted in the
+// metrics
+const (
+	limitUser   = "user"
+	limitRoom   = "room"
+	limitConfig = "config"
+)
+
+// runningBuild is a build kicked off by the bot that has not finished yet
+type runningBuild struct {
+	configID string
+	started  time.Time
+}
+
+// configKey is a build configuration of a CI server
+type configKey struct {
+	provider ci.Provider
+	configID string
+}
+
+// limiter enforces the configured limits on kicks. It keeps the times of each
+// user's and room's kicks over the last hour and the builds kicked off by the
+// bot that are still queued or running.
+type limiter struct {
+	limits config.Limits
+	// now is the clock the kicks are timed by
+	now      func() time.Time
+	mu       sync.Mutex
+	users    map[string][]time.Time
+	rooms    map[string][]time.Time
+	running  map[eventKey]runningBuild
+	rejected map[string]int
+}
+
+func newLimiter(limits config.Limits) *limiter {
+	return &limiter{
+		limits:   limits,
+		now:      time.Now,
+		users:    make(map[string][]time.Time),
+		rooms:    make(map[string][]time.Time),
+		running:  make(map[eventKey]runningBuild),
+		rejected: make(map[string]int),
+	}
+}
+
+// allow checks a kick of the configuration by the user from the room against
+// the limits. It returns why the kick was turned down, or "" when it can go
+// ahead. The kick only counts once kicked records it. Scheduled builds have
+// no user and room and are only held to the limit on concurrent builds.
+func (l *limiter) allow(user chat.User, roomID string, p ci.Provider, configID string) string {
+	if l == nil {
+		return ""
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	userKey := limitKey(user)
+	if userKey != "" && l.limits.UserKicksPerHour > 0 {
+		kicks := recent(l.users, userKey, now)
+		if len(kicks) >= l.limits.UserKicksPerHour {
+			l.rejected[limitUser]++
+			return fmt.Sprintf("Sorry %v, you have kicked off %v builds in the last hour, the most anyone can. Try again in %v.", user.Name, len(kicks), retryIn(kicks[0], now))
+		}
+	}
+	if roomID != "" && l.limits.RoomKicksPerHour > 0 {
+		kicks := recent(l.rooms, roomID, now)
+		if len(kicks) >= l.limits.RoomKicksPerHour {
+			l.rejected[limitRoom]++
+			return fmt.Sprintf("Sorry %v, this room has kicked off %v builds in the last hour, the most a room can. Try again in %v.", user.Name, len(kicks), retryIn(kicks[0], now))
+		}
+	}
+	if l.limits.ConcurrentBuilds > 0 {
+		if n := l.runningBuilds()[configKey{p, configID}]; n >= l.limits.ConcurrentBuilds {
+			l.rejected[limitConfig]++
+			return fmt.Sprintf("%v already has %v builds kicked off by the bot queued or running. Try again once one of them finishes.", configID, n)
+		}
+	}
+	return ""
+}
+
+// kicked counts a kick the CI server took against the user's and the room's
+// hourly limits
+func (l *limiter) kicked(user chat.User, roomID string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	if userKey := limitKey(user); userKey != "" {
+		l.users[userKey] = append(l.users[userKey], now)
+	}
+	if roomID != "" {
+		l.rooms[roomID] = append(l.rooms[roomID], now)
+	}
+}
+
+// limitKey is what the kicks of the user are counted by
+func limitKey(user chat.User) string {
+	if user.ID != "" {
+		return user.ID
+	}
+	return user.MentionName
+}
+
+// started records a build kicked off by the bot until it finishes
+func (l *limiter) started(p ci.Provider, configID string, id string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.running[eventKey{p, id}] = runningBuild{configID: configID, started: l.now()}
+}
+
+// finished drops a build that is no longer queued or running
+func (l *limiter) finished(p ci.Provider, id string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.running, eventKey{p, id})
+}
+
+// runningBuilds counts the unfinished builds of each configuration. Builds
+// followed for longer than watchTimeout are no longer followed and left out.
+// l.mu must be held.
+func (l *limiter) runningBuilds() map[configKey]int {
+	counts := make(map[configKey]int)
+	now := l.now()
+	for key, b := range l.running {
+		if now.Sub(b.started) > watchTimeout {
+			delete(l.running, key)
+			continue
+		}
+		counts[configKey{key.provider, b.configID}]++
+	}
+	return counts
+}
+
+// recent drops the kicks of key older than an hour and returns the rest,
+// oldest first
+func recent(kicks map[string][]time.Time, key string, now time.Time) []time.Time {
+	times := kicks[key]
+	i := 0
+	for i < len(times) && now.Sub(times[i]) >= time.Hour {
+		i++
+	}
+	times = times[i:]
+	if len(times) == 0 {
+		delete(kicks, key)
+	} else {
+		kicks[key] = times
+	}
+	return times
+}
+
+// retryIn is how long until the oldest kick of the last hour no longer counts
+func retryIn(oldest time.Time, now time.Time) string {
+	minutes := int((oldest.Add(time.Hour).Sub(now) + time.Minute - 1) / time.Minute)
+	if minutes == 1 {
+		return "1 minute"
+	}
+	return fmt.Sprintf("%v minutes", minutes)
+}
+
+// writeMetrics writes the limits and the limiter's state in the Prometheus
+// text format
+func (l *limiter) writeMetrics(w io.Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	fmt.Fprintln(w, "# HELP hipchatbot_limit Configured limits on kicks, zero is no limit.")
+	fmt.Fprintln(w, "# TYPE hipchatbot_limit gauge")
+	fmt.Fprintf(w, "hipchatbot_limit{limit=%q} %v\n", "user_kicks_per_hour", l.limits.UserKicksPerHour)
+	fmt.Fprintf(w, "hipchatbot_limit{limit=%q} %v\n", "room_kicks_per_hour", l.limits.RoomKicksPerHour)
+	fmt.Fprintf(w, "hipchatbot_limit{limit=%q} %v\n", "concurrent_builds", l.limits.ConcurrentBuilds)
+
+	fmt.Fprintln(w, "# HELP hipchatbot_kicks_rejected_total Kicks turned down, by the limit they were over.")
+	fmt.Fprintln(w, "# TYPE hipchatbot_kicks_rejected_total counter")
+	for _, limit := range []string{limitUser, limitRoom, limitConfig} {
+		fmt.Fprintf(w, "hipchatbot_kicks_rejected_total{limit=%q} %v\n", limit, l.rejected[limit])
+	}
+
+	fmt.Fprintln(w, "# HELP hipchatbot_user_kicks Kicks of each user in the last hour.")
+	fmt.Fprintln(w, "# TYPE hipchatbot_user_kicks gauge")
+	for _, user := range sortedKeys(l.users) {
+		fmt.Fprintf(w, "hipchatbot_user_kicks{user=%q} %v\n", user, len(recent(l.users, user, now)))
+	}
+
+	fmt.Fprintln(w, "# HELP hipchatbot_room_kicks Kicks from each room in the last hour.")
+	fmt.Fprintln(w, "# TYPE hipchatbot_room_kicks gauge")
+	for _, room := range sortedKeys(l.rooms) {
+		fmt.Fprintf(w, "hipchatbot_room_kicks{room=%q} %v\n", room, len(recent(l.rooms, room, now)))
+	}
+
+	fmt.Fprintln(w, "# HELP hipchatbot_running_builds Builds kicked off by the bot that are queued or running, by build configuration.")
+	fmt.Fprintln(w, "# TYPE hipchatbot_running_builds gauge")
+	byConfig := make(map[string]int)
+	for key, n := range l.runningBuilds() {
+		byConfig[key.configID] += n
+	}
+	var configs []string
+	for id := range byConfig {
+		configs = append(configs, id)
+	}
+	sort.Strings(configs)
+	for _, id := range configs {
+		fmt.Fprintf(w, "hipchatbot_running_builds{config=%q} %v\n", id, byConfig[id])
+	}
+}
+
+// sortedKeys returns the keys of kicks in order
+func sortedKeys(kicks map[string][]time.Time) []string {
+	var keys []string
+	for key := range kicks {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// metrics serves the limiter's state to Prometheus
+func (c *Context) metrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	l := c.limits
+	if l == nil {
+		l = newLimiter(config.Limits{})
+	}
+	l.writeMetrics(w)
+}
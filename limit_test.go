@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mkobaly/hipchatBot/chat"
+	"github.com/mkobaly/hipchatBot/config"
+)
+
+func TestUserLimit(t *testing.T) {
+	c, p := settingsContext(t)
+	c.limits = newLimiter(config.Limits{UserKicksPerHour: 2})
+	ctx := context.Background()
+	ada := chat.User{ID: "2", Name: "Ada Lovelace", MentionName: "Ada"}
+	grace := chat.User{ID: "1", Name: "Grace", MentionName: "grace"}
+	run := func(user chat.User) replies {
+		var r replies
+		c.dispatch(ctx, chat.Command{Text: "/build kick Web_CI main", User: user, RoomID: "1", Source: "test"}, &r)
+		return r
+	}
+	// kicks the CI server turned down do not count
+	p.err = errors.New("agents are down")
+	run(ada)
+	p.err = nil
+	run(ada)
+	run(ada)
+	r := run(ada)
+	if len(p.kicked) != 2 {
+		t.Errorf("kicked off over the limit: %+v", p.kicked)
+	}
+	if len(r) != 1 || r[0].Color != chat.ColorYellow || !strings.HasPrefix(r[0].Text, "Sorry Ada Lovelace, you have kicked off 2 builds in the last hour") {
+		t.Errorf("unexpected rejection %+v", r)
+	}
+	run(grace)
+	if len(p.kicked) != 3 {
+		t.Errorf("limit of one user applied to another: %+v", p.kicked)
+	}
+}
+
+func TestConcurrentLimit(t *testing.T) {
+	c, p := settingsContext(t)
+	c.limits = newLimiter(config.Limits{ConcurrentBuilds: 1})
+	ada := chat.User{ID: "2", Name: "Ada Lovelace", MentionName: "Ada"}
+	for i := 0; i < 3; i++ {
+		// replies can not post the build result, the build still counts
+		var r replies
+		c.dispatch(context.Background(), chat.Command{Text: "/build kick Web_CI main", User: ada, RoomID: "1", Source: "test"}, &r)
+	}
+	if len(p.kicked) != 1 {
+		t.Errorf("kicked off %v concurrent builds, want 1", len(p.kicked))
+	}
+	c.limits.finished(p, "1")
+	var r replies
+	c.dispatch(context.Background(), chat.Command{Text: "/build kick Web_CI main", User: ada, RoomID: "1", Source: "test"}, &r)
+	if len(p.kicked) != 2 {
+		t.Errorf("not kicked off once the build finished: %+v", r)
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	l := newLimiter(config.Limits{RoomKicksPerHour: 1, ConcurrentBuilds: 1})
+	now := time.Now()
+	l.now = func() time.Time { return now }
+	p := &kickProvider{}
+	ada := chat.User{ID: "2", Name: "Ada"}
+
+	if rejection := l.allow(ada, "1", p, "Web_CI"); rejection != "" {
+		t.Fatal(rejection)
+	}
+	l.kicked(ada, "1")
+	l.started(p, "Web_CI", "100")
+	now = now.Add(10 * time.Minute)
+	rejection := l.allow(ada, "1", p, "Web_CI")
+	if want := "Sorry Ada, this room has kicked off 1 builds in the last hour, the most a room can. Try again in 50 minutes."; rejection != want {
+		t.Errorf("got %q, want %q", rejection, want)
+	}
+	if rejection := l.allow(chat.User{}, "", p, "Web_CI"); !strings.Contains(rejection, "already has 1 builds") {
+		t.Errorf("concurrent builds not limited: %q", rejection)
+	}
+	if rejection := l.allow(chat.User{}, "", p, "Api_CI"); rejection != "" {
+		t.Errorf("other configuration limited: %q", rejection)
+	}
+	now = now.Add(watchTimeout)
+	if rejection := l.allow(chat.User{}, "", p, "Web_CI"); rejection != "" {
+		t.Errorf("build no longer followed still counted: %q", rejection)
+	}
+	l.started(p, "Web_CI", "101")
+	l.finished(p, "101")
+	if rejection := l.allow(ada, "1", p, "Web_CI"); rejection != "" {
+		t.Errorf("limited after the hour and the build finished: %q", rejection)
+	}
+
+	w := httptest.NewRecorder()
+	(&Context{limits: l}).metrics(w, httptest.NewRequest("GET", "/metrics", nil))
+	for _, line := range []string{
+		`hipchatbot_limit{limit="room_kicks_per_hour"} 1`,
+		`hipchatbot_kicks_rejected_total{limit="room"} 1`,
+		`hipchatbot_kicks_rejected_total{limit="config"} 1`,
+		`hipchatbot_user_kicks{user="2"} 0`,
+	} {
+		if !strings.Contains(w.Body.String(), line+"\n") {
+			t.Errorf("metrics miss %q:\n%v", line, w.Body)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"flag"
@@ -9,16 +10,30 @@ import (
 	"html/template"
 	"log"
 	"net/http"
+	"os"
 	"path"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode"
 
 	"sort"
 
 	"github.com/gorilla/mux"
+	"github.com/mkobaly/hipchatBot/audit"
+	"github.com/mkobaly/hipchatBot/chat"
+	"github.com/mkobaly/hipchatBot/ci"
 	"github.com/mkobaly/hipchatBot/config"
+	"github.com/mkobaly/hipchatBot/cron"
+	"github.com/mkobaly/hipchatBot/github"
+	"github.com/mkobaly/hipchatBot/gitlab"
+	"github.com/mkobaly/hipchatBot/jenkins"
+	"github.com/mkobaly/hipchatBot/mattermost"
+	"github.com/mkobaly/hipchatBot/slack"
+	"github.com/mkobaly/hipchatBot/store"
 	"github.com/mkobaly/hipchatBot/teamcity"
+	"github.com/mkobaly/hipchatBot/teams"
 	"github.com/mkobaly/hipchatBot/util"
 	"github.com/tbruyelle/hipchat-go/hipchat"
 )
@@ -42,21 +57,170 @@ type HipchatItem struct {
 	Room    *hipchat.Room
 }
 
+// messageSender returns who sent m. HipChat sends "from" as an object for
+// users and as a plain string for integrations.
+func messageSender(m *hipchat.Message) chat.User {
+	var u chat.User
+	switch from := m.From.(type) {
+	case map[string]interface{}:
+		if id, ok := from["id"].(float64); ok {
+			u.ID = strconv.Itoa(int(id))
+		}
+		u.Name, _ = from["name"].(string)
+		u.MentionName, _ = from["mention_name"].(string)
+	case string:
+		u.Name = from
+		u.MentionName = from
+	}
+	return u
+}
+
 // RoomConfig holds information to send messages to a specific room
 type RoomConfig struct {
 	token *hipchat.OAuthAccessToken
 	hc    *hipchat.Client
 	name  string
+	// oauthID and oauthSecret are the installation's OAuth client, which
+	// signs the signed_request of add-on pages
+	oauthID     string
+	oauthSecret string
 }
 
 // Context keep context of the running application
 type Context struct {
 	baseURL string
 	static  string
-	//rooms per room OAuth configuration and client
-	rooms   map[string]*RoomConfig
-	builder *teamcity.Builder
-	cfg     *config.Config
+	//rooms per room OAuth configuration and client, guarded by mu
+	mu    sync.Mutex
+	rooms map[string]*RoomConfig
+	//notifiers post to a room of a chat platform, by command source. Guarded
+	//by mu.
+	notifiers map[string]func(roomID string, roomName string) chat.Notifier
+	providers map[string]ci.Provider
+	cfg       *config.Config
+	//health is the state of the builds watched in each room
+	health *roomHealth
+	//store holds the room settings saved from the config page and the
+	//rooms' watches
+	store *store.Store
+	//subs are the builds of the watches already posted
+	subs *subscriptions
+	//events passes webhook build events to the watchers of kicked off builds
+	events *buildEvents
+	//confirms are the kicks of protected configurations waiting for their
+	//confirmation
+	confirms *confirmations
+	//limits caps the builds kicked off through the bot
+	limits *limiter
+	//audit logs every command and the builds kicked off by the bot
+	audit *audit.Log
+}
+
+// newProvider creates the CI provider for the kind of server creds points at
+func newProvider(creds config.UserCredential) (ci.Provider, error) {
+	switch creds.ServerType() {
+	case config.TypeTeamcity:
+		return teamcity.New(creds), nil
+	case config.TypeJenkins:
+		return jenkins.New(creds), nil
+	case config.TypeGitlab:
+		return gitlab.New(creds), nil
+	case config.TypeGithub:
+		return github.New(creds), nil
+	}
+	return nil, fmt.Errorf("unknown CI server type %q", creds.Type)
+}
+
+func newProviders(cfg *config.Config) map[string]ci.Provider {
+	providers := make(map[string]ci.Provider)
+	for name, creds := range cfg.Servers() {
+		p, err := newProvider(creds)
+		if err != nil {
+			log.Fatalf("Server %v: %v", name, err)
+		}
+		providers[name] = p
+	}
+	return providers
+}
+
+// roomSettings returns the settings saved for the room from its config page,
+// or the ones in config.yaml when there are none
+func (c *Context) roomSettings(roomID string) config.RoomSettings {
+	if c.store != nil {
+		if rs, ok := c.store.Room(roomID); ok {
+			return rs
+		}
+	}
+	if c.cfg == nil {
+		return config.RoomSettings{}
+	}
+	return c.cfg.Rooms[roomID]
+}
+
+// providerFor picks the CI server a command runs against: --server when
+// given, then the server buildConfigID is routed to, then the room's default
+// server
+func (c *Context) providerFor(roomID string, flags commandFlags, buildConfigID string) (string, ci.Provider, error) {
+	name := flags.Get("server")
+	if name == "" {
+		name = c.cfg.Route(buildConfigID)
+	}
+	if name == "" {
+		name = c.roomSettings(roomID).Server
+	}
+	if name == "" {
+		name = c.cfg.ServerForRoom(roomID)
+	}
+	p, ok := c.providers[name]
+	if !ok {
+		return name, nil, fmt.Errorf("unknown CI server %q", name)
+	}
+	return name, p, nil
+}
+
+// pickProvider is providerFor for command handlers. It tells the room when
+// the server is unknown and only names the server when there is a choice of
+// more than one.
+func (c *Context) pickProvider(ctx context.Context, r chat.Replier, roomID string, flags commandFlags, buildConfigID string) (string, ci.Provider, bool) {
+	server, p, err := c.providerFor(roomID, flags, buildConfigID)
+	if err != nil {
+		r.Reply(ctx, chat.Message{Text: err.Error(), Color: chat.ColorRed})
+		return "", nil, false
+	}
+	if len(c.providers) < 2 {
+		server = ""
+	}
+	return server, p, true
+}
+
+// serverNames returns the configured server names in a stable order
+func (c *Context) serverNames() []string {
+	var names []string
+	for name := range c.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// statusColor is the message color for the state of a build
+func statusColor(b *ci.Build) string {
+	switch {
+	case b.Finished() && b.Status == ci.StatusSuccess:
+		return chat.ColorGreen
+	case b.Finished() && b.Status == ci.StatusFailure:
+		return chat.ColorRed
+	}
+	return chat.ColorYellow
+}
+
+// logTail returns the last n lines of a build log
+func logTail(log string, n int) string {
+	lines := strings.Split(strings.TrimRight(log, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
 }
 
 func (c *Context) healthcheck(w http.ResponseWriter, r *http.Request) {
@@ -75,46 +239,114 @@ func (c *Context) atlassianConnect(w http.ResponseWriter, r *http.Request) {
 	tmpl.ExecuteTemplate(w, "config", vals)
 }
 
+// roomClient gets a token for the installation and returns the client posting
+// to its room with it
+func roomClient(install store.Install) (*hipchat.Client, error) {
+	credentials := hipchat.ClientCredentials{
+		ClientID:     install.OAuthID,
+		ClientSecret: install.OAuthSecret,
+	}
+	tok, _, err := hipchat.NewClient("").GenerateToken(credentials, []string{hipchat.ScopeSendNotification, hipchat.ScopeViewRoom})
+	if err != nil {
+		return nil, err
+	}
+	return tok.CreateClient(), nil
+}
+
+// restoreInstalls brings back the installations saved in the store, without
+// clients to post to their rooms until connectInstalls gets them
+func (c *Context) restoreInstalls() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, i := range c.store.Installs() {
+		c.rooms[i.RoomID] = &RoomConfig{name: i.RoomID, oauthID: i.OAuthID, oauthSecret: i.OAuthSecret}
+	}
+}
+
+// connectInstalls gets the clients of the restored installations
+func (c *Context) connectInstalls() {
+	for _, i := range c.store.Installs() {
+		hc, err := roomClient(i)
+		if err != nil {
+			log.Printf("Error getting a token for room %v: %v", i.RoomID, err)
+			continue
+		}
+		c.mu.Lock()
+		if rc := c.rooms[i.RoomID]; rc != nil && rc.hc == nil && rc.oauthID == i.OAuthID {
+			rc.hc = hc
+		}
+		c.mu.Unlock()
+	}
+}
+
 func (c *Context) installable(w http.ResponseWriter, r *http.Request) {
 	authPayload, err := util.DecodePostJSON(r, true)
 	if err != nil {
 		log.Fatalf("Parsed auth data failed:%v\n", err)
 	}
 
-	credentials := hipchat.ClientCredentials{
-		ClientID:     authPayload["oauthId"].(string),
-		ClientSecret: authPayload["oauthSecret"].(string),
+	install := store.Install{
+		RoomID:      strconv.Itoa(int(authPayload["roomId"].(float64))),
+		OAuthID:     authPayload["oauthId"].(string),
+		OAuthSecret: authPayload["oauthSecret"].(string),
 	}
-	roomName := strconv.Itoa(int(authPayload["roomId"].(float64)))
-	newClient := hipchat.NewClient("")
-	tok, _, err := newClient.GenerateToken(credentials, []string{hipchat.ScopeSendNotification})
+	hc, err := roomClient(install)
 	if err != nil {
 		log.Fatalf("Client.GetAccessToken returns an error %v", err)
 	}
-	rc := &RoomConfig{
-		name: roomName,
-		hc:   tok.CreateClient(),
+	c.mu.Lock()
+	c.rooms[install.RoomID] = &RoomConfig{
+		name:        install.RoomID,
+		hc:          hc,
+		oauthID:     install.OAuthID,
+		oauthSecret: install.OAuthSecret,
+	}
+	c.mu.Unlock()
+	// the installation is kept so the add-on's pages can still be verified
+	// after a restart
+	if err := c.store.SetInstall(install); err != nil {
+		log.Printf("Error saving the installation in room %v: %v", install.RoomID, err)
 	}
-	c.rooms[roomName] = rc
 
 	util.PrintDump(w, r, false)
 	json.NewEncoder(w).Encode([]string{"OK"})
 }
 
-func (c *Context) config(w http.ResponseWriter, r *http.Request) {
-	signedRequest := r.URL.Query().Get("signed_request")
-	lp := path.Join("./static", "layout.hbs")
-	fp := path.Join("./static", "config.hbs")
-	vals := map[string]string{
-		"LocalBaseUrl":  c.baseURL,
-		"SignedRequest": signedRequest,
-		"HostScriptUrl": c.baseURL,
+// hipchatReplier posts replies to the room through the HipChat integration
+// URL. Format is the HipChat message format, "html" or "text". HTML replies
+// about a build are sent as cards with icons from baseURL.
+type hipchatReplier struct {
+	url     string
+	format  string
+	baseURL string
+}
+
+func (h hipchatReplier) Reply(ctx context.Context, m chat.Message) error {
+	if h.format == "text" {
+		message := m.Text
+		if m.Template != "" {
+			message = renderTemplate(FormatText, m.Template, m.Data)
+		}
+		return postToHipchat(h.url, message, m.Color, "text")
 	}
-	tmpl, err := template.ParseFiles(lp, fp)
-	if err != nil {
-		log.Fatalf("%v", err)
+	message := "<b>" + template.HTMLEscapeString(m.Text) + "</b>"
+	if m.Template != "" {
+		message = renderTemplate(FormatHTML, m.Template, m.Data)
+	}
+	if card := buildCard(m, h.baseURL); card != nil {
+		return postNotification(h.url, &hipchat.NotificationRequest{
+			Color:         hipchat.Color(m.Color),
+			Message:       message,
+			MessageFormat: "html",
+			Card:          card,
+		})
 	}
-	tmpl.ExecuteTemplate(w, "layout", vals)
+	return postToHipchat(h.url, message, m.Color, "html")
+}
+
+// Notify sends the result of a build kicked off from the room
+func (h hipchatReplier) Notify(ctx context.Context, m chat.Message) error {
+	return h.Reply(ctx, m)
 }
 
 func (c *Context) hook(w http.ResponseWriter, r *http.Request) {
@@ -124,86 +356,589 @@ func (c *Context) hook(w http.ResponseWriter, r *http.Request) {
 		log.Fatalf("Err decoding request body: %v\n", err)
 	}
 
-	cmd := strings.Split(p.Item.Message.Message, " ")
+	cmd := chat.Command{
+		Text:   p.Item.Message.Message,
+		User:   messageSender(p.Item.Message),
+		Source: "HipChat",
+	}
+	if p.Item.Room != nil {
+		cmd.RoomID = strconv.Itoa(p.Item.Room.ID)
+		cmd.RoomName = p.Item.Room.Name
+	}
+	c.dispatch(r.Context(), cmd, hipchatReplier{url: c.cfg.HipchatURL, format: "html", baseURL: c.baseURL})
+}
+
+// dispatch runs a bot command from any of the chat platforms, sending the
+// replies through r
+func (c *Context) dispatch(ctx context.Context, command chat.Command, r chat.Replier) {
+	entry := commandEntry(command, time.Now())
+	defer c.writeAudit(entry)
+	r = audited(r, entry)
+	reply := func(m chat.Message) {
+		if err := r.Reply(ctx, m); err != nil {
+			log.Printf("Error replying to %v: %v", command.Source, err)
+		}
+	}
+	notice := func(text string, color string) {
+		reply(chat.Message{Text: text, Color: color})
+	}
+	help := func(color string) {
+		reply(chat.Message{Template: "help", Color: color})
+	}
+
+	cmd, flags := splitFlags(splitCommand(command.Text))
 	if len(cmd) < 2 {
-		postToHipchat(c.cfg.HipchatURL, parseHTMLTemplate("help", nil), "yellow", "html")
+		entry.Action = "help"
+		help(chat.ColorYellow)
 		return
 	}
 	action := cmd[1]
-
+	entry.Action = action
+	_, entry.ConfigID, _ = permission(cmd)
+	sender := command.User
+	settings := c.roomSettings(command.RoomID)
+	if !settings.Allowed(command.Source, sender.ID, sender.MentionName) {
+		notice("Sorry "+sender.Name+", you are not allowed to run builds in this room", chat.ColorRed)
+		return
+	}
+	if name, configID, ok := permission(cmd); ok && c.cfg != nil && c.cfg.Permissions.Enabled() {
+		role := c.cfg.Permissions.RoleOf(command.Source, sender.ID, sender.MentionName)
+		if !c.cfg.Permissions.Allows(role, name, configID) {
+			log.Printf("Denied %v %v to %v with role %q", name, configID, sender, role)
+			notice(denial(sender, role, name, configID), chat.ColorRed)
+			return
+		}
+	}
 	switch action {
 	case "list":
-		builds, err := c.builder.GetBuilds()
-		sort.Sort(teamcity.ById(builds))
-		if err == nil {
-			var bIds []string
-			for _, r := range builds {
-				if strings.HasSuffix(r.ID, "_RC") || strings.HasSuffix(r.ID, "_CI") {
-					bIds = append(bIds, r.ID)
+		// list goes across every server unless one is asked for
+		servers := c.serverNames()
+		if flags.Get("server") != "" {
+			servers = []string{flags.Get("server")}
+		}
+		var lists []chat.ServerConfigs
+		failed := 0
+		for _, name := range servers {
+			sb := chat.ServerConfigs{Server: name}
+			if len(servers) == 1 {
+				sb.Server = ""
+			}
+			provider, ok := c.providers[name]
+			if !ok {
+				notice("Unknown CI server "+name, chat.ColorRed)
+				return
+			}
+			ids, err := provider.List(ctx)
+			if err != nil {
+				log.Printf("Error getting build list from %v: %v", name, err)
+				sb.Error = true
+				failed++
+			}
+			creds := c.cfg.Servers()[name]
+			for _, id := range ids {
+				if creds.Listed(id) && settings.Visible(id) {
+					sb.IDs = append(sb.IDs, id)
 				}
 			}
-			message := parseHTMLTemplate("list", bIds)
-			postToHipchat(c.cfg.HipchatURL, message, "green", "html")
-		} else {
-			postToHipchat(c.cfg.HipchatURL, "<b>Error getting build list</b>", "red", "html")
+			lists = append(lists, sb)
+		}
+		if failed == len(lists) {
+			notice("Error getting build list", chat.ColorRed)
+			return
+		}
+		color := chat.ColorGreen
+		if failed > 0 {
+			color = chat.ColorYellow
 		}
+		reply(chat.Message{Template: "list", Data: lists, Color: color})
 		return
 	case "kick":
+		if len(cmd) == 3 && settings.Branch != "" {
+			cmd = append(cmd, settings.Branch)
+		}
 		if len(cmd) != 4 {
-			postToHipchat(c.cfg.HipchatURL, parseHTMLTemplate("help", nil), "yellow", "html")
+			help(chat.ColorYellow)
 			return
 		}
-		buildConfig, branch := cmd[2], cmd[3]
-		bi := teamcity.BuildInfo{BuildConfigID: buildConfig, Branch: branch}
-		c.builder.SetBuildInfo(bi)
-
-		params := make(map[string]string)
-		params["Branch"] = branch
+		buildConfig, branch, revision := cmd[2], cmd[3], flags.Get("revision")
+		if !settings.Visible(buildConfig) {
+			notice(buildConfig+" can not be kicked off from this room", chat.ColorYellow)
+			return
+		}
+		entry.Branch = branch
+		server, provider, ok := c.pickProvider(ctx, r, command.RoomID, flags, buildConfig)
+		if !ok {
+			return
+		}
+		entry.Server = server
+		if c.cfg != nil && c.cfg.IsProtected(buildConfig) && !confirmed(ctx) {
+			if oneShot(ctx) {
+				notice(buildConfig+" is protected and its kick has to be confirmed, kick it off from the repl or a chat room", chat.ColorRed)
+				return
+			}
+			token, err := c.confirms.add(command, time.Now())
+			if err != nil {
+				log.Printf("Error creating confirmation token: %v", err)
+				notice("Error kicking off build", chat.ColorRed)
+				return
+			}
+			notice(fmt.Sprintf("%v is protected, confirm with %v confirm %v within %v minutes", buildConfig, cmd[0], token, int(confirmTimeout.Minutes())), chat.ColorYellow)
+			return
+		}
+		if rejection := c.limits.allow(sender, command.RoomID, provider, buildConfig); rejection != "" {
+			log.Printf("Limited kick of %v by %v in room %v", buildConfig, sender, command.RoomID)
+			notice(rejection, chat.ColorYellow)
+			return
+		}
+		req := ci.BuildRequest{
+			ConfigID: buildConfig,
+			Branch:   branch,
+			Revision: revision,
+			Comment:  "Triggered from " + command.Source + " by " + sender.String(),
+			User:     sender.String(),
+			Params:   flags.Params(),
+		}
 
-		if err := c.builder.Build(params); err != nil {
-			postToHipchat(c.cfg.HipchatURL, "<b>Error kicking off build</b>", "red", "html")
+		b, err := provider.Trigger(ctx, req)
+		if err == ci.ErrReadOnly {
+			notice("Bot is read-only, builds can not be kicked off", chat.ColorYellow)
+		} else if err != nil {
+			log.Printf("Error kicking off %v: %v", buildConfig, err)
+			notice("Error kicking off build", chat.ColorRed)
 		} else {
-
-			data := struct {
-				BuildConfigID string
-				Branch        string
-				TaskID        string
-			}{
+			c.limits.kicked(sender, command.RoomID)
+			entry.TaskID = b.ID
+			data := chat.KickReply{
 				BuildConfigID: buildConfig,
-				Branch:        branch,
-				TaskID:        strings.Split(c.builder.BuildResult.HREF, ":")[1],
+				Branch:        b.Branch,
+				Revision:      revision,
+				Server:        server,
+				TaskID:        b.ID,
+				WebURL:        b.WebURL,
 			}
-			message := parseHTMLTemplate("kick", data)
-			postToHipchat(c.cfg.HipchatURL, message, "green", "html")
+			reply(chat.Message{Template: "kick", Data: data, Color: chat.ColorGreen})
+			c.buildChanged(command.RoomID, b.ID, b)
+			// without a Notifier the result can not be posted, but the build
+			// is still followed until it finishes
+			n, _ := r.(chat.Notifier)
+			c.followBuild(command.RoomID, provider, req, b, n)
 		}
 		return
+	case "confirm":
+		if len(cmd) != 3 {
+			help(chat.ColorYellow)
+			return
+		}
+		kick, refusal := c.confirms.confirm(cmd[2], command, time.Now())
+		if refusal != nil {
+			reply(*refusal)
+			return
+		}
+		log.Printf("%v confirmed %q", sender, kick.Text)
+		c.dispatch(context.WithValue(ctx, confirmedKey{}, true), kick, r)
+		return
 	case "status":
 		if len(cmd) != 3 {
-			postToHipchat(c.cfg.HipchatURL, parseHTMLTemplate("help", nil), "yellow", "html")
+			help(chat.ColorYellow)
+			return
+		}
+		_, provider, ok := c.pickProvider(ctx, r, command.RoomID, flags, "")
+		if !ok {
 			return
 		}
 		taskId := cmd[2]
-		b, err := c.builder.GetBuildStatus1(taskId)
-		if err != nil {
-			fmt.Println(err.Error())
+		b, denied := c.buildPermission(ctx, provider, command, action, taskId)
+		if denied != "" {
+			notice(denied, chat.ColorRed)
+			return
 		}
-		color := "yellow"
-		if b.State == "finished" && b.Status == "SUCCESS" {
-			color = "green"
+		var err error
+		if b == nil {
+			b, err = provider.Status(ctx, taskId)
 		}
-		if b.State == "finished" && b.Status == "FAILURE" {
-			color = "red"
+		if err != nil {
+			log.Printf("Error getting status of task %v: %v", taskId, err)
+			notice("Error getting build status", chat.ColorRed)
+			return
 		}
-		message := parseHTMLTemplate("status", b)
-		postToHipchat(c.cfg.HipchatURL, message, color, "html")
+		reply(chat.Message{Template: "status", Data: b, Color: statusColor(b)})
 
+		return
+	case "cancel":
+		if len(cmd) != 3 {
+			help(chat.ColorYellow)
+			return
+		}
+		_, provider, ok := c.pickProvider(ctx, r, command.RoomID, flags, "")
+		if !ok {
+			return
+		}
+		taskId := cmd[2]
+		entry.TaskID = taskId
+		if _, denied := c.buildPermission(ctx, provider, command, action, taskId); denied != "" {
+			notice(denied, chat.ColorRed)
+			return
+		}
+		err := provider.Cancel(ctx, taskId, "Cancelled from "+command.Source+" by "+sender.String())
+		if err == ci.ErrReadOnly {
+			notice("Bot is read-only, builds can not be cancelled", chat.ColorYellow)
+		} else if err != nil {
+			log.Printf("Error cancelling task %v: %v", taskId, err)
+			notice("Error cancelling build", chat.ColorRed)
+		} else {
+			notice("Build "+taskId+" cancelled", chat.ColorGreen)
+		}
+		return
+	case "log":
+		if len(cmd) != 3 {
+			help(chat.ColorYellow)
+			return
+		}
+		_, provider, ok := c.pickProvider(ctx, r, command.RoomID, flags, "")
+		if !ok {
+			return
+		}
+		taskId := cmd[2]
+		if _, denied := c.buildPermission(ctx, provider, command, action, taskId); denied != "" {
+			notice(denied, chat.ColorRed)
+			return
+		}
+		text, err := provider.Log(ctx, taskId)
+		if err != nil {
+			log.Printf("Error getting log of task %v: %v", taskId, err)
+			notice("Error getting build log", chat.ColorRed)
+			return
+		}
+		data := struct {
+			TaskID string
+			Log    string
+		}{
+			TaskID: taskId,
+			Log:    logTail(text, 30),
+		}
+		reply(chat.Message{Template: "log", Data: data, Color: chat.ColorGray})
+		return
+	case "artifacts":
+		if len(cmd) != 3 {
+			help(chat.ColorYellow)
+			return
+		}
+		_, provider, ok := c.pickProvider(ctx, r, command.RoomID, flags, "")
+		if !ok {
+			return
+		}
+		taskId := cmd[2]
+		if _, denied := c.buildPermission(ctx, provider, command, action, taskId); denied != "" {
+			notice(denied, chat.ColorRed)
+			return
+		}
+		artifacts, err := provider.Artifacts(ctx, taskId)
+		if err != nil {
+			log.Printf("Error getting artifacts of task %v: %v", taskId, err)
+			notice("Error getting build artifacts", chat.ColorRed)
+			return
+		}
+		data := struct {
+			TaskID    string
+			Artifacts []ci.Artifact
+		}{
+			TaskID:    taskId,
+			Artifacts: artifacts,
+		}
+		reply(chat.Message{Template: "artifacts", Data: data, Color: chat.ColorGreen})
+		return
+	case "mine":
+		_, provider, ok := c.pickProvider(ctx, r, command.RoomID, flags, "")
+		if !ok {
+			return
+		}
+		ub, ok := provider.(ci.UserBuilds)
+		if !ok {
+			notice("This server can not look up your builds", chat.ColorYellow)
+			return
+		}
+		builds, err := ub.BuildsTriggeredBy(ctx, sender.String(), 10)
+		if err != nil {
+			log.Printf("Error getting builds for %v: %v", sender, err)
+			notice("Error getting your builds", chat.ColorRed)
+			return
+		}
+		data := struct {
+			User   chat.User
+			Builds []*ci.Build
+		}{
+			User:   sender,
+			Builds: builds,
+		}
+		reply(chat.Message{Template: "mine", Data: data, Color: chat.ColorGreen})
+		return
+	case "watch":
+		if len(cmd) != 3 {
+			help(chat.ColorYellow)
+			return
+		}
+		w := store.Watch{
+			RoomID:   command.RoomID,
+			RoomName: command.RoomName,
+			Source:   command.Source,
+			ConfigID: cmd[2],
+			Branch:   flags.Get("branch"),
+			Only:     flags.Get("only"),
+		}
+		switch w.Only {
+		case "":
+			w.Only = store.OnlyAll
+		case store.OnlyAll, store.OnlyFailures, store.OnlyChanges:
+		default:
+			help(chat.ColorYellow)
+			return
+		}
+		if !settings.Visible(w.ConfigID) {
+			notice(w.ConfigID+" can not be watched from this room", chat.ColorYellow)
+			return
+		}
+		server, provider, err := c.providerFor(command.RoomID, flags, w.ConfigID)
+		if err != nil {
+			notice(err.Error(), chat.ColorRed)
+			return
+		}
+		w.Server = server
+		history, ok := provider.(ci.History)
+		if !ok {
+			notice("This server can not watch builds", chat.ColorYellow)
+			return
+		}
+		if c.notifier(command.Source, command.RoomID, command.RoomName) == nil {
+			notice("Build results can not be posted to this room", chat.ColorYellow)
+			return
+		}
+		builds, err := history.FinishedBuilds(ctx, w.ConfigID, w.Branch, watchHistory)
+		if err != nil {
+			log.Printf("Error getting builds of %v: %v", w.ConfigID, err)
+			notice("Error getting builds of "+w.ConfigID, chat.ColorRed)
+			return
+		}
+		c.subs.prime(w, builds)
+		if err := c.store.AddWatch(w); err != nil {
+			log.Printf("Error saving watch of %v: %v", w.ConfigID, err)
+			notice("Error saving the watch", chat.ColorRed)
+			return
+		}
+		if len(builds) > 0 {
+			c.buildChanged(command.RoomID, builds[0].ID, builds[0])
+		}
+		reply(chat.Message{Template: "watching", Data: c.store.RoomWatches(command.RoomID), Color: chat.ColorGreen})
+		return
+	case "unwatch":
+		if len(cmd) != 3 {
+			help(chat.ColorYellow)
+			return
+		}
+		w := store.Watch{RoomID: command.RoomID, ConfigID: cmd[2], Branch: flags.Get("branch")}
+		server, _, err := c.providerFor(command.RoomID, flags, w.ConfigID)
+		if err != nil {
+			notice(err.Error(), chat.ColorRed)
+			return
+		}
+		w.Server = server
+		removed, err := c.store.RemoveWatch(w.RoomID, w.Server, w.ConfigID, w.Branch)
+		if err != nil {
+			log.Printf("Error removing watch of %v: %v", w.ConfigID, err)
+			notice("Error removing the watch", chat.ColorRed)
+		} else if !removed {
+			notice(w.ConfigID+" is not watched in this room", chat.ColorYellow)
+		} else {
+			c.subs.forget(w)
+			watched := false
+			for _, rw := range c.store.RoomWatches(w.RoomID) {
+				watched = watched || rw.ConfigID == w.ConfigID
+			}
+			if !watched && c.health != nil {
+				c.health.forget(w.RoomID, w.ConfigID)
+			}
+			notice("No longer watching "+w.ConfigID, chat.ColorGreen)
+		}
+		return
+	case "watching":
+		reply(chat.Message{Template: "watching", Data: c.store.RoomWatches(command.RoomID), Color: chat.ColorGreen})
+		return
+	case "audit":
+		if len(cmd) != 2 {
+			help(chat.ColorYellow)
+			return
+		}
+		// the audit log is for admin roles only
+		if c.cfg == nil || !c.cfg.Permissions.IsAdmin(c.cfg.Permissions.RoleOf(command.Source, sender.ID, sender.MentionName)) {
+			log.Printf("Denied audit to %v", sender)
+			notice("Sorry "+sender.Name+", the audit log is only shown to admins", chat.ColorRed)
+			return
+		}
+		if !c.audit.Enabled() {
+			notice("The audit log is not kept, set its file in config.yaml", chat.ColorYellow)
+			return
+		}
+		entries, err := c.auditEntries(flags, time.Now())
+		if err != nil {
+			notice(err.Error(), chat.ColorYellow)
+			return
+		}
+		reply(chat.Message{Template: "audit", Data: entries, Color: chat.ColorGray})
+		return
+	case "schedule":
+		if len(cmd) == 3 && cmd[2] == "list" {
+			reply(chat.Message{Template: "schedules", Data: c.scheduleEntries(command.RoomID, time.Now()), Color: chat.ColorGreen})
+			return
+		}
+		if len(cmd) == 4 && cmd[2] == "remove" {
+			removed, err := c.store.RemoveSchedule(command.RoomID, cmd[3])
+			if err != nil {
+				log.Printf("Error removing schedule %v: %v", cmd[3], err)
+				notice("Error removing the scheduled build", chat.ColorRed)
+			} else if !removed {
+				notice("There is no scheduled build "+cmd[3]+" in this room", chat.ColorYellow)
+			} else {
+				notice("Scheduled build "+cmd[3]+" removed", chat.ColorGreen)
+			}
+			return
+		}
+		if len(cmd) != 5 {
+			help(chat.ColorYellow)
+			return
+		}
+		sc := store.Schedule{
+			RoomID:      command.RoomID,
+			RoomName:    command.RoomName,
+			Source:      command.Source,
+			ConfigID:    cmd[2],
+			Branch:      cmd[3],
+			Cron:        cmd[4],
+			User:        sender.String(),
+			UserID:      sender.ID,
+			MentionName: sender.MentionName,
+		}
+		schedule, err := cron.Parse(sc.Cron)
+		if err != nil {
+			notice("Bad schedule: "+err.Error(), chat.ColorYellow)
+			return
+		}
+		if !settings.Visible(sc.ConfigID) {
+			notice(sc.ConfigID+" can not be kicked off from this room", chat.ColorYellow)
+			return
+		}
+		server, _, err := c.providerFor(command.RoomID, flags, sc.ConfigID)
+		if err != nil {
+			notice(err.Error(), chat.ColorRed)
+			return
+		}
+		sc.Server = server
+		if c.notifier(command.Source, command.RoomID, command.RoomName) == nil {
+			notice("Scheduled builds can not be posted to this room", chat.ColorYellow)
+			return
+		}
+		if c.cfg != nil && c.cfg.IsProtected(sc.ConfigID) && !confirmed(ctx) {
+			token, err := c.confirms.add(command, time.Now())
+			if err != nil {
+				log.Printf("Error creating confirmation token: %v", err)
+				notice("Error saving the scheduled build", chat.ColorRed)
+				return
+			}
+			notice(fmt.Sprintf("%v is protected, confirm scheduling it with %v confirm %v within %v minutes", sc.ConfigID, cmd[0], token, int(confirmTimeout.Minutes())), chat.ColorYellow)
+			return
+		}
+		sc, err = c.store.AddSchedule(sc)
+		if err != nil {
+			log.Printf("Error saving schedule of %v: %v", sc.ConfigID, err)
+			notice("Error saving the scheduled build", chat.ColorRed)
+			return
+		}
+		next := "never"
+		if t := schedule.Next(time.Now()); !t.IsZero() {
+			next = t.Format(nextRunFormat)
+		}
+		notice("Scheduled build "+sc.ID+" of "+sc.ConfigID+" ("+sc.Branch+") added, next run "+next, chat.ColorGreen)
 		return
 	case "--help":
-		postToHipchat(c.cfg.HipchatURL, parseHTMLTemplate("help", nil), "green", "html")
+		help(chat.ColorGreen)
 		return
 	default:
-		postToHipchat(c.cfg.HipchatURL, parseHTMLTemplate("help", nil), "yellow", "html")
+		help(chat.ColorYellow)
+	}
+}
+
+// commandFlags holds the "--name value" options of a command. An option may
+// be given more than once.
+type commandFlags map[string][]string
+
+// Get returns the first value of the option, or "" when it was not given
+func (f commandFlags) Get(name string) string {
+	if v := f[name]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// Params returns the "--param key=value" options as a map
+func (f commandFlags) Params() map[string]string {
+	params := make(map[string]string)
+	for _, kv := range f["param"] {
+		if i := strings.Index(kv, "="); i > 0 {
+			params[kv[:i]] = kv[i+1:]
+		}
+	}
+	return params
+}
+
+// splitCommand splits a command into words. Text in double quotes, straight
+// or the curly ones chat clients type, is one word.
+func splitCommand(text string) []string {
+	var words []string
+	var word strings.Builder
+	quoted, inWord := false, false
+	for _, r := range text {
+		switch {
+		case r == '"' || r == '\u201c' || r == '\u201d':
+			quoted = !quoted
+			inWord = true
+		case !quoted && unicode.IsSpace(r):
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
 	}
+	return words
+}
+
+// splitFlags separates "--name value" options from the positional words of a
+// command. Options are only looked for after the action so "/build --help"
+// keeps working.
+func splitFlags(cmd []string) ([]string, commandFlags) {
+	flags := make(commandFlags)
+	if len(cmd) < 3 {
+		return cmd, flags
+	}
+	args := cmd[:2:2]
+	for i := 2; i < len(cmd); i++ {
+		if !strings.HasPrefix(cmd[i], "--") {
+			args = append(args, cmd[i])
+			continue
+		}
+		name := strings.TrimPrefix(cmd[i], "--")
+		if i+1 < len(cmd) && !strings.HasPrefix(cmd[i+1], "--") {
+			flags[name] = append(flags[name], cmd[i+1])
+			i++
+		} else {
+			flags[name] = append(flags[name], "")
+		}
+	}
+	return args, flags
 }
 
 // routes all URL routes for app add-on
@@ -211,93 +946,214 @@ func (c *Context) routes() *mux.Router {
 	r := mux.NewRouter()
 	//healthcheck route required by Micros
 	r.Path("/healthcheck").Methods("GET").HandlerFunc(c.healthcheck)
+	r.Path("/metrics").Methods("GET").HandlerFunc(c.metrics)
 	//descriptor for Atlassian Connect
 	r.Path("/").Methods("GET").HandlerFunc(c.atlassianConnect)
 	r.Path("/atlassian-connect.json").Methods("GET").HandlerFunc(c.atlassianConnect)
 
 	// HipChat specific API routes
 	r.Path("/installable").Methods("POST").HandlerFunc(c.installable)
-	r.Path("/config").Methods("GET").HandlerFunc(c.config)
+	r.Path("/config").Methods("GET", "POST").HandlerFunc(c.config)
 	r.Path("/hook").Methods("POST").HandlerFunc(c.hook)
+	r.Path("/glance").Methods("GET").HandlerFunc(c.glance)
+	r.Path("/sidebar").Methods("GET").HandlerFunc(c.sidebar)
+	r.Path("/sidebar/builds").Methods("GET").HandlerFunc(c.sidebarBuilds)
+	r.Path("/teamcity/webhook").Methods("POST").HandlerFunc(c.teamcityWebhook)
+	c.addNotifier("HipChat", func(roomID string, roomName string) chat.Notifier {
+		return hipchatReplier{url: c.cfg.HipchatURL, format: "html", baseURL: c.baseURL}
+	})
+
+	// Slack app routes
+	if c.cfg != nil && c.cfg.Slack.SigningSecret != "" {
+		s := slack.New(c.cfg.Slack, c.dispatch, slackText)
+		r.Path("/slack/command").Methods("POST").HandlerFunc(s.Command)
+		r.Path("/slack/events").Methods("POST").HandlerFunc(s.Events)
+		c.addNotifier("Slack", s.Notifier)
+	}
+
+	// Mattermost slash command route
+	if c.cfg != nil && c.cfg.Mattermost.Token != "" {
+		m := mattermost.New(c.cfg.Mattermost, c.dispatch, renderer(FormatMarkdown))
+		r.Path("/mattermost/command").Methods("POST").HandlerFunc(m.Command)
+		c.addNotifier("Mattermost", m.Notifier)
+	}
+
+	// Teams outgoing webhook route
+	if c.cfg != nil && c.cfg.Teams.Secret != "" {
+		t, err := teams.New(c.cfg.Teams, c.dispatch, renderer(FormatMarkdown))
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		r.Path("/teams/webhook").Methods("POST").HandlerFunc(t.Webhook)
+		c.addNotifier("Teams", t.Notifier)
+	}
 
 	r.PathPrefix("/").Handler(http.FileServer(http.Dir(c.static)))
 	return r
 }
 
-func watchForFinishedBuild(b *teamcity.Builder, hipchatURL string) error {
+// How often and for how long a kicked off build is watched for its result
+var (
+	watchInterval = 10 * time.Second
+	watchTimeout  = 12 * time.Hour
+)
+
+// watchForFinishedBuild follows the build until it finishes and sends its
+// result through n, when there is one. The build comes from the server's webhook events, or is
+// polled every interval. changed is called with the build on every update.
+func watchForFinishedBuild(ctx context.Context, p ci.Provider, id string, events <-chan *ci.Build, interval time.Duration, n chat.Notifier, changed func(*ci.Build)) error {
 	for {
-		time.Sleep(time.Second * 2)
-		fmt.Println("In WatchForFinishedBuild..2 sec delay")
-		br := b.BuildResult
-		fmt.Printf("Build Result Id: %v State: %v", br.BuildTypeID, br.State)
-		err := b.GetBuildStatus(br)
-		fmt.Printf("Build Result Id: %v State: %v", br.BuildTypeID, br.State)
-		if err != nil {
-			return err
-		}
-		if br.State == "finished" {
-			color := "red"
-			if br.Status == "SUCCESS" {
-				color = "green"
+		var br *ci.Build
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case br = <-events:
+		case <-time.After(interval):
+			var err error
+			br, err = p.Status(ctx, id)
+			if err != nil {
+				return err
 			}
-			message := "Build complete for " + br.BuildTypeID + " State: " + br.State + " Status: " + br.Status
-			postToHipchat(hipchatURL, message, color, "text")
+		}
+		changed(br)
+		if br.Finished() && n == nil {
 			return nil
+		} else if br.Finished() {
+			color := chat.ColorRed
+			if br.Status == ci.StatusSuccess {
+				color = chat.ColorGreen
+			}
+			return n.Notify(ctx, chat.Message{Template: "finished", Data: br, Color: color})
 		}
 	}
 }
 
-func parseHTMLTemplate(templateName string, data interface{}) string {
-	var buffer bytes.Buffer
-	t := template.New(templateName + ".html")
-	var err error
-	tb, err := Asset("templates/" + templateName + ".html")
-	s := string(tb)
-	t, err = t.Parse(s)
-	if err != nil {
-		fmt.Println(err.Error())
+// followBuild follows a build kicked off from the room by req until it
+// finishes, keeping the room's health up to date and the build counted by the
+// limits meanwhile. Its result is posted through n, unless n is nil.
+func (c *Context) followBuild(roomID string, provider ci.Provider, req ci.BuildRequest, b *ci.Build, n chat.Notifier) {
+	if n != nil {
+		n = roomNotifier{Notifier: n, settings: c.roomSettings(roomID)}
+		c.followWatched(roomID, provider, req, b.ID)
 	}
-	err = t.Execute(&buffer, data)
-	if err != nil {
-		fmt.Println(err.Error())
+	c.limits.started(provider, req.ConfigID, b.ID)
+	events, unsubscribe := c.events.subscribe(provider, b.ID)
+	interval := watchInterval
+	if c.webhooked(provider) {
+		interval = webhookFallback
+	}
+	go func() {
+		defer unsubscribe()
+		defer c.limits.finished(provider, b.ID)
+		ctx, cancel := context.WithTimeout(context.Background(), watchTimeout)
+		defer cancel()
+		// the build may get a new ID once it starts, it stays recorded as b.ID
+		id := b.ID
+		changed := func(b *ci.Build) { c.buildChanged(roomID, id, b) }
+		if err := watchForFinishedBuild(ctx, provider, b.ID, events, interval, n, changed); err != nil {
+			log.Printf("Error watching build %v: %v", b.ID, err)
+		}
+	}()
+}
+
+// roomNotifier holds back the build results the room does not want posted
+type roomNotifier struct {
+	chat.Notifier
+	settings config.RoomSettings
+}
+
+func (n roomNotifier) Notify(ctx context.Context, m chat.Message) error {
+	if b, ok := m.Data.(*ci.Build); ok && !n.settings.Notifies(b.Status == ci.StatusSuccess) {
+		return nil
 	}
-	return buffer.String()
+	return n.Notifier.Notify(ctx, m)
 }
 
 func postToHipchat(hipchatURL string, message string, color string, format string) error {
 	m := HipChatBasicMessage{Color: color, Notify: false, MessageFormat: format, Message: message}
+	return postNotification(hipchatURL, m)
+}
+
+// postNotification posts a room notification, a HipChatBasicMessage or a
+// hipchat.NotificationRequest with a card
+func postNotification(hipchatURL string, m interface{}) error {
 	b := new(bytes.Buffer)
 	json.NewEncoder(b).Encode(m)
 	resp, err := http.Post(hipchatURL, "application/json", b)
 	if err != nil {
 		return err
 	}
+	resp.Body.Close()
 	if resp.StatusCode >= 400 {
 		return errors.New("Non 200 response status")
 	}
 	return err
 }
 
+// templateReload is how often templates_dir is checked for changes
+const templateReload = 2 * time.Second
+
 // BASE_URL=https://6011fb9f.ngrok.io ./hipchatBot
+//
+// hipchatBot repl and hipchatBot exec "/build status 123" run commands from
+// the terminal instead of serving the chat platforms.
 func main() {
 	var (
 		static = flag.String("static", "./static/", "static folder")
+		room   = flag.String("room", "", "room whose settings repl and exec use")
 	)
 	flag.Parse()
 
 	config := config.NewConfig("config.yaml")
+	if err := config.Permissions.Validate(); err != nil {
+		log.Fatalf("Error in permissions: %v", err)
+	}
+
+	if config.TemplatesDir != "" {
+		ts, err := newTemplateStore(config.TemplatesDir)
+		if err != nil {
+			log.Fatalf("Error loading templates from %v: %v", config.TemplatesDir, err)
+		}
+		templates = ts
+		go ts.watch(context.Background(), templateReload)
+	}
+
+	st, err := store.Open(config.StateFile)
+	if err != nil {
+		log.Fatalf("Error loading %v: %v", config.StateFile, err)
+	}
+	auditLog, err := openAuditLog(config.Audit)
+	if err != nil {
+		log.Fatalf("Error opening audit log %v: %v", config.Audit.File, err)
+	}
 
 	c := &Context{
-		baseURL: config.NgrokURL,
-		static:  *static,
-		rooms:   make(map[string]*RoomConfig),
-		builder: teamcity.New(config.Teamcity),
-		cfg:     config,
+		baseURL:   config.NgrokURL,
+		static:    *static,
+		rooms:     make(map[string]*RoomConfig),
+		providers: newProviders(config),
+		cfg:       config,
+		health:    newRoomHealth(),
+		store:     st,
+		subs:      newSubscriptions(),
+		events:    newBuildEvents(),
+		confirms:  newConfirmations(),
+		limits:    newLimiter(config.Limits),
+		audit:     auditLog,
+	}
+
+	c.restoreInstalls()
+
+	if flag.NArg() > 0 {
+		os.Exit(c.runLocal(flag.Args(), *room))
 	}
 
 	log.Printf("Base HipChat integration v0.10 - running on port:%v", config.Port)
 
 	r := c.routes()
+	go c.connectInstalls()
+	go c.pollWatches(context.Background())
+	go c.runSchedules(context.Background())
 	http.Handle("/", r)
 	http.ListenAndServe(":"+strconv.Itoa(config.Port), nil)
 }
@@ -1,15 +1,128 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
 
+	"github.com/mkobaly/hipchatBot/chat"
+	"github.com/mkobaly/hipchatBot/ci"
 	"github.com/mkobaly/hipchatBot/config"
 	"github.com/mkobaly/hipchatBot/teamcity"
+	"github.com/tbruyelle/hipchat-go/hipchat"
 )
 
+func TestKickAtRevision(t *testing.T) {
+	var body map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/httpAuth/app/rest/buildQueue" {
+			t.Errorf("unexpected call %v %v", r.Method, r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"id":42,"buildTypeId":"Web_CI","state":"queued","branchName":"main"}`))
+	}))
+	defer ts.Close()
+
+	c, _ := settingsContext(t)
+	c.providers["default"] = teamcity.New(config.UserCredential{URL: ts.URL + "/", Username: "bot", Password: "secret"})
+	var r replies
+	c.dispatch(context.Background(), chat.Command{Text: "/build kick Web_CI main --revision 1a2b3c", User: chat.User{Name: "Ada", MentionName: "ada"}, RoomID: "1", Source: "test"}, &r)
+
+	changes, _ := body["lastChanges"].(map[string]interface{})
+	change, _ := changes["change"].([]interface{})
+	if len(change) != 1 || change[0].(map[string]interface{})["locator"] != "version:1a2b3c,buildType:(id:Web_CI)" {
+		t.Errorf("queued build not pinned to the revision: %v", body)
+	}
+	if len(r) != 1 || r[0].Template != "kick" {
+		t.Fatalf("unexpected kick reply %+v", r)
+	}
+	if text := renderTemplate(FormatText, r[0].Template, r[0].Data); !strings.Contains(text, "Revision: 1a2b3c") {
+		t.Errorf("reply does not mention the revision: %q", text)
+	}
+}
+
+func TestMessageSender(t *testing.T) {
+	for _, tc := range []struct {
+		from interface{}
+		want chat.User
+	}{
+		{map[string]interface{}{"id": float64(4513556), "name": "Michael Kobaly", "mention_name": "MichaelKobaly"},
+			chat.User{ID: "4513556", Name: "Michael Kobaly", MentionName: "MichaelKobaly"}},
+		{map[string]interface{}{"name": "O'Brien, Pat", "mention_name": "pat"}, chat.User{Name: "O'Brien, Pat", MentionName: "pat"}},
+		{"JIRA", chat.User{Name: "JIRA", MentionName: "JIRA"}},
+		{nil, chat.User{}},
+	} {
+		if got := messageSender(&hipchat.Message{From: tc.from}); got != tc.want {
+			t.Errorf("messageSender(%v) = %+v, want %+v", tc.from, got, tc.want)
+		}
+	}
+}
+
+func TestKickRecordsUser(t *testing.T) {
+	for _, tc := range []struct {
+		source  string
+		user    chat.User
+		comment string
+		param   string
+	}{
+		{"HipChat", chat.User{ID: "1", Name: "Ada Lovelace", MentionName: "ada"}, "Triggered from HipChat by Ada Lovelace (@ada)", "Ada Lovelace (@ada)"},
+		{"Slack", chat.User{ID: "U2", Name: "O'Brien, Pat", MentionName: "pat"}, "Triggered from Slack by O'Brien, Pat (@pat)", "O'Brien, Pat (@pat)"},
+	} {
+		c, p := settingsContext(t)
+		var r replies
+		c.dispatch(context.Background(), chat.Command{Text: "/build kick Web_CI main", User: tc.user, RoomID: "1", Source: tc.source}, &r)
+		if len(p.kicked) != 1 || p.kicked[0].Comment != tc.comment || p.kicked[0].User != tc.param {
+			t.Errorf("unexpected build %+v", p.kicked)
+		}
+	}
+}
+
+// mineProvider finds the builds of one chat user
+type mineProvider struct {
+	kickProvider
+	asked []string
+}
+
+func (p *mineProvider) BuildsTriggeredBy(ctx context.Context, user string, count int) ([]*ci.Build, error) {
+	p.asked = append(p.asked, user)
+	return []*ci.Build{{ID: "12", ConfigID: "Web_CI", State: ci.StateFinished, Status: ci.StatusSuccess}}, nil
+}
+
+func TestMine(t *testing.T) {
+	c, _ := settingsContext(t)
+	run := func(user chat.User) replies {
+		var r replies
+		c.dispatch(context.Background(), chat.Command{Text: "/build mine", User: user, RoomID: "1", Source: "test"}, &r)
+		return r
+	}
+	if r := run(chat.User{Name: "Ada Lovelace", MentionName: "ada"}); len(r) != 1 || r[0].Color != chat.ColorYellow {
+		t.Errorf("mine without user builds: %+v", r)
+	}
+
+	p := &mineProvider{}
+	c.providers["default"] = p
+	for _, tc := range []struct {
+		user chat.User
+		want string
+	}{
+		{chat.User{ID: "1", Name: "Ada Lovelace", MentionName: "ada"}, "Ada Lovelace (@ada)"},
+		{chat.User{Name: "O'Brien, Pat", MentionName: "pat"}, "O'Brien, Pat (@pat)"},
+	} {
+		r := run(tc.user)
+		if len(r) != 1 || r[0].Template != "mine" {
+			t.Fatalf("unexpected mine reply %+v", r)
+		}
+		if p.asked[len(p.asked)-1] != tc.want {
+			t.Errorf("asked for the builds of %q, want %q", p.asked[len(p.asked)-1], tc.want)
+		}
+	}
+}
+
 func TestHook(t *testing.T) {
 	// Create a request to pass to our handler. We don't have any query parameters for now, so we'll
 	// pass 'nil' as the third parameter.
@@ -61,9 +174,9 @@ func TestHook(t *testing.T) {
 	config := config.NewConfig("config.yaml")
 
 	c := &Context{
-		rooms:   make(map[string]*RoomConfig),
-		builder: teamcity.New(config.Teamcity),
-		cfg:     config,
+		rooms:     make(map[string]*RoomConfig),
+		providers: newProviders(config),
+		cfg:       config,
 	}
 
 	// We create a ResponseRecorder (which satisfies http.ResponseWriter) to record the response.
@@ -114,3 +227,102 @@ func TestHealthCheck(t *testing.T) {
 	// 		rec.Body.String(), expected)
 	// }
 }
+
+func TestSplitFlags(t *testing.T) {
+	for _, tc := range []struct {
+		in    string
+		args  string
+		flags commandFlags
+	}{
+		{"/build list", "/build list", commandFlags{}},
+		{"/build list --server cloud", "/build list", commandFlags{"server": {"cloud"}}},
+		{"/build kick Web_CI --server cloud main --param a=1 --param b=2", "/build kick Web_CI main",
+			commandFlags{"server": {"cloud"}, "param": {"a=1", "b=2"}}},
+		{"/build status 12 --server", "/build status 12", commandFlags{"server": {""}}},
+	} {
+		args, flags := splitFlags(strings.Fields(tc.in))
+		if strings.Join(args, " ") != tc.args || !reflect.DeepEqual(flags, tc.flags) {
+			t.Errorf("splitFlags(%q) = %q %v, want %q %v", tc.in, args, flags, tc.args, tc.flags)
+		}
+	}
+}
+
+// listErrProvider is a server whose build configurations can not be listed
+type listErrProvider struct {
+	ci.Provider
+}
+
+func (listErrProvider) List(ctx context.Context) ([]string, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestListServers(t *testing.T) {
+	c, p := settingsContext(t)
+	c.providers = map[string]ci.Provider{"cloud": p, "onprem": listErrProvider{}}
+	run := func(text string) replies {
+		var r replies
+		c.dispatch(context.Background(), chat.Command{Text: text, RoomID: "1", Source: "test"}, &r)
+		return r
+	}
+
+	r := run("/build list")
+	if len(r) != 1 || r[0].Color != chat.ColorYellow {
+		t.Fatalf("unexpected reply %+v", r)
+	}
+	want := []chat.ServerConfigs{{Server: "cloud", IDs: []string{"Web_CI", "Web_RC", "Api_CI"}}, {Server: "onprem", Error: true}}
+	if lists := r[0].Data.([]chat.ServerConfigs); !reflect.DeepEqual(lists, want) {
+		t.Errorf("got lists %+v, want %+v", lists, want)
+	}
+
+	r = run("/build list --server cloud")
+	if len(r) != 1 || r[0].Color != chat.ColorGreen || len(r[0].Data.([]chat.ServerConfigs)) != 1 || r[0].Data.([]chat.ServerConfigs)[0].Server != "" {
+		t.Errorf("unexpected reply for one server %+v", r)
+	}
+	if r := run("/build list --server onprem"); len(r) != 1 || r[0].Color != chat.ColorRed {
+		t.Errorf("unexpected reply for a failing server %+v", r)
+	}
+	if r := run("/build list --server nope"); len(r) != 1 || r[0].Text != "Unknown CI server nope" {
+		t.Errorf("unexpected reply for an unknown server %+v", r)
+	}
+}
+
+func TestPickServer(t *testing.T) {
+	c, _ := settingsContext(t)
+	cloud, onprem, legacy := &kickProvider{}, &kickProvider{}, &kickProvider{}
+	c.providers = map[string]ci.Provider{"cloud": cloud, "onprem": onprem, "legacy": legacy}
+	c.cfg = &config.Config{
+		DefaultServer: "legacy",
+		Rooms:         map[string]config.RoomSettings{"2": {Server: "onprem"}},
+	}
+	run := func(text string, roomID string) replies {
+		var r replies
+		c.dispatch(context.Background(), chat.Command{Text: text, RoomID: roomID, Source: "test"}, &r)
+		return r
+	}
+	kicked := func() []int { return []int{len(cloud.kicked), len(onprem.kicked), len(legacy.kicked)} }
+
+	// the default server, then the room's server from config.yaml
+	run("/build kick Web_CI main", "1")
+	run("/build kick Web_CI main", "2")
+	if got := kicked(); !reflect.DeepEqual(got, []int{0, 1, 1}) {
+		t.Errorf("unexpected builds per server %v", got)
+	}
+
+	// the room's settings take over from config.yaml and --server from both
+	c.store.SetRoom("2", config.RoomSettings{Server: "cloud"})
+	r := run("/build kick Web_CI main", "2")
+	run("/build kick Web_CI main --server onprem", "2")
+	if got := kicked(); !reflect.DeepEqual(got, []int{1, 2, 1}) {
+		t.Errorf("unexpected builds per server %v", got)
+	}
+	if len(r) != 1 || r[0].Data.(chat.KickReply).Server != "cloud" {
+		t.Errorf("kick reply does not name the server: %+v", r)
+	}
+
+	if r := run("/build kick Web_CI main --server nope", "1"); len(r) != 1 || r[0].Color != chat.ColorRed || r[0].Text != `unknown CI server "nope"` {
+		t.Errorf("unexpected reply for an unknown server %+v", r)
+	}
+	if got := kicked(); !reflect.DeepEqual(got, []int{1, 2, 1}) {
+		t.Errorf("unknown server kicked off a build: %v", got)
+	}
+}
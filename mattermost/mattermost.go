@@ -0,0 +1,224 @@
+// Package mattermost connects the bot to Mattermost through a custom slash
+// command. Replies are sent to the command's response_url and messages sent
+// later, like the result of a build, go through an incoming webhook.
+package mattermost
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mkobaly/hipchatBot/chat"
+	"github.com/mkobaly/hipchatBot/config"
+)
+
+const defaultTimeout = 30 * time.Second
+
+// ErrToken is returned for commands that do not carry the slash command's
+// token
+var ErrToken = errors.New("mattermost: invalid slash command token")
+
+// colors are the attachment colors for the message colors
+var colors = map[string]string{
+	chat.ColorGreen:  "#2eb886",
+	chat.ColorYellow: "#daa038",
+	chat.ColorRed:    "#a30200",
+	chat.ColorGray:   "#cccccc",
+}
+
+// attachment is a Mattermost message attachment. It gives the reply the
+// color bar HipChat messages have.
+type attachment struct {
+	Fallback string `json:"fallback"`
+	Color    string `json:"color,omitempty"`
+	Text     string `json:"text"`
+}
+
+// response is both the slash command response and an incoming webhook post
+type response struct {
+	ResponseType string       `json:"response_type,omitempty"`
+	Channel      string       `json:"channel,omitempty"`
+	Username     string       `json:"username,omitempty"`
+	Attachments  []attachment `json:"attachments,omitempty"`
+}
+
+// Handler serves a Mattermost slash command
+type Handler struct {
+	token      string
+	webhookURL string
+	username   string
+	dispatch   chat.Dispatcher
+	markdown   func(chat.Message) string
+	http       *http.Client
+}
+
+// New creates the Mattermost handler. Commands are passed to dispatch and
+// replies with a template are shown as the Markdown returned by markdown.
+func New(settings config.MattermostSettings, dispatch chat.Dispatcher, markdown func(chat.Message) string) *Handler {
+	return &Handler{
+		token:      settings.Token,
+		webhookURL: settings.WebhookURL,
+		username:   settings.Username,
+		dispatch:   dispatch,
+		markdown:   markdown,
+		http:       &http.Client{Timeout: defaultTimeout},
+	}
+}
+
+// verify checks the slash command's token, which Mattermost sends in the
+// form and, since 5.x, in the Authorization header
+func (h *Handler) verify(r *http.Request) error {
+	token := r.PostForm.Get("token")
+	if token == "" {
+		token = strings.TrimPrefix(r.Header.Get("Authorization"), "Token ")
+	}
+	if h.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+		return ErrToken
+	}
+	return nil
+}
+
+// Command handles the slash command POST. The command is acknowledged
+// straight away and runs on its own, its replies going to the command's
+// response_url. Older servers that send no response_url wait for the command
+// and get its replies in the response.
+func (h *Handler) Command(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if err := h.verify(r); err != nil {
+		log.Printf("Mattermost command rejected: %v", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	cmd := chat.Command{
+		Text: strings.TrimSpace(r.PostForm.Get("command") + " " + r.PostForm.Get("text")),
+		User: chat.User{
+			ID:          r.PostForm.Get("user_id"),
+			Name:        r.PostForm.Get("user_name"),
+			MentionName: r.PostForm.Get("user_name"),
+		},
+		RoomID:   r.PostForm.Get("channel_id"),
+		RoomName: r.PostForm.Get("channel_name"),
+		Source:   "Mattermost",
+	}
+	rep := &replier{h: h, responseURL: r.PostForm.Get("response_url")}
+	var cr chat.Replier = rep
+	if h.webhookURL != "" {
+		cr = &notifier{replier: rep, channel: cmd.RoomName}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if rep.responseURL == "" {
+		h.dispatch(r.Context(), cmd, cr)
+		json.NewEncoder(w).Encode(rep.response())
+		return
+	}
+	go h.dispatch(context.Background(), cmd, cr)
+	json.NewEncoder(w).Encode(&response{ResponseType: "ephemeral"})
+}
+
+// attachment renders m
+func (h *Handler) attachment(m chat.Message) attachment {
+	text := "**" + m.Text + "**"
+	if m.Template != "" {
+		text = h.markdown(m)
+	}
+	return attachment{Fallback: text, Color: colors[m.Color], Text: text}
+}
+
+// replier sends the replies to a slash command to its response_url, or
+// collects them for its response when there is none
+type replier struct {
+	h           *Handler
+	responseURL string
+	mu          sync.Mutex
+	replies     []attachment
+	ephemeral   bool
+}
+
+func (r *replier) Reply(ctx context.Context, m chat.Message) error {
+	// help and failures are only shown to the user that ran the command
+	private := m.Template == "help" || (m.Template == "" && m.Color != chat.ColorGreen)
+	if r.responseURL != "" {
+		resp := &response{ResponseType: "in_channel", Attachments: []attachment{r.h.attachment(m)}}
+		if private {
+			resp.ResponseType = "ephemeral"
+		}
+		return r.h.post(ctx, r.responseURL, resp)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.replies) == 0 {
+		r.ephemeral = private
+	} else if !private {
+		r.ephemeral = false
+	}
+	r.replies = append(r.replies, r.h.attachment(m))
+	return nil
+}
+
+func (r *replier) response() *response {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	resp := &response{ResponseType: "in_channel", Attachments: r.replies}
+	if r.ephemeral {
+		resp.ResponseType = "ephemeral"
+	}
+	return resp
+}
+
+// Notifier posts to the channel through the incoming webhook, outside of any
+// command. It is nil when no webhook is set up.
+func (h *Handler) Notifier(channelID string, channelName string) chat.Notifier {
+	if h.webhookURL == "" {
+		return nil
+	}
+	return &notifier{replier: &replier{h: h}, channel: channelName}
+}
+
+// notifier is the replier of a command when an incoming webhook is set up.
+// Notify posts to the channel the command came from.
+type notifier struct {
+	*replier
+	channel string
+}
+
+func (n *notifier) Notify(ctx context.Context, m chat.Message) error {
+	h := n.h
+	msg := &response{Channel: n.channel, Username: h.username, Attachments: []attachment{h.attachment(m)}}
+	return h.post(ctx, h.webhookURL, msg)
+}
+
+// post sends msg to the incoming webhook or a command's response_url
+func (h *Handler) post(ctx context.Context, url string, msg *response) error {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := h.http.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		var body bytes.Buffer
+		body.ReadFrom(resp.Body)
+		return fmt.Errorf("mattermost: POST %s: %d %s", url, resp.StatusCode, strings.TrimSpace(body.String()))
+	}
+	return nil
+}
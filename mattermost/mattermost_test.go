@@ -0,0 +1,143 @@
+package mattermost
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mkobaly/hipchatBot/chat"
+	"github.com/mkobaly/hipchatBot/config"
+)
+
+func command(text string, token string) *http.Request {
+	return commandTo(text, token, "")
+}
+
+// commandTo is a slash command whose delayed replies go to responseURL
+func commandTo(text string, token string, responseURL string) *http.Request {
+	form := url.Values{
+		"response_url": {responseURL},
+		"token":        {token},
+		"command":      {"/build"},
+		"text":         {text},
+		"user_id":      {"u42"},
+		"user_name":    {"ada"},
+		"channel_id":   {"c7"},
+		"channel_name": {"deploys"},
+	}
+	r := httptest.NewRequest("POST", "/mattermost/command", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r
+}
+
+func markdown(m chat.Message) string {
+	return "rendered " + m.Template
+}
+
+func TestCommand(t *testing.T) {
+	var got chat.Command
+	dispatch := func(ctx context.Context, cmd chat.Command, r chat.Replier) {
+		got = cmd
+		if strings.HasSuffix(cmd.Text, "--help") {
+			r.Reply(ctx, chat.Message{Template: "help", Color: chat.ColorGreen})
+			return
+		}
+		r.Reply(ctx, chat.Message{Template: "kick", Color: chat.ColorGreen})
+	}
+	h := New(config.MattermostSettings{Token: "s3cret"}, dispatch, markdown)
+
+	w := httptest.NewRecorder()
+	h.Command(w, command("kick Web_CI main", "s3cret"))
+	var resp response
+	json.NewDecoder(w.Body).Decode(&resp)
+	if got.Text != "/build kick Web_CI main" || got.RoomID != "c7" || got.User.MentionName != "ada" || got.Source != "Mattermost" {
+		t.Errorf("unexpected command %+v", got)
+	}
+	if resp.ResponseType != "in_channel" || len(resp.Attachments) != 1 || resp.Attachments[0].Text != "rendered kick" || resp.Attachments[0].Color != "#2eb886" {
+		t.Errorf("unexpected response %+v", resp)
+	}
+
+	w = httptest.NewRecorder()
+	h.Command(w, command("--help", "s3cret"))
+	resp = response{}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.ResponseType != "ephemeral" {
+		t.Errorf("help should be ephemeral, got %q", resp.ResponseType)
+	}
+
+	w = httptest.NewRecorder()
+	h.Command(w, command("list", "wrong"))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("bad token got status %d", w.Code)
+	}
+}
+
+func TestCommandResponseURL(t *testing.T) {
+	posted := make(chan response, 2)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var m response
+		json.NewDecoder(r.Body).Decode(&m)
+		posted <- m
+	}))
+	defer ts.Close()
+
+	release := make(chan struct{})
+	dispatch := func(ctx context.Context, cmd chat.Command, r chat.Replier) {
+		<-release
+		r.Reply(ctx, chat.Message{Text: "Error kicking off build", Color: chat.ColorRed})
+		r.Reply(ctx, chat.Message{Template: "kick", Color: chat.ColorGreen})
+	}
+	h := New(config.MattermostSettings{Token: "s3cret"}, dispatch, markdown)
+
+	w := httptest.NewRecorder()
+	h.Command(w, commandTo("kick Web_CI main", "s3cret", ts.URL))
+	var resp response
+	json.NewDecoder(w.Body).Decode(&resp)
+	if w.Code != http.StatusOK || resp.ResponseType != "ephemeral" || len(resp.Attachments) != 0 {
+		t.Errorf("unexpected acknowledgement %d %+v", w.Code, resp)
+	}
+
+	close(release)
+	for _, want := range []string{"ephemeral", "in_channel"} {
+		select {
+		case m := <-posted:
+			if m.ResponseType != want || len(m.Attachments) != 1 {
+				t.Errorf("expected a %v reply got %+v", want, m)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("reply was not sent to the response_url")
+		}
+	}
+}
+
+func TestNotify(t *testing.T) {
+	posted := make(chan response, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var m response
+		json.NewDecoder(r.Body).Decode(&m)
+		posted <- m
+	}))
+	defer ts.Close()
+
+	dispatch := func(ctx context.Context, cmd chat.Command, r chat.Replier) {
+		n, ok := r.(chat.Notifier)
+		if !ok {
+			t.Fatal("replier can not notify with a webhook set up")
+		}
+		if err := n.Notify(ctx, chat.Message{Text: "Build complete", Color: chat.ColorRed}); err != nil {
+			t.Errorf("Notify: %v", err)
+		}
+	}
+	h := New(config.MattermostSettings{Token: "s3cret", WebhookURL: ts.URL, Username: "buildbot"}, dispatch, markdown)
+	h.Command(httptest.NewRecorder(), command("kick Web_CI main", "s3cret"))
+
+	m := <-posted
+	if m.Channel != "deploys" || m.Username != "buildbot" || m.Attachments[0].Text != "**Build complete**" || m.Attachments[0].Color != "#a30200" {
+		t.Errorf("unexpected webhook post %+v", m)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/mkobaly/hipchatBot/chat"
+	"github.com/mkobaly/hipchatBot/ci"
+)
+
+// permission returns the name a command goes by in the role permissions and
+// the build configuration it is about, if any. ok is false for "--help" and
+// unknown commands, which only show the help, for "confirm", whose kick is
+// checked when it runs, and for "audit", which only admin roles can run.
+func permission(cmd []string) (name string, configID string, ok bool) {
+	if len(cmd) < 2 {
+		return "", "", false
+	}
+	action := cmd[1]
+	switch action {
+	case "kick", "watch", "unwatch":
+		if len(cmd) > 2 {
+			configID = cmd[2]
+		}
+		return action, configID, true
+	case "schedule":
+		if len(cmd) > 2 && (cmd[2] == "list" || cmd[2] == "remove") {
+			return "schedule " + cmd[2], "", true
+		}
+		if len(cmd) > 2 {
+			configID = cmd[2]
+		}
+		return action, configID, true
+	case "list", "status", "cancel", "log", "artifacts", "mine", "watching":
+		return action, "", true
+	}
+	return "", "", false
+}
+
+// buildPermission checks a command on a build, like status or cancel, against
+// the projects and filters of the room and the user's role. Both are matched
+// against the configuration the CI server reports for the build, so a build
+// that can not be looked up is denied. It returns the build when it was looked
+// up and why the command was denied, or "" when it can go ahead.
+func (c *Context) buildPermission(ctx context.Context, provider ci.Provider, command chat.Command, name string, id string) (*ci.Build, string) {
+	settings := c.roomSettings(command.RoomID)
+	limited := len(settings.Projects) > 0 || len(settings.Filters) > 0
+	roles := c.cfg != nil && c.cfg.Permissions.Enabled()
+	if !limited && !roles {
+		return nil, ""
+	}
+	user := command.User
+	b, err := provider.Status(ctx, id)
+	if err != nil || b.ConfigID == "" {
+		log.Printf("Denied %v %v to %v, its build configuration is unknown: %v", name, id, user, err)
+		return nil, "Sorry " + user.Name + ", the build configuration of " + id + " could not be checked"
+	}
+	if !settings.Visible(b.ConfigID) {
+		log.Printf("Denied %v %v of %v to %v in room %v", name, id, b.ConfigID, user, command.RoomID)
+		return b, "Sorry " + user.Name + ", builds of " + b.ConfigID + " can not be seen from this room"
+	}
+	if !roles {
+		return b, ""
+	}
+	role := c.cfg.Permissions.RoleOf(command.Source, user.ID, user.MentionName)
+	if !c.cfg.Permissions.Allows(role, name, b.ConfigID) {
+		log.Printf("Denied %v %v of %v to %v with role %q", name, id, b.ConfigID, user, role)
+		return b, denial(user, role, name, b.ConfigID)
+	}
+	return b, ""
+}
+
+// denial tells the user why the command was not run
+func denial(user chat.User, role string, name string, configID string) string {
+	what := name
+	if configID != "" {
+		what += " " + configID
+	}
+	if role == "" {
+		return "Sorry " + user.Name + ", you have no role allowing you to " + what
+	}
+	return "Sorry " + user.Name + ", your " + role + " role does not allow you to " + what
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mkobaly/hipchatBot/chat"
+	"github.com/mkobaly/hipchatBot/config"
+)
+
+var testPermissions = config.Permissions{
+	Default: "viewer",
+	Roles: map[string]config.Role{
+		"viewer":   {Commands: []string{"list", "status", "log", "artifacts", "mine", "watching", "schedule list"}},
+		"builder":  {Commands: []string{"*"}, Configs: []string{"*_CI"}},
+		"releaser": {Commands: []string{"*"}, Configs: []string{"*"}},
+		"admin":    {Commands: []string{"list"}, Admin: true},
+	},
+	Users: map[string]string{
+		"4513556":    "releaser",
+		"@ada":       "builder",
+		"@linus":     "admin",
+		"slack:U123": "releaser",
+	},
+}
+
+func TestPermissions(t *testing.T) {
+	c, p := settingsContext(t)
+	c.cfg.Permissions = testPermissions
+	ctx := context.Background()
+	run := func(text string, user chat.User) replies {
+		var r replies
+		c.dispatch(ctx, chat.Command{Text: text, User: user, RoomID: "1", Source: "HipChat"}, &r)
+		return r
+	}
+	viewer := chat.User{ID: "1", Name: "Grace", MentionName: "grace"}
+	builder := chat.User{ID: "2", Name: "Ada Lovelace", MentionName: "Ada"}
+	releaser := chat.User{ID: "4513556", Name: "Michael Kobaly", MentionName: "MichaelKobaly"}
+
+	if r := run("/build list", viewer); len(r) != 1 || r[0].Template != "list" {
+		t.Errorf("viewer can not list: %+v", r)
+	}
+	if r := run("/build --help", viewer); len(r) != 1 || r[0].Template != "help" {
+		t.Errorf("viewer can not get help: %+v", r)
+	}
+	r := run("/build kick Web_CI main", viewer)
+	if len(r) != 1 || r[0].Color != chat.ColorRed || r[0].Text != "Sorry Grace, your viewer role does not allow you to kick Web_CI" {
+		t.Errorf("viewer kicked off a build: %+v", r)
+	}
+
+	run("/build kick Web_CI main", builder)
+	if r := run("/build kick Web_RC main", builder); len(r) != 1 || r[0].Color != chat.ColorRed {
+		t.Errorf("builder kicked off a release: %+v", r)
+	}
+	run("/build kick Web_RC main", releaser)
+	if len(p.kicked) != 2 || p.kicked[0].ConfigID != "Web_CI" || p.kicked[1].ConfigID != "Web_RC" {
+		t.Errorf("unexpected builds %+v", p.kicked)
+	}
+
+	// build 2 is of Web_RC, which builders can not touch
+	if r := run("/build status 1", builder); len(r) != 1 || r[0].Template != "status" {
+		t.Errorf("builder can not get the status of a CI build: %+v", r)
+	}
+	for _, cmd := range []string{"status", "cancel", "log", "artifacts"} {
+		r := run("/build "+cmd+" 2", builder)
+		if len(r) != 1 || r[0].Text != "Sorry Ada Lovelace, your builder role does not allow you to "+cmd+" Web_RC" {
+			t.Errorf("builder can %v a release build: %+v", cmd, r)
+		}
+	}
+	if r := run("/build log 99", builder); len(r) != 1 || r[0].Color != chat.ColorRed {
+		t.Errorf("builder can see the log of an unknown build: %+v", r)
+	}
+}
+
+func TestPermissionsOfOtherChats(t *testing.T) {
+	c, p := settingsContext(t)
+	c.cfg.Permissions = testPermissions
+	ctx := context.Background()
+	run := func(text string, source string, user chat.User) replies {
+		var r replies
+		c.dispatch(ctx, chat.Command{Text: text, User: user, RoomID: "1", Source: source}, &r)
+		return r
+	}
+
+	// user names on the other chats are picked by their users
+	for _, source := range []string{"Slack", "Mattermost", "Teams"} {
+		for _, u := range []chat.User{
+			{ID: "4513556", Name: "Michael Kobaly", MentionName: "MichaelKobaly"},
+			{ID: "U9", Name: "Ada", MentionName: "ada"},
+		} {
+			if r := run("/build kick Web_CI main", source, u); len(r) != 1 || r[0].Color != chat.ColorRed {
+				t.Errorf("%v user %v got a HipChat role: %+v", source, u.MentionName, r)
+			}
+		}
+	}
+	if r := run("/build audit", "Teams", chat.User{ID: "29:1", Name: "linus", MentionName: "linus"}); len(r) != 1 || r[0].Color != chat.ColorRed {
+		t.Errorf("Teams user named linus read the audit log: %+v", r)
+	}
+	if r := run("/build kick Web_CI main", "Mattermost", chat.User{ID: "U123", Name: "Someone", MentionName: "someone"}); len(r) != 1 || r[0].Color != chat.ColorRed {
+		t.Errorf("Mattermost user got the role of the Slack user with the same ID: %+v", r)
+	}
+
+	run("/build kick Web_RC main", "Slack", chat.User{ID: "U123", Name: "Michael Kobaly", MentionName: "mk"})
+	if len(p.kicked) != 1 || p.kicked[0].ConfigID != "Web_RC" {
+		t.Errorf("Slack releaser did not kick off a release: %+v", p.kicked)
+	}
+
+	c.store.SetRoom("1", config.RoomSettings{AllowedUsers: []string{"@MichaelKobaly", "slack:U123"}})
+	if r := run("/build list", "Mattermost", chat.User{ID: "x", Name: "Michael Kobaly", MentionName: "MichaelKobaly"}); len(r) != 1 || r[0].Color != chat.ColorRed {
+		t.Errorf("Mattermost user allowed in the room by a HipChat mention name: %+v", r)
+	}
+	if r := run("/build list", "Slack", chat.User{ID: "U123", Name: "Michael Kobaly", MentionName: "mk"}); len(r) != 1 || r[0].Template != "list" {
+		t.Errorf("allowed Slack user can not list: %+v", r)
+	}
+}
+
+func TestPermissionsValidate(t *testing.T) {
+	if err := testPermissions.Validate(); err != nil {
+		t.Error(err)
+	}
+	bad := testPermissions
+	bad.Users = map[string]string{"ada": "superuser"}
+	if err := bad.Validate(); err == nil {
+		t.Errorf("undefined role accepted")
+	}
+	if err := (config.Permissions{}).Validate(); err != nil {
+		t.Errorf("no permissions: %v", err)
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/mkobaly/hipchatBot/chat"
+)
+
+// Reply template formats. Every reply template has a variant in each.
+const (
+	FormatHTML     = "html"
+	FormatText     = "text"
+	FormatMarkdown = "markdown"
+)
+
+// formatExt is the file extension of each format's template variant
+var formatExt = map[string]string{
+	FormatHTML:     ".html",
+	FormatText:     ".txt",
+	FormatMarkdown: ".md",
+}
+
+// replyTemplates are the templates the commands and the build watcher reply
+// with
+var replyTemplates = []string{"help", "list", "kick", "status", "log", "artifacts", "mine", "finished", "watching", "schedules", "audit"}
+
+// executor is what html/template and text/template templates have in common
+type executor interface {
+	Execute(w io.Writer, data interface{}) error
+}
+
+// parseTemplate parses the source of a template variant. HTML is escaped by
+// html/template, the text and Markdown variants are rendered as is with their
+// line ends made "\n".
+func parseTemplate(format string, name string, src string) (executor, error) {
+	if format == FormatHTML {
+		return template.New(name).Parse(src)
+	}
+	return texttemplate.New(name).Parse(strings.Replace(src, "\r\n", "\n", -1))
+}
+
+// templateStore holds the parsed reply templates. Variants found in dir
+// override the embedded ones.
+type templateStore struct {
+	dir    string
+	mu     sync.RWMutex
+	parsed map[string]executor
+	// stamp is dirStamp when the templates were last loaded
+	stamp string
+}
+
+// newTemplateStore parses every variant of the reply templates, failing
+// with all the parse errors found
+func newTemplateStore(dir string) (*templateStore, error) {
+	s := &templateStore{dir: dir}
+	return s, s.load()
+}
+
+func (s *templateStore) load() error {
+	stamp := s.dirStamp()
+	parsed := make(map[string]executor)
+	var errs []string
+	for _, name := range replyTemplates {
+		for format, ext := range formatExt {
+			src, err := s.source(name + ext)
+			if err == nil {
+				parsed[name+ext], err = parseTemplate(format, name+ext, src)
+			}
+			if err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+	}
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return errors.New(strings.Join(errs, "; "))
+	}
+	s.mu.Lock()
+	s.parsed, s.stamp = parsed, stamp
+	s.mu.Unlock()
+	return nil
+}
+
+// source returns the file from dir, or the embedded asset when dir does not
+// have it
+func (s *templateStore) source(file string) (string, error) {
+	if s.dir != "" {
+		b, err := ioutil.ReadFile(filepath.Join(s.dir, file))
+		if err == nil {
+			return string(b), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+	b, err := Asset("templates/" + file)
+	return string(b), err
+}
+
+// dirStamp sums up the names, sizes and modification times of the files in
+// dir so changes can be noticed
+func (s *templateStore) dirStamp() string {
+	if s.dir == "" {
+		return ""
+	}
+	infos, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return err.Error()
+	}
+	var b bytes.Buffer
+	for _, fi := range infos {
+		fmt.Fprintf(&b, "%s %d %d\n", fi.Name(), fi.Size(), fi.ModTime().UnixNano())
+	}
+	return b.String()
+}
+
+// watch reloads the templates whenever the files in dir change. When a
+// changed template does not parse the error is logged and the templates in
+// use are kept.
+func (s *templateStore) watch(ctx context.Context, interval time.Duration) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+		stamp := s.dirStamp()
+		s.mu.RLock()
+		changed := stamp != s.stamp
+		s.mu.RUnlock()
+		if !changed {
+			continue
+		}
+		if err := s.load(); err != nil {
+			log.Printf("Error reloading templates from %v: %v", s.dir, err)
+			// wait for the next change before trying again
+			s.mu.Lock()
+			s.stamp = stamp
+			s.mu.Unlock()
+			continue
+		}
+		log.Printf("Reloaded templates from %v", s.dir)
+	}
+}
+
+func (s *templateStore) render(format string, templateName string, data interface{}) (string, error) {
+	s.mu.RLock()
+	t, ok := s.parsed[templateName+formatExt[format]]
+	s.mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("no %v variant of template %q", format, templateName)
+	}
+	var buffer bytes.Buffer
+	err := t.Execute(&buffer, data)
+	if format == FormatHTML {
+		return buffer.String(), err
+	}
+	return strings.TrimSpace(buffer.String()), err
+}
+
+// templates are the reply templates in use. They are the embedded ones
+// unless main loads a templates_dir.
+var templates = embeddedTemplates()
+
+func embeddedTemplates() *templateStore {
+	s, err := newTemplateStore("")
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// renderTemplate renders the format's variant of a reply template
+func renderTemplate(format string, templateName string, data interface{}) string {
+	out, err := templates.render(format, templateName, data)
+	if err != nil {
+		log.Printf("Error rendering template %v: %v", templateName, err)
+	}
+	return out
+}
+
+// renderer renders the templated replies of a chat backend in format
+func renderer(format string) func(chat.Message) string {
+	return func(m chat.Message) string {
+		return renderTemplate(format, m.Template, m.Data)
+	}
+}
+
+var (
+	mdBold  = regexp.MustCompile(`\*\*([^*\n]+)\*\*`)
+	mdLink  = regexp.MustCompile(`\[([^\]\n]*)\]\(([^)\s]*)\)`)
+	mdItem  = regexp.MustCompile(`(?m)^- `)
+	mdFence = "```"
+)
+
+// slackText renders the replies Slack has no Block Kit layout for. Slack's
+// mrkdwn is the Markdown variant with single * bold, <url|text> links and
+// &, <, > escaped.
+func slackText(m chat.Message) string {
+	s := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(renderTemplate(FormatMarkdown, m.Template, m.Data))
+	// code blocks, like build logs, are left alone
+	parts := strings.Split(s, mdFence)
+	for i := 0; i < len(parts); i += 2 {
+		p := mdLink.ReplaceAllString(parts[i], "<$2|$1>")
+		p = mdBold.ReplaceAllString(p, "*$1*")
+		parts[i] = mdItem.ReplaceAllString(p, "• ")
+	}
+	return strings.Join(parts, mdFence)
+}
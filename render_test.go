@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mkobaly/hipchatBot/audit"
+	"github.com/mkobaly/hipchatBot/chat"
+	"github.com/mkobaly/hipchatBot/ci"
+	"github.com/mkobaly/hipchatBot/store"
+)
+
+// sampleData is reply data for rendering each template
+var sampleData = map[string]interface{}{
+	"help":   nil,
+	"list":   []chat.ServerConfigs{{Server: "main", IDs: []string{"Web_CI"}}, {Server: "jenkins", Error: true}},
+	"kick":   chat.KickReply{BuildConfigID: "Web_CI", Branch: "main", Revision: "abc123", TaskID: "42", Server: "main"},
+	"status": &ci.Build{ID: "42", ConfigID: "Web_CI", Branch: "main", State: ci.StateFinished, Status: ci.StatusSuccess},
+	"log": struct {
+		TaskID string
+		Log    string
+	}{"42", "step 1\nstep 2"},
+	"artifacts": struct {
+		TaskID    string
+		Artifacts []ci.Artifact
+	}{"42", []ci.Artifact{{Name: "site.zip", URL: "https://ci/site.zip"}}},
+	"mine": struct {
+		User   chat.User
+		Builds []*ci.Build
+	}{chat.User{Name: "Ada"}, []*ci.Build{{ID: "42", ConfigID: "Web_CI", Branch: "main"}}},
+	"schedules": []scheduleEntry{{Schedule: store.Schedule{ID: "1", ConfigID: "Web_CI", Branch: "feature/x", Cron: "0 2 * * *", User: "Ada"}, Next: "2026-10-15 02:00"}},
+	"watching":  []store.Watch{{ConfigID: "Web_CI", Branch: "main", Only: store.OnlyFailures}},
+	"finished":  &ci.Build{ID: "42", ConfigID: "Web_CI", State: ci.StateFinished, Status: ci.StatusFailure, WebURL: "https://ci/42"},
+	"audit":     []auditEntry{{Entry: audit.Entry{User: "Ada", RoomName: "Web", Command: "/build kick Web_CI main", Outcome: audit.OutcomeOK, TaskID: "42"}, When: "Oct 15 02:00:00"}},
+}
+
+func TestTemplateVariants(t *testing.T) {
+	for _, name := range replyTemplates {
+		data, ok := sampleData[name]
+		if !ok {
+			t.Errorf("no sample data for template %v", name)
+		}
+		for format, ext := range formatExt {
+			if _, err := Asset("templates/" + name + ext); err != nil {
+				t.Errorf("template %v has no %v variant", name, format)
+				continue
+			}
+			if out := renderTemplate(format, name, data); out == "" || strings.Contains(out, "<no value>") {
+				t.Errorf("%v variant of %v rendered %q", format, name, out)
+			}
+		}
+	}
+
+	// every embedded template is a variant of a reply template
+	known := make(map[string]bool)
+	for _, name := range replyTemplates {
+		for _, ext := range formatExt {
+			known["templates/"+name+ext] = true
+		}
+	}
+	for _, asset := range AssetNames() {
+		if !known[asset] {
+			t.Errorf("%v is not a variant of a reply template", asset)
+		}
+	}
+}
+
+func TestSlackText(t *testing.T) {
+	data := struct {
+		TaskID    string
+		Artifacts []ci.Artifact
+	}{
+		TaskID:    "42",
+		Artifacts: []ci.Artifact{{Name: "site & docs.zip", URL: "https://ci/site.zip"}},
+	}
+	got := slackText(chat.Message{Template: "artifacts", Data: data})
+	want := "*Build Artifacts 42*\n\n• <https://ci/site.zip|site &amp; docs.zip>"
+	if got != want {
+		t.Errorf("slackText() = %q, want %q", got, want)
+	}
+
+	log := struct {
+		TaskID string
+		Log    string
+	}{"42", "- **kept**"}
+	if got := slackText(chat.Message{Template: "log", Data: log}); !strings.Contains(got, "```\n- **kept**\n```") {
+		t.Errorf("slackText() changed the log: %q", got)
+	}
+}
+
+func TestTemplateStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	write := func(name string, src string) {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("status.txt", "{{.ConfigID}} is {{.Status}}")
+	s, err := newTemplateStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := sampleData["status"]
+	if out, _ := s.render(FormatText, "status", b); out != "Web_CI is SUCCESS" {
+		t.Errorf("override not used: %q", out)
+	}
+	if out, _ := s.render(FormatHTML, "status", b); !strings.Contains(out, "Build Result Status") {
+		t.Errorf("embedded template not used: %q", out)
+	}
+
+	write("kick.md", "{{.BuildConfigID")
+	if _, err := newTemplateStore(dir); err == nil || !strings.Contains(err.Error(), "kick.md") {
+		t.Errorf("parse error not reported: %v", err)
+	}
+
+	// a broken template is skipped by the reload, a fixed one picked up
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.watch(ctx, 10*time.Millisecond)
+	write("status.txt", "{{.Branch}} finished")
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		write("kick.md", "kicked {{.BuildConfigID}}")
+		out, _ := s.render(FormatText, "status", b)
+		if out == "main finished" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("templates not reloaded: %q", out)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
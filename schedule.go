@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/mkobaly/hipchatBot/audit"
+	"github.com/mkobaly/hipchatBot/chat"
+	"github.com/mkobaly/hipchatBot/ci"
+	"github.com/mkobaly/hipchatBot/cron"
+	"github.com/mkobaly/hipchatBot/store"
+)
+
+// maxScheduleDelay is how late a scheduled build is still kicked off, when
+// the bot could not check the schedules in time
+const maxScheduleDelay = 5 * time.Minute
+
+// nextRunFormat is how the next run of a schedule is shown
+const nextRunFormat = "Mon Jan 2 15:04 MST"
+
+// scheduleEntry is a schedule as the schedules reply lists it
+type scheduleEntry struct {
+	store.Schedule
+	Next string
+}
+
+// scheduleEntries returns the room's schedules with their next run
+func (c *Context) scheduleEntries(roomID string, now time.Time) []scheduleEntry {
+	var res []scheduleEntry
+	for _, sc := range c.store.RoomSchedules(roomID) {
+		e := scheduleEntry{Schedule: sc, Next: "never"}
+		if s, err := cron.Parse(sc.Cron); err == nil {
+			if next := s.Next(now); !next.IsZero() {
+				e.Next = next.Format(nextRunFormat)
+			}
+		}
+		res = append(res, e)
+	}
+	return res
+}
+
+// runSchedules kicks off the scheduled builds at their times, in the bot's
+// time zone, until ctx is done
+func (c *Context) runSchedules(ctx context.Context) {
+	last := time.Now().Truncate(time.Minute)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(last.Add(time.Minute))):
+		}
+		now := time.Now().Truncate(time.Minute)
+		for t := last.Add(time.Minute); !t.After(now); t = t.Add(time.Minute) {
+			if now.Sub(t) <= maxScheduleDelay {
+				c.runDueSchedules(ctx, t)
+			}
+		}
+		last = now
+	}
+}
+
+// runDueSchedules kicks off the builds scheduled for the minute t
+func (c *Context) runDueSchedules(ctx context.Context, t time.Time) {
+	for _, sc := range c.store.Schedules() {
+		s, err := cron.Parse(sc.Cron)
+		if err != nil {
+			log.Printf("Schedule %v: %v", sc.ID, err)
+			continue
+		}
+		if s.Matches(t) {
+			c.runSchedule(ctx, sc)
+		}
+	}
+}
+
+// scheduleRefusal checks a schedule against the room's settings and the role
+// of the user who added it as they are now, as both may have changed since.
+// It returns why the build may not be kicked off, or "" when it can go ahead.
+func (c *Context) scheduleRefusal(sc store.Schedule) string {
+	settings := c.roomSettings(sc.RoomID)
+	if !settings.Visible(sc.ConfigID) {
+		return sc.ConfigID + " can no longer be kicked off from this room"
+	}
+	if !settings.Allowed(sc.Source, sc.UserID, sc.MentionName) {
+		return sc.User + " is no longer allowed to run builds in this room"
+	}
+	if c.cfg != nil && c.cfg.Permissions.Enabled() {
+		role := c.cfg.Permissions.RoleOf(sc.Source, sc.UserID, sc.MentionName)
+		if !c.cfg.Permissions.Allows(role, "schedule", sc.ConfigID) {
+			return "the role of " + sc.User + " no longer allows scheduling " + sc.ConfigID
+		}
+	}
+	return ""
+}
+
+// runSchedule kicks off a scheduled build and announces it in the room
+func (c *Context) runSchedule(ctx context.Context, sc store.Schedule) {
+	entry := &audit.Entry{
+		Time:     time.Now(),
+		Source:   sc.Source,
+		User:     sc.User,
+		RoomID:   sc.RoomID,
+		RoomName: sc.RoomName,
+		Command:  "schedule " + sc.ID + " at " + sc.Cron,
+		Action:   "schedule run",
+		Server:   sc.Server,
+		ConfigID: sc.ConfigID,
+		Branch:   sc.Branch,
+		Outcome:  audit.OutcomeFailed,
+	}
+	defer c.writeAudit(entry)
+	provider, ok := c.providers[sc.Server]
+	if !ok {
+		log.Printf("Schedule %v: unknown CI server %q", sc.ID, sc.Server)
+		return
+	}
+	n := c.notifier(sc.Source, sc.RoomID, sc.RoomName)
+	if n == nil {
+		log.Printf("Schedule %v: can not post to %v room %v", sc.ID, sc.Source, sc.RoomID)
+		return
+	}
+	notify := func(m chat.Message) {
+		if err := n.Notify(ctx, m); err != nil {
+			log.Printf("Error posting schedule %v to room %v: %v", sc.ID, sc.RoomID, err)
+		}
+	}
+	if refusal := c.scheduleRefusal(sc); refusal != "" {
+		log.Printf("Refused schedule %v: %v", sc.ID, refusal)
+		notify(chat.Message{Text: "Not kicking off the scheduled build: " + refusal, Color: chat.ColorRed})
+		entry.Outcome, entry.Reply = audit.OutcomeRefused, refusal
+		return
+	}
+	if rejection := c.limits.allow(chat.User{}, "", provider, sc.ConfigID); rejection != "" {
+		log.Printf("Limited schedule %v: %v", sc.ID, rejection)
+		notify(chat.Message{Text: "Not kicking off the scheduled build: " + rejection, Color: chat.ColorYellow})
+		entry.Outcome, entry.Reply = audit.OutcomeRefused, rejection
+		return
+	}
+	req := ci.BuildRequest{
+		ConfigID: sc.ConfigID,
+		Branch:   sc.Branch,
+		Comment:  "Scheduled from " + sc.Source + " by " + sc.User,
+		User:     sc.User,
+	}
+	b, err := provider.Trigger(ctx, req)
+	if err != nil {
+		log.Printf("Error kicking off schedule %v: %v", sc.ID, err)
+		notify(chat.Message{Text: "Error kicking off the scheduled build of " + sc.ConfigID, Color: chat.ColorRed})
+		return
+	}
+	entry.Outcome, entry.TaskID = audit.OutcomeOK, b.ID
+	data := chat.KickReply{
+		BuildConfigID: sc.ConfigID,
+		Branch:        b.Branch,
+		TaskID:        b.ID,
+		WebURL:        b.WebURL,
+		Schedule:      sc.Cron,
+	}
+	if len(c.providers) > 1 {
+		data.Server = sc.Server
+	}
+	notify(chat.Message{Template: "kick", Data: data, Color: chat.ColorGreen})
+	c.buildChanged(sc.RoomID, b.ID, b)
+	c.followBuild(sc.RoomID, provider, req, b, n)
+}
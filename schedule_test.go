@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mkobaly/hipchatBot/chat"
+	"github.com/mkobaly/hipchatBot/ci"
+	"github.com/mkobaly/hipchatBot/config"
+	"github.com/mkobaly/hipchatBot/store"
+)
+
+func TestSplitCommand(t *testing.T) {
+	for in, want := range map[string][]string{
+		`/build schedule Web_CI feature/x "0 2 * * 1-5"`: {"/build", "schedule", "Web_CI", "feature/x", "0 2 * * 1-5"},
+		"/build schedule Web_CI main “@daily”":           {"/build", "schedule", "Web_CI", "main", "@daily"},
+		"  /build   list ":      {"/build", "list"},
+		`/build kick Web_CI ""`: {"/build", "kick", "Web_CI", ""},
+	} {
+		if got := splitCommand(in); !reflect.DeepEqual(got, want) {
+			t.Errorf("splitCommand(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func scheduleContext(t *testing.T) (*Context, *kickProvider, *replies) {
+	st, err := store.Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &kickProvider{}
+	c := &Context{
+		rooms:     make(map[string]*RoomConfig),
+		providers: map[string]ci.Provider{"default": p},
+		cfg:       &config.Config{DefaultServer: "default"},
+		health:    newRoomHealth(),
+		store:     st,
+		subs:      newSubscriptions(),
+		events:    newBuildEvents(),
+		confirms:  newConfirmations(),
+	}
+	posted := &replies{}
+	c.addNotifier("test", func(roomID string, roomName string) chat.Notifier {
+		return notifierFunc(posted.Reply)
+	})
+	return c, p, posted
+}
+
+func TestScheduleCommands(t *testing.T) {
+	c, _, _ := scheduleContext(t)
+	run := func(text string) replies {
+		var r replies
+		user := chat.User{Name: "Ada Lovelace", MentionName: "ada"}
+		c.dispatch(context.Background(), chat.Command{Text: text, User: user, RoomID: "1", Source: "test"}, &r)
+		return r
+	}
+
+	r := run(`/build schedule Web_CI feature/x "0 2 * * 1-5"`)
+	if len(r) != 1 || r[0].Color != chat.ColorGreen || !strings.Contains(r[0].Text, "Scheduled build 1 ") {
+		t.Fatalf("unexpected schedule reply %+v", r)
+	}
+	if r := run(`/build schedule Web_CI main "every night"`); len(r) != 1 || !strings.HasPrefix(r[0].Text, "Bad schedule") {
+		t.Errorf("bad cron expression accepted: %+v", r)
+	}
+
+	r = run("/build schedule list")
+	entries := r[0].Data.([]scheduleEntry)
+	if len(entries) != 1 || entries[0].Cron != "0 2 * * 1-5" || entries[0].Server != "default" || entries[0].User != "Ada Lovelace (@ada)" || entries[0].Next == "never" {
+		t.Errorf("unexpected schedules %+v", entries)
+	}
+
+	if r := run("/build schedule remove 2"); r[0].Color != chat.ColorYellow {
+		t.Errorf("removed a schedule that does not exist: %+v", r)
+	}
+	if r := run("/build schedule remove 1"); r[0].Color != chat.ColorGreen {
+		t.Errorf("schedule not removed: %+v", r)
+	}
+	if r := run("/build schedule list"); len(r[0].Data.([]scheduleEntry)) != 0 {
+		t.Errorf("removed schedule still listed")
+	}
+
+	var r2 replies
+	c.dispatch(context.Background(), chat.Command{Text: `/build schedule Web_CI main "@daily"`, RoomID: "1", Source: "the command line"}, &r2)
+	if len(r2) != 1 || r2[0].Color != chat.ColorYellow {
+		t.Errorf("schedule for a room the bot can not post to: %+v", r2)
+	}
+}
+
+func TestRunDueSchedules(t *testing.T) {
+	c, p, posted := scheduleContext(t)
+	c.store.AddSchedule(store.Schedule{RoomID: "1", Source: "test", Server: "default", ConfigID: "Web_CI", Branch: "feature/x", Cron: "0 2 * * *", User: "Ada"})
+
+	c.runDueSchedules(context.Background(), time.Date(2026, time.October, 14, 1, 0, 0, 0, time.Local))
+	if len(p.kicked) != 0 {
+		t.Fatalf("kicked off at the wrong time: %+v", p.kicked)
+	}
+	c.runDueSchedules(context.Background(), time.Date(2026, time.October, 14, 2, 0, 0, 0, time.Local))
+	if len(p.kicked) != 1 || p.kicked[0].Branch != "feature/x" || p.kicked[0].User != "Ada" {
+		t.Fatalf("unexpected builds %+v", p.kicked)
+	}
+	if len(*posted) != 1 || (*posted)[0].Data.(chat.KickReply).Schedule != "0 2 * * *" {
+		t.Errorf("unexpected announcement %+v", *posted)
+	}
+}
+
+func TestProtectedSchedule(t *testing.T) {
+	c, _, _ := scheduleContext(t)
+	c.cfg.Protected = []string{"*_RC"}
+	user := chat.User{ID: "2", Name: "Ada Lovelace", MentionName: "ada"}
+	run := func(text string) replies {
+		var r replies
+		c.dispatch(context.Background(), chat.Command{Text: text, User: user, RoomID: "1", Source: "test"}, &r)
+		return r
+	}
+
+	r := run(`/build schedule Web_RC main "@daily"`)
+	if len(r) != 1 || r[0].Color != chat.ColorYellow || !strings.Contains(r[0].Text, "confirm") {
+		t.Fatalf("protected schedule not held for confirmation: %+v", r)
+	}
+	if len(c.store.Schedules()) != 0 {
+		t.Fatalf("protected schedule saved before its confirmation")
+	}
+	token := r[0].Text[strings.LastIndex(r[0].Text, "confirm ")+len("confirm ") : strings.Index(r[0].Text, " within")]
+	if r := run("/build confirm " + token); len(r) != 1 || r[0].Color != chat.ColorGreen {
+		t.Fatalf("confirmed schedule not added: %+v", r)
+	}
+	if s := c.store.Schedules(); len(s) != 1 || s[0].UserID != "2" || s[0].MentionName != "ada" {
+		t.Errorf("unexpected schedules %+v", s)
+	}
+}
+
+func TestScheduleRechecked(t *testing.T) {
+	c, p, posted := scheduleContext(t)
+	c.addNotifier("HipChat", func(roomID string, roomName string) chat.Notifier {
+		return notifierFunc(posted.Reply)
+	})
+	sc, _ := c.store.AddSchedule(store.Schedule{RoomID: "1", Source: "HipChat", Server: "default", ConfigID: "Web_RC", Branch: "main", Cron: "@daily",
+		User: "Ada Lovelace (@ada)", UserID: "2", MentionName: "ada"})
+
+	// the room has since been limited to CI builds
+	c.store.SetRoom("1", config.RoomSettings{Filters: []string{"*_CI"}})
+	c.runSchedule(context.Background(), sc)
+
+	// and Ada's role no longer allows release builds
+	c.store.SetRoom("1", config.RoomSettings{})
+	c.cfg.Permissions = testPermissions
+	c.runSchedule(context.Background(), sc)
+
+	if len(p.kicked) != 0 {
+		t.Errorf("schedule kicked off a build it is no longer allowed to: %+v", p.kicked)
+	}
+	if len(*posted) != 2 || (*posted)[0].Color != chat.ColorRed || (*posted)[1].Color != chat.ColorRed {
+		t.Errorf("unexpected announcements %+v", *posted)
+	}
+}
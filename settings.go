@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+	"unicode"
+
+	"github.com/mkobaly/hipchatBot/config"
+)
+
+// notifyOptions are the choices of the config page's notification setting
+var notifyOptions = []string{config.NotifyAll, config.NotifyFailures, config.NotifyNone}
+
+// page renders one of the add-on's pages from the static folder inside
+// layout.hbs. vals are added to the values every page gets.
+func (c *Context) page(w http.ResponseWriter, r *http.Request, name string, vals map[string]interface{}) {
+	data := map[string]interface{}{
+		"LocalBaseUrl":  c.baseURL,
+		"SignedRequest": r.URL.Query().Get("signed_request"),
+		"HostScriptUrl": c.baseURL,
+	}
+	for k, v := range vals {
+		data[k] = v
+	}
+	tmpl, err := template.ParseFiles(path.Join("./static", "layout.hbs"), path.Join("./static", name))
+	if err != nil {
+		log.Printf("Error parsing %v: %v", name, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	tmpl.ExecuteTemplate(w, "layout", data)
+}
+
+// config shows the room's settings page and saves the settings posted from it
+func (c *Context) config(w http.ResponseWriter, r *http.Request) {
+	roomID, err := c.signedRoom(r)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	rs := c.roomSettings(roomID)
+	vals := map[string]interface{}{}
+	if r.Method == "POST" {
+		rs, err = c.parseRoomSettings(r)
+		if err == nil {
+			err = c.store.SetRoom(roomID, rs)
+		}
+		if err != nil {
+			log.Printf("Error saving settings of room %v: %v", roomID, err)
+			vals["Error"] = err.Error()
+		} else {
+			vals["Saved"] = true
+		}
+	}
+	vals["Servers"] = c.serverNames()
+	vals["NotifyOptions"] = notifyOptions
+	vals["Server"] = rs.Server
+	vals["Projects"] = strings.Join(rs.Projects, ", ")
+	vals["Filters"] = strings.Join(rs.Filters, ", ")
+	vals["Branch"] = rs.Branch
+	vals["Notify"] = rs.Notify
+	vals["AllowedUsers"] = strings.Join(rs.AllowedUsers, ", ")
+	c.page(w, r, "config.hbs", vals)
+}
+
+// parseRoomSettings reads the settings posted from the config page
+func (c *Context) parseRoomSettings(r *http.Request) (config.RoomSettings, error) {
+	rs := config.RoomSettings{
+		Server:       r.PostFormValue("server"),
+		Projects:     splitList(r.PostFormValue("projects")),
+		Filters:      splitList(r.PostFormValue("filters")),
+		Branch:       strings.TrimSpace(r.PostFormValue("branch")),
+		Notify:       r.PostFormValue("notify"),
+		AllowedUsers: splitList(r.PostFormValue("allowedusers")),
+	}
+	if _, ok := c.providers[rs.Server]; rs.Server != "" && !ok {
+		return rs, fmt.Errorf("unknown CI server %q", rs.Server)
+	}
+	for _, p := range rs.Filters {
+		if _, err := path.Match(p, ""); err != nil {
+			return rs, fmt.Errorf("bad filter %q", p)
+		}
+	}
+	if rs.Notify == config.NotifyAll {
+		rs.Notify = ""
+	}
+	if rs.Notify != "" && rs.Notify != config.NotifyFailures && rs.Notify != config.NotifyNone {
+		return rs, fmt.Errorf("unknown notification setting %q", rs.Notify)
+	}
+	return rs, nil
+}
+
+// splitList splits a comma or space separated list
+func splitList(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mkobaly/hipchatBot/chat"
+	"github.com/mkobaly/hipchatBot/ci"
+	"github.com/mkobaly/hipchatBot/config"
+	"github.com/mkobaly/hipchatBot/store"
+)
+
+// replies records the replies to a command
+type replies []chat.Message
+
+func (r *replies) Reply(ctx context.Context, m chat.Message) error {
+	*r = append(*r, m)
+	return nil
+}
+
+// kickProvider is a provider that lists ids and records the builds kicked off.
+// Its builds keep running. With err set kicking off fails.
+type kickProvider struct {
+	ci.Provider
+	ids    []string
+	kicked []ci.BuildRequest
+	err    error
+}
+
+func (p *kickProvider) List(ctx context.Context) ([]string, error) {
+	return p.ids, nil
+}
+
+func (p *kickProvider) Trigger(ctx context.Context, req ci.BuildRequest) (*ci.Build, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	p.kicked = append(p.kicked, req)
+	return &ci.Build{ID: strconv.Itoa(len(p.kicked)), ConfigID: req.ConfigID, Branch: req.Branch, State: ci.StateQueued}, nil
+}
+
+func (p *kickProvider) Status(ctx context.Context, id string) (*ci.Build, error) {
+	b := &ci.Build{ID: id, State: ci.StateRunning}
+	if i, err := strconv.Atoi(id); err == nil && i >= 1 && i <= len(p.kicked) {
+		b.ConfigID = p.kicked[i-1].ConfigID
+	}
+	return b, nil
+}
+
+func settingsContext(t *testing.T) (*Context, *kickProvider) {
+	st, err := store.Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &kickProvider{ids: []string{"Web_CI", "Web_RC", "Api_CI"}}
+	return &Context{
+		rooms:     map[string]*RoomConfig{"4008322": {oauthID: "client-1", oauthSecret: "s3cret"}},
+		providers: map[string]ci.Provider{"default": p},
+		cfg:       &config.Config{DefaultServer: "default"},
+		health:    newRoomHealth(),
+		store:     st,
+		confirms:  newConfirmations(),
+		subs:      newSubscriptions(),
+	}, p
+}
+
+func TestConfigPage(t *testing.T) {
+	c, _ := settingsContext(t)
+	claims := map[string]interface{}{
+		"iss":     "client-1",
+		"exp":     time.Now().Add(time.Minute).Unix(),
+		"context": map[string]interface{}{"room_id": 4008322},
+	}
+	target := "/config?signed_request=" + signJWT(claims, "s3cret")
+	post := func(form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", target, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		c.config(w, req)
+		return w
+	}
+
+	w := post(url.Values{
+		"server":       {"default"},
+		"projects":     {"Web"},
+		"filters":      {"*_CI, *_RC"},
+		"branch":       {"develop"},
+		"notify":       {"failures"},
+		"allowedusers": {"@MichaelKobaly 123"},
+	})
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Settings saved") {
+		t.Fatalf("unexpected response %d %q", w.Code, w.Body.String())
+	}
+	want := config.RoomSettings{
+		Server:       "default",
+		Projects:     []string{"Web"},
+		Filters:      []string{"*_CI", "*_RC"},
+		Branch:       "develop",
+		Notify:       config.NotifyFailures,
+		AllowedUsers: []string{"@MichaelKobaly", "123"},
+	}
+	if rs, _ := c.store.Room("4008322"); !reflect.DeepEqual(rs, want) {
+		t.Errorf("saved %+v, want %+v", rs, want)
+	}
+
+	w = httptest.NewRecorder()
+	c.config(w, httptest.NewRequest("GET", target, nil))
+	if body := w.Body.String(); !strings.Contains(body, `value="*_CI, *_RC"`) || !strings.Contains(body, `value="failures" selected`) {
+		t.Errorf("saved settings not shown %q", body)
+	}
+
+	if w := post(url.Values{"server": {"nope"}}); !strings.Contains(w.Body.String(), "unknown CI server") {
+		t.Errorf("unknown server saved %q", w.Body.String())
+	}
+	if rs, _ := c.store.Room("4008322"); rs.Server != "default" {
+		t.Errorf("bad settings replaced the saved ones: %+v", rs)
+	}
+
+	w = httptest.NewRecorder()
+	c.config(w, httptest.NewRequest("POST", "/config?signed_request="+signJWT(claims, "wrong"), nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("bad signature got status %d", w.Code)
+	}
+}
+
+func TestConfigPageAfterRestart(t *testing.T) {
+	dir, err := ioutil.TempDir("", "state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	st, err := store.Open(filepath.Join(dir, "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := st.SetInstall(store.Install{RoomID: "4008322", OAuthID: "client-1", OAuthSecret: "s3cret"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// a fresh server only knows the installation from the store
+	st, err = store.Open(filepath.Join(dir, "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &Context{
+		rooms:     make(map[string]*RoomConfig),
+		providers: map[string]ci.Provider{"default": &kickProvider{}},
+		cfg:       &config.Config{DefaultServer: "default"},
+		health:    newRoomHealth(),
+		store:     st,
+	}
+	c.restoreInstalls()
+	claims := map[string]interface{}{
+		"iss":     "client-1",
+		"exp":     time.Now().Add(time.Minute).Unix(),
+		"context": map[string]interface{}{"room_id": 4008322},
+	}
+	w := httptest.NewRecorder()
+	c.routes().ServeHTTP(w, httptest.NewRequest("GET", "/config?signed_request="+signJWT(claims, "s3cret"), nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("config page after a restart got status %d", w.Code)
+	}
+}
+
+func TestRoomSettingsHonoured(t *testing.T) {
+	c, p := settingsContext(t)
+	c.store.SetRoom("4008322", config.RoomSettings{
+		Projects:     []string{"Web"},
+		Branch:       "develop",
+		AllowedUsers: []string{"@MichaelKobaly"},
+	})
+	ctx := context.Background()
+	run := func(text string, user chat.User) replies {
+		var r replies
+		c.dispatch(ctx, chat.Command{Text: text, User: user, RoomID: "4008322", Source: "HipChat"}, &r)
+		return r
+	}
+	michael := chat.User{ID: "4513556", Name: "Michael Kobaly", MentionName: "michaelkobaly"}
+
+	if r := run("/build list", chat.User{Name: "Someone", MentionName: "someone"}); len(r) != 1 || r[0].Color != chat.ColorRed {
+		t.Errorf("user not in allowed users got %+v", r)
+	}
+
+	r := run("/build list", michael)
+	if len(r) != 1 || !reflect.DeepEqual(r[0].Data.([]chat.ServerConfigs)[0].IDs, []string{"Web_CI", "Web_RC"}) {
+		t.Errorf("list did not honour projects: %+v", r)
+	}
+
+	run("/build kick Web_CI", michael)
+	if len(p.kicked) != 1 || p.kicked[0].Branch != "develop" {
+		t.Errorf("kick did not use the default branch: %+v", p.kicked)
+	}
+
+	if r := run("/build kick Api_CI master", michael); len(p.kicked) != 1 || len(r) != 1 || r[0].Color != chat.ColorYellow {
+		t.Errorf("kicked off a configuration the room can not see: %+v", r)
+	}
+
+	// build 2 is of Api_CI, kicked off from another room
+	p.kicked = append(p.kicked, ci.BuildRequest{ConfigID: "Api_CI", Branch: "master"})
+	if r := run("/build status 1", michael); len(r) != 1 || r[0].Template != "status" {
+		t.Errorf("can not get the status of a Web build: %+v", r)
+	}
+	for _, cmd := range []string{"status", "cancel", "log", "artifacts"} {
+		r := run("/build "+cmd+" 2", michael)
+		if len(r) != 1 || r[0].Text != "Sorry Michael Kobaly, builds of Api_CI can not be seen from this room" {
+			t.Errorf("can %v a build the room can not see: %+v", cmd, r)
+		}
+	}
+}
+
+func TestRoomNotifier(t *testing.T) {
+	var r replies
+	n := roomNotifier{Notifier: notifierFunc(r.Reply), settings: config.RoomSettings{Notify: config.NotifyFailures}}
+	ctx := context.Background()
+	n.Notify(ctx, chat.Message{Template: "finished", Data: &ci.Build{Status: ci.StatusSuccess}})
+	n.Notify(ctx, chat.Message{Template: "finished", Data: &ci.Build{Status: ci.StatusFailure}})
+	if len(r) != 1 || r[0].Data.(*ci.Build).Status != ci.StatusFailure {
+		t.Errorf("unexpected notifications %+v", r)
+	}
+}
+
+// notifierFunc turns a function into a chat.Notifier
+type notifierFunc func(context.Context, chat.Message) error
+
+func (f notifierFunc) Notify(ctx context.Context, m chat.Message) error {
+	return f(ctx, m)
+}
@@ -0,0 +1,189 @@
+package slack
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mkobaly/hipchatBot/chat"
+	"github.com/mkobaly/hipchatBot/ci"
+)
+
+// maxSectionText is the longest text Slack accepts in a section block
+const maxSectionText = 3000
+
+// text is a Block Kit text object
+type text struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func mrkdwn(s string) *text {
+	return &text{Type: "mrkdwn", Text: s}
+}
+
+// block is the subset of Block Kit layout blocks the replies use
+type block struct {
+	Type     string  `json:"type"`
+	Text     *text   `json:"text,omitempty"`
+	Fields   []*text `json:"fields,omitempty"`
+	Elements []*text `json:"elements,omitempty"`
+}
+
+func header(s string) block {
+	return block{Type: "header", Text: &text{Type: "plain_text", Text: s}}
+}
+
+func section(s string) block {
+	return block{Type: "section", Text: mrkdwn(s)}
+}
+
+func contextBlock(s string) block {
+	return block{Type: "context", Elements: []*text{mrkdwn(s)}}
+}
+
+// field is a bold label over its value in a section's fields
+func field(label string, value string) *text {
+	return mrkdwn("*" + label + "*\n" + escape(value))
+}
+
+// message is a chat.postMessage or response_url payload. Text is the
+// fallback shown in notifications.
+type message struct {
+	Channel      string  `json:"channel,omitempty"`
+	ResponseType string  `json:"response_type,omitempty"`
+	Text         string  `json:"text"`
+	Blocks       []block `json:"blocks,omitempty"`
+}
+
+// escape makes s safe to use as mrkdwn text
+func escape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// colorEmoji stands in for the message color, which blocks can not show
+var colorEmoji = map[string]string{
+	chat.ColorGreen:  ":large_green_circle:",
+	chat.ColorYellow: ":large_yellow_circle:",
+	chat.ColorRed:    ":red_circle:",
+	chat.ColorGray:   ":white_circle:",
+}
+
+// render lays out a reply. The list, kick and status replies have their own
+// blocks, the rest are a section of the mrkdwn text from fallback.
+func render(m chat.Message, fallback func(chat.Message) string) *message {
+	switch data := m.Data.(type) {
+	case []chat.ServerConfigs:
+		if m.Template == "list" {
+			return listBlocks(data)
+		}
+	case chat.KickReply:
+		if m.Template == "kick" {
+			return kickBlocks(data)
+		}
+	case *ci.Build:
+		if m.Template == "status" {
+			return statusBlocks(data, m.Color)
+		}
+	}
+	var s string
+	if m.Template == "" {
+		s = colorEmoji[m.Color] + " *" + escape(m.Text) + "*"
+	} else {
+		s = fallback(m)
+	}
+	msg := &message{Text: s}
+	for _, chunk := range split(s, maxSectionText) {
+		msg.Blocks = append(msg.Blocks, section(chunk))
+	}
+	return msg
+}
+
+func listBlocks(lists []chat.ServerConfigs) *message {
+	msg := &message{Text: "Available build configurations"}
+	msg.Blocks = append(msg.Blocks, header(msg.Text))
+	for _, l := range lists {
+		var lines []string
+		if l.Server != "" {
+			title := "*" + escape(l.Server) + "*"
+			if l.Error {
+				title += " _(unavailable)_"
+			}
+			lines = append(lines, title)
+		} else if l.Error {
+			lines = append(lines, "_(unavailable)_")
+		}
+		for _, id := range l.IDs {
+			lines = append(lines, "• "+escape(id))
+		}
+		if len(lines) == 0 {
+			lines = append(lines, "_No build configurations_")
+		}
+		for _, chunk := range split(strings.Join(lines, "\n"), maxSectionText) {
+			msg.Blocks = append(msg.Blocks, section(chunk))
+		}
+	}
+	return msg
+}
+
+func kickBlocks(k chat.KickReply) *message {
+	msg := &message{Text: k.Heading() + " for " + k.BuildConfigID}
+	fields := []*text{field("Build configuration", k.BuildConfigID)}
+	if k.Branch != "" {
+		msg.Text += " on " + k.Branch
+		fields = append(fields, field("Branch", k.Branch))
+	}
+	if k.Revision != "" {
+		fields = append(fields, field("Revision", k.Revision))
+	}
+	if k.Schedule != "" {
+		fields = append(fields, field("Schedule", k.Schedule))
+	}
+	status := "/build status " + k.TaskID
+	if k.Server != "" {
+		status += " --server " + k.Server
+	}
+	msg.Blocks = []block{
+		header(k.Heading()),
+		{Type: "section", Fields: fields},
+		contextBlock("Check on it with `" + escape(status) + "`"),
+	}
+	return msg
+}
+
+func statusBlocks(b *ci.Build, color string) *message {
+	msg := &message{Text: fmt.Sprintf("%s %s: %s %s", b.ConfigID, b.Branch, b.State, b.Status)}
+	msg.Blocks = []block{
+		header("Build result status"),
+		{Type: "section", Fields: []*text{
+			field("Build config", b.ConfigID),
+			field("Branch", b.Branch),
+			field("State", b.State),
+			mrkdwn("*Status*\n" + colorEmoji[color] + " " + escape(b.Status)),
+		}},
+	}
+	var notes []string
+	if b.StatusText != "" {
+		notes = append(notes, escape(b.StatusText))
+	}
+	if b.WebURL != "" {
+		notes = append(notes, "<"+b.WebURL+"|Open build>")
+	}
+	if len(notes) > 0 {
+		msg.Blocks = append(msg.Blocks, contextBlock(strings.Join(notes, " · ")))
+	}
+	return msg
+}
+
+// split cuts s into pieces of at most n bytes, at line ends where it can
+func split(s string, n int) []string {
+	var parts []string
+	for len(s) > n {
+		i := strings.LastIndex(s[:n], "\n")
+		if i <= 0 {
+			i = n
+		}
+		parts = append(parts, s[:i])
+		s = strings.TrimPrefix(s[i:], "\n")
+	}
+	return append(parts, s)
+}
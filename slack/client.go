@@ -0,0 +1,121 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	defaultURL     = "https://slack.com/api"
+	defaultTimeout = 30 * time.Second
+)
+
+// Error is returned when Slack refuses a call
+type Error struct {
+	Method  string
+	URL     string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("slack: %s %s: %s", e.Method, e.URL, e.Message)
+}
+
+// client posts replies to Slack, either to a slash command's response_url
+// or through the Web API with the bot token
+type client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+func newClient(token string) *client {
+	return &client{
+		baseURL: defaultURL,
+		token:   token,
+		http:    &http.Client{Timeout: defaultTimeout},
+	}
+}
+
+// apiResponse is the envelope of every Web API response
+type apiResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+// respond posts a message to a slash command's response_url
+func (c *client) respond(ctx context.Context, responseURL string, m *message) error {
+	resp, err := c.send(ctx, "POST", responseURL, "", m)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// call runs a Web API method. Methods that only read take their arguments
+// in query and are sent as a GET, the rest post in as JSON. The response is
+// decoded into out.
+func (c *client) call(ctx context.Context, method string, query url.Values, in interface{}, out interface{}) error {
+	httpMethod, u := "POST", c.baseURL+"/"+method
+	if in == nil {
+		httpMethod, u = "GET", u+"?"+query.Encode()
+	}
+	resp, err := c.send(ctx, httpMethod, u, c.token, in)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	var raw json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return err
+	}
+	var ar apiResponse
+	if err := json.Unmarshal(raw, &ar); err != nil {
+		return err
+	}
+	if !ar.OK {
+		return &Error{Method: httpMethod, URL: method, Message: ar.Error}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(raw, out)
+}
+
+func (c *client) send(ctx context.Context, method string, u string, token string, in interface{}) (*http.Response, error) {
+	var body bytes.Buffer
+	if in != nil {
+		if err := json.NewEncoder(&body).Encode(in); err != nil {
+			return nil, err
+		}
+	}
+	req, err := http.NewRequest(method, u, &body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		var b bytes.Buffer
+		b.ReadFrom(resp.Body)
+		resp.Body.Close()
+		return nil, &Error{Method: method, URL: u, Message: fmt.Sprintf("%d %s", resp.StatusCode, strings.TrimSpace(b.String()))}
+	}
+	return resp, nil
+}
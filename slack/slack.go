@@ -0,0 +1,260 @@
+// Package slack connects the bot to a Slack app. Commands arrive either as a
+// slash command or as an app mention through the Events API and are run by
+// the same dispatcher as the HipChat webhook.
+package slack
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mkobaly/hipchatBot/chat"
+	"github.com/mkobaly/hipchatBot/config"
+)
+
+const (
+	// maxSkew is how old a request may be before it is taken for a replay
+	maxSkew = 5 * time.Minute
+	// maxBody is the largest request body read from Slack
+	maxBody = 1 << 20
+)
+
+// ErrSignature is returned for requests that were not signed with the app's
+// signing secret
+var ErrSignature = errors.New("slack: invalid request signature")
+
+// Handler serves the slash command and Events API endpoints of a Slack app
+type Handler struct {
+	signingSecret string
+	dispatch      chat.Dispatcher
+	fallback      func(chat.Message) string
+	client        *client
+	now           func() time.Time
+}
+
+// New creates the Slack handler. Commands are passed to dispatch and replies
+// without a Block Kit layout of their own are shown as the mrkdwn returned by
+// fallback.
+func New(settings config.SlackSettings, dispatch chat.Dispatcher, fallback func(chat.Message) string) *Handler {
+	return &Handler{
+		signingSecret: settings.SigningSecret,
+		dispatch:      dispatch,
+		fallback:      fallback,
+		client:        newClient(settings.BotToken),
+		now:           time.Now,
+	}
+}
+
+// verify checks the request's v0 signature and timestamp, returning its body
+func (h *Handler) verify(r *http.Request) ([]byte, error) {
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxBody))
+	if err != nil {
+		return nil, err
+	}
+	if h.signingSecret == "" {
+		return nil, ErrSignature
+	}
+	ts := r.Header.Get("X-Slack-Request-Timestamp")
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return nil, ErrSignature
+	}
+	if skew := h.now().Sub(time.Unix(sec, 0)); skew > maxSkew || skew < -maxSkew {
+		return nil, ErrSignature
+	}
+	mac := hmac.New(sha256.New, []byte(h.signingSecret))
+	mac.Write([]byte("v0:" + ts + ":"))
+	mac.Write(body)
+	want := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(want), []byte(r.Header.Get("X-Slack-Signature"))) {
+		return nil, ErrSignature
+	}
+	return body, nil
+}
+
+// Command handles a slash command. Slack wants an answer within three
+// seconds so the command is acknowledged straight away and the replies go to
+// the command's response_url.
+func (h *Handler) Command(w http.ResponseWriter, r *http.Request) {
+	body, err := h.verify(r)
+	if err != nil {
+		log.Printf("Slack command rejected: %v", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	cmd := chat.Command{
+		Text: strings.TrimSpace(form.Get("command") + " " + form.Get("text")),
+		User: chat.User{
+			ID:          form.Get("user_id"),
+			Name:        form.Get("user_name"),
+			MentionName: form.Get("user_name"),
+		},
+		RoomID:   form.Get("channel_id"),
+		RoomName: form.Get("channel_name"),
+		Source:   "Slack",
+	}
+	rep := h.replier(form.Get("response_url"), cmd.RoomID)
+	go h.dispatch(context.Background(), cmd, rep)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(&message{ResponseType: "in_channel"})
+}
+
+// eventEnvelope is an Events API request
+type eventEnvelope struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Event     struct {
+		Type    string `json:"type"`
+		User    string `json:"user"`
+		BotID   string `json:"bot_id"`
+		Text    string `json:"text"`
+		Channel string `json:"channel"`
+	} `json:"event"`
+}
+
+// mention matches the "<@U123>" the message of an app mention starts with
+var mention = regexp.MustCompile(`^\s*<@[^>]+>\s*`)
+
+// Events handles the Events API. Apart from the URL verification handshake
+// only app mentions are used, "@bot kick Config branch" or "@bot build kick
+// Config branch" running "/build kick Config branch".
+func (h *Handler) Events(w http.ResponseWriter, r *http.Request) {
+	body, err := h.verify(r)
+	if err != nil {
+		log.Printf("Slack event rejected: %v", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	var env eventEnvelope
+	if err := json.Unmarshal(body, &env); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if env.Type == "url_verification" {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(env.Challenge))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	// Slack retries events it thinks were not delivered, the first delivery
+	// has already run the command
+	if r.Header.Get("X-Slack-Retry-Num") != "" {
+		return
+	}
+	ev := env.Event
+	if env.Type != "event_callback" || ev.Type != "app_mention" || ev.BotID != "" {
+		return
+	}
+	cmd := chat.Command{
+		Text:   commandText(ev.Text),
+		RoomID: ev.Channel,
+		Source: "Slack",
+	}
+	rep := h.replier("", ev.Channel)
+	go func() {
+		ctx := context.Background()
+		cmd.User = h.user(ctx, ev.User)
+		h.dispatch(ctx, cmd, rep)
+	}()
+}
+
+// commandText turns the text of an app mention into a "/build" command
+func commandText(s string) string {
+	words := strings.Fields(mention.ReplaceAllString(s, ""))
+	if len(words) > 0 && (words[0] == "build" || words[0] == "/build") {
+		words = words[1:]
+	}
+	return strings.Join(append([]string{"/build"}, words...), " ")
+}
+
+// user looks up the name of the Slack user id, falling back to the id when
+// the app can not read users
+func (h *Handler) user(ctx context.Context, id string) chat.User {
+	u := chat.User{ID: id, Name: id, MentionName: id}
+	var resp struct {
+		User struct {
+			Name     string `json:"name"`
+			RealName string `json:"real_name"`
+		} `json:"user"`
+	}
+	if err := h.client.call(ctx, "users.info", url.Values{"user": {id}}, nil, &resp); err != nil {
+		log.Printf("Error looking up Slack user %v: %v", id, err)
+		return u
+	}
+	u.MentionName = resp.User.Name
+	u.Name = resp.User.RealName
+	if u.Name == "" {
+		u.Name = u.MentionName
+	}
+	return u
+}
+
+// replier sends replies to a slash command's response_url, or to the channel
+// of an app mention
+type replier struct {
+	h           *Handler
+	responseURL string
+	channel     string
+}
+
+// replier returns the replier of a command sent in the channel. With a bot
+// token it is also a chat.Notifier, so the result of a kicked off build can
+// be posted once the response_url has expired.
+func (h *Handler) replier(responseURL string, channelID string) chat.Replier {
+	r := &replier{h: h, responseURL: responseURL, channel: channelID}
+	if h.client.token == "" {
+		return r
+	}
+	return notifier{r}
+}
+
+func (r *replier) Reply(ctx context.Context, m chat.Message) error {
+	msg := render(m, r.h.fallback)
+	if r.responseURL != "" {
+		msg.ResponseType = "in_channel"
+		return r.h.client.respond(ctx, r.responseURL, msg)
+	}
+	return r.post(ctx, msg)
+}
+
+// post sends msg to the channel through chat.postMessage with the bot token
+func (r *replier) post(ctx context.Context, msg *message) error {
+	msg.Channel = r.channel
+	return r.h.client.call(ctx, "chat.postMessage", nil, msg, nil)
+}
+
+// Notifier posts to the channel with the bot token, outside of any command.
+// It is nil when no bot token is set up.
+func (h *Handler) Notifier(channelID string, channelName string) chat.Notifier {
+	if h.client.token == "" {
+		return nil
+	}
+	return notifier{&replier{h: h, channel: channelID}}
+}
+
+// notifier is a replier that can also post to its channel by itself
+type notifier struct {
+	*replier
+}
+
+func (n notifier) Notify(ctx context.Context, m chat.Message) error {
+	return n.post(ctx, render(m, n.h.fallback))
+}
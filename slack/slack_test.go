@@ -0,0 +1,219 @@
+package slack
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mkobaly/hipchatBot/chat"
+	"github.com/mkobaly/hipchatBot/config"
+)
+
+const secret = "8f742231b10e8888abcd99yyyzzz85a5"
+
+// signed builds a request signed the way Slack signs them
+func signed(path string, body string, ts time.Time) *http.Request {
+	r := httptest.NewRequest("POST", path, strings.NewReader(body))
+	stamp := strconv.FormatInt(ts.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + stamp + ":" + body))
+	r.Header.Set("X-Slack-Request-Timestamp", stamp)
+	r.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return r
+}
+
+// recorder is a dispatcher that replies with the list and kick layouts and
+// keeps the replier of the last command
+type recorder struct {
+	cmds    chan chat.Command
+	replier chat.Replier
+}
+
+func (rec *recorder) dispatch(ctx context.Context, cmd chat.Command, r chat.Replier) {
+	r.Reply(ctx, chat.Message{
+		Template: "kick",
+		Data:     chat.KickReply{BuildConfigID: "Web_CI", Branch: "main", TaskID: "42"},
+		Color:    chat.ColorGreen,
+	})
+	rec.replier = r
+	rec.cmds <- cmd
+}
+
+func newTestHandler(t *testing.T) (*Handler, *recorder, chan *message, string) {
+	posted := make(chan *message, 2)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/users.info":
+			if r.Header.Get("Authorization") != "Bearer xoxb-test" || r.URL.Query().Get("user") != "U42" {
+				t.Errorf("unexpected users.info call %v", r.URL)
+			}
+			w.Write([]byte(`{"ok":true,"user":{"name":"ada","real_name":"Ada Lovelace"}}`))
+			return
+		case "/api/chat.postMessage", "/respond":
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var m message
+		json.NewDecoder(r.Body).Decode(&m)
+		posted <- &m
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	t.Cleanup(ts.Close)
+
+	rec := &recorder{cmds: make(chan chat.Command, 1)}
+	h := New(config.SlackSettings{SigningSecret: secret, BotToken: "xoxb-test"}, rec.dispatch, func(chat.Message) string { return "" })
+	h.client.baseURL = ts.URL + "/api"
+	return h, rec, posted, ts.URL
+}
+
+func wait(t *testing.T, rec *recorder, posted chan *message) (chat.Command, *message) {
+	select {
+	case cmd := <-rec.cmds:
+		return cmd, <-posted
+	case <-time.After(5 * time.Second):
+		t.Fatal("command was not dispatched")
+	}
+	return chat.Command{}, nil
+}
+
+func TestVerify(t *testing.T) {
+	h, _, _, _ := newTestHandler(t)
+	body := "command=%2Fbuild&text=list"
+
+	if _, err := h.verify(signed("/slack/command", body, time.Now())); err != nil {
+		t.Errorf("valid signature rejected: %v", err)
+	}
+	if _, err := h.verify(signed("/slack/command", body, time.Now().Add(-10*time.Minute))); err != ErrSignature {
+		t.Errorf("stale request accepted: %v", err)
+	}
+	r := signed("/slack/command", body, time.Now())
+	r.Header.Set("X-Slack-Signature", "v0=00")
+	if _, err := h.verify(r); err != ErrSignature {
+		t.Errorf("bad signature accepted: %v", err)
+	}
+	r = signed("/slack/command", body, time.Now())
+	r.Body = httptest.NewRequest("POST", "/", strings.NewReader(body+"&text=kick")).Body
+	if _, err := h.verify(r); err != ErrSignature {
+		t.Errorf("tampered body accepted: %v", err)
+	}
+}
+
+func TestCommand(t *testing.T) {
+	h, rec, posted, base := newTestHandler(t)
+	form := url.Values{
+		"command":      {"/build"},
+		"text":         {"kick Web_CI main"},
+		"user_id":      {"U42"},
+		"user_name":    {"ada"},
+		"channel_id":   {"C7"},
+		"response_url": {base + "/respond"},
+	}
+	w := httptest.NewRecorder()
+	h.Command(w, signed("/slack/command", form.Encode(), time.Now()))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d", w.Code)
+	}
+
+	cmd, m := wait(t, rec, posted)
+	if cmd.Text != "/build kick Web_CI main" || cmd.RoomID != "C7" || cmd.User.MentionName != "ada" || cmd.Source != "Slack" {
+		t.Errorf("unexpected command %+v", cmd)
+	}
+	if m.ResponseType != "in_channel" || len(m.Blocks) != 3 || m.Blocks[0].Type != "header" {
+		t.Errorf("unexpected reply %+v", m)
+	}
+	if got := m.Blocks[2].Elements[0].Text; !strings.Contains(got, "/build status 42") {
+		t.Errorf("kick reply missing status hint: %q", got)
+	}
+
+	// the build result is posted to the channel, not to the response_url
+	n, ok := rec.replier.(chat.Notifier)
+	if !ok {
+		t.Fatalf("slash command replier is not a chat.Notifier")
+	}
+	if err := n.Notify(context.Background(), chat.Message{Text: "Web_CI #12 failed", Color: chat.ColorRed}); err != nil {
+		t.Fatal(err)
+	}
+	if m := <-posted; m.Channel != "C7" || m.ResponseType != "" {
+		t.Errorf("unexpected notification %+v", m)
+	}
+
+	w = httptest.NewRecorder()
+	r := signed("/slack/command", form.Encode(), time.Now())
+	r.Header.Set("X-Slack-Signature", "v0=00")
+	h.Command(w, r)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("unsigned command got status %d", w.Code)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	h, rec, posted, _ := newTestHandler(t)
+
+	w := httptest.NewRecorder()
+	h.Events(w, signed("/slack/events", `{"type":"url_verification","challenge":"abc123"}`, time.Now()))
+	if w.Body.String() != "abc123" {
+		t.Errorf("challenge not echoed: %q", w.Body.String())
+	}
+
+	body := `{"type":"event_callback","event":{"type":"app_mention","user":"U42","channel":"C7","text":"<@UBOT> build kick Web_CI main"}}`
+	w = httptest.NewRecorder()
+	h.Events(w, signed("/slack/events", body, time.Now()))
+	cmd, m := wait(t, rec, posted)
+	if cmd.Text != "/build kick Web_CI main" || cmd.User.Name != "Ada Lovelace" || cmd.User.MentionName != "ada" {
+		t.Errorf("unexpected command %+v", cmd)
+	}
+	if m.Channel != "C7" || len(m.Blocks) == 0 {
+		t.Errorf("unexpected reply %+v", m)
+	}
+
+	// a retried delivery must not run the command twice
+	r := signed("/slack/events", body, time.Now())
+	r.Header.Set("X-Slack-Retry-Num", "1")
+	h.Events(httptest.NewRecorder(), r)
+	select {
+	case cmd := <-rec.cmds:
+		t.Errorf("retry dispatched %+v", cmd)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestNotifier(t *testing.T) {
+	h, _, posted, _ := newTestHandler(t)
+	n := h.Notifier("C7", "builds")
+	if err := n.Notify(context.Background(), chat.Message{Text: "Web_CI #12 failed", Color: chat.ColorRed}); err != nil {
+		t.Fatal(err)
+	}
+	if m := <-posted; m.Channel != "C7" {
+		t.Errorf("posted to %q", m.Channel)
+	}
+
+	h = New(config.SlackSettings{SigningSecret: secret}, nil, nil)
+	if h.Notifier("C7", "builds") != nil {
+		t.Errorf("notifier without a bot token")
+	}
+}
+
+func TestRender(t *testing.T) {
+	m := render(chat.Message{Template: "list", Data: []chat.ServerConfigs{
+		{Server: "main", IDs: []string{"Web_CI", "Api_RC"}},
+		{Server: "jenkins", Error: true},
+	}}, nil)
+	if len(m.Blocks) != 3 || m.Blocks[1].Text.Text != "*main*\n• Web_CI\n• Api_RC" || !strings.Contains(m.Blocks[2].Text.Text, "unavailable") {
+		t.Errorf("unexpected list blocks %+v", m.Blocks)
+	}
+
+	m = render(chat.Message{Text: "Error <getting> build list", Color: chat.ColorRed}, nil)
+	if m.Blocks[0].Text.Text != ":red_circle: *Error &lt;getting&gt; build list*" {
+		t.Errorf("unexpected notice %q", m.Blocks[0].Text.Text)
+	}
+}
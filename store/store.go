@@ -0,0 +1,265 @@
+// Package store saves the settings rooms change from chat in a JSON file so
+// they survive a restart of the bot
+package store
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/mkobaly/hipchatBot/config"
+)
+
+// Which finished builds of a watched configuration are posted
+const (
+	OnlyAll      = "all"
+	OnlyFailures = "failures"
+	OnlyChanges  = "changes"
+)
+
+// Watch subscribes a room to the finished builds of a build configuration on
+// Server. Source is the chat platform the room is on, RoomName its name
+// there. An empty Branch watches every branch.
+type Watch struct {
+	RoomID   string `json:"roomId"`
+	RoomName string `json:"roomName,omitempty"`
+	Source   string `json:"source"`
+	Server   string `json:"server"`
+	ConfigID string `json:"configId"`
+	Branch   string `json:"branch,omitempty"`
+	Only     string `json:"only"`
+}
+
+// Key identifies the watch within the store
+func (w Watch) Key() string {
+	return w.RoomID + "/" + w.Server + "/" + w.ConfigID + "/" + w.Branch
+}
+
+// Schedule kicks off a build of a configuration on Server at the times of
+// the cron expression Cron and posts it to the room. User is who scheduled
+// it.
+type Schedule struct {
+	ID       string `json:"id"`
+	RoomID   string `json:"roomId"`
+	RoomName string `json:"roomName,omitempty"`
+	Source   string `json:"source"`
+	Server   string `json:"server"`
+	ConfigID string `json:"configId"`
+	Branch   string `json:"branch"`
+	Cron     string `json:"cron"`
+	User     string `json:"user"`
+	// UserID and MentionName identify the user who added the schedule, whose
+	// role each run is checked against
+	UserID      string `json:"userId,omitempty"`
+	MentionName string `json:"mentionName,omitempty"`
+}
+
+// Install is the HipChat add-on installed in a room. OAuthID and OAuthSecret
+// are the installation's OAuth client, whose secret signs the requests of the
+// add-on's pages.
+type Install struct {
+	RoomID      string `json:"roomId"`
+	OAuthID     string `json:"oauthId"`
+	OAuthSecret string `json:"oauthSecret"`
+}
+
+// state is the content of the store's file
+type state struct {
+	Rooms     map[string]config.RoomSettings `json:"rooms"`
+	Installs  map[string]Install             `json:"installs,omitempty"`
+	Watches   []Watch                        `json:"watches,omitempty"`
+	Schedules []Schedule                     `json:"schedules,omitempty"`
+	// LastSchedule is the ID of the latest schedule added
+	LastSchedule int `json:"lastSchedule,omitempty"`
+}
+
+// Store holds the bot's saved state. A Store without a file only keeps it in
+// memory.
+type Store struct {
+	file  string
+	mu    sync.Mutex
+	state state
+}
+
+// Open loads the store saved in file. A missing file is an empty store.
+func Open(file string) (*Store, error) {
+	s := &Store{file: file}
+	if file != "" {
+		b, err := ioutil.ReadFile(file)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		if len(b) > 0 {
+			if err := json.Unmarshal(b, &s.state); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if s.state.Rooms == nil {
+		s.state.Rooms = make(map[string]config.RoomSettings)
+	}
+	if s.state.Installs == nil {
+		s.state.Installs = make(map[string]Install)
+	}
+	return s, nil
+}
+
+// Room returns the settings saved for the room
+func (s *Store) Room(roomID string) (config.RoomSettings, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rs, ok := s.state.Rooms[roomID]
+	return rs, ok
+}
+
+// SetRoom saves the room's settings
+func (s *Store) SetRoom(roomID string, rs config.RoomSettings) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.Rooms[roomID] = rs
+	return s.save()
+}
+
+// Installs returns the add-on's installations, by room
+func (s *Store) Installs() []Install {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var res []Install
+	for _, i := range s.state.Installs {
+		res = append(res, i)
+	}
+	sort.Slice(res, func(a, b int) bool { return res[a].RoomID < res[b].RoomID })
+	return res
+}
+
+// SetInstall saves the installation, replacing the one of its room
+func (s *Store) SetInstall(i Install) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.Installs[i.RoomID] = i
+	return s.save()
+}
+
+// Watches returns every room's watches
+func (s *Store) Watches() []Watch {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Watch(nil), s.state.Watches...)
+}
+
+// RoomWatches returns the watches of the room
+func (s *Store) RoomWatches(roomID string) []Watch {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var res []Watch
+	for _, w := range s.state.Watches {
+		if w.RoomID == roomID {
+			res = append(res, w)
+		}
+	}
+	return res
+}
+
+// AddWatch saves the watch, replacing the room's watch of the same
+// configuration and branch on the same server
+func (s *Store) AddWatch(w Watch) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, old := range s.state.Watches {
+		if old.Key() == w.Key() {
+			s.state.Watches[i] = w
+			return s.save()
+		}
+	}
+	s.state.Watches = append(s.state.Watches, w)
+	return s.save()
+}
+
+// RemoveWatch deletes the room's watch of the configuration and branch on
+// the server, reporting whether there was one
+func (s *Store) RemoveWatch(roomID string, server string, configID string, branch string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := Watch{RoomID: roomID, Server: server, ConfigID: configID, Branch: branch}.Key()
+	for i, w := range s.state.Watches {
+		if w.Key() == key {
+			s.state.Watches = append(s.state.Watches[:i:i], s.state.Watches[i+1:]...)
+			return true, s.save()
+		}
+	}
+	return false, nil
+}
+
+// Schedules returns every room's schedules
+func (s *Store) Schedules() []Schedule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Schedule(nil), s.state.Schedules...)
+}
+
+// RoomSchedules returns the schedules of the room
+func (s *Store) RoomSchedules(roomID string) []Schedule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var res []Schedule
+	for _, sc := range s.state.Schedules {
+		if sc.RoomID == roomID {
+			res = append(res, sc)
+		}
+	}
+	return res
+}
+
+// AddSchedule saves the schedule under a new ID, which it returns with
+func (s *Store) AddSchedule(sc Schedule) (Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.LastSchedule++
+	sc.ID = strconv.Itoa(s.state.LastSchedule)
+	s.state.Schedules = append(s.state.Schedules, sc)
+	return sc, s.save()
+}
+
+// RemoveSchedule deletes the room's schedule, reporting whether there was
+// one
+func (s *Store) RemoveSchedule(roomID string, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, sc := range s.state.Schedules {
+		if sc.RoomID == roomID && sc.ID == id {
+			s.state.Schedules = append(s.state.Schedules[:i:i], s.state.Schedules[i+1:]...)
+			return true, s.save()
+		}
+	}
+	return false, nil
+}
+
+// save writes the state to the store's file. It is written next to it first
+// so a crash never leaves half a file behind. The caller holds mu.
+func (s *Store) save() error {
+	if s.file == "" {
+		return nil
+	}
+	b, err := json.MarshalIndent(s.state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(s.file), filepath.Base(s.file)+".")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.file)
+}
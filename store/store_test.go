@@ -0,0 +1,144 @@
+package store
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/mkobaly/hipchatBot/config"
+)
+
+func TestStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "state.json")
+
+	s, err := Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.Room("4008322"); ok {
+		t.Errorf("new store has a room")
+	}
+	rs := config.RoomSettings{Server: "cloud", Projects: []string{"Web"}, Branch: "develop", Notify: config.NotifyFailures}
+	if err := s.SetRoom("4008322", rs); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := s.Room("4008322"); !ok || !reflect.DeepEqual(got, rs) {
+		t.Errorf("reopened store has %+v, want %+v", got, rs)
+	}
+
+	install := Install{RoomID: "4008322", OAuthID: "client-1", OAuthSecret: "s3cret"}
+	s.SetInstall(Install{RoomID: "4008322", OAuthID: "client-0", OAuthSecret: "old"})
+	if err := s.SetInstall(install); err != nil {
+		t.Fatal(err)
+	}
+	s, _ = Open(file)
+	if got := s.Installs(); len(got) != 1 || got[0] != install {
+		t.Errorf("reopened store has installs %+v, want %+v", got, install)
+	}
+
+	ioutil.WriteFile(file, []byte("{"), 0600)
+	if _, err := Open(file); err == nil {
+		t.Errorf("corrupt file opened")
+	}
+}
+
+func TestWatches(t *testing.T) {
+	dir, err := ioutil.TempDir("", "store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "state.json")
+
+	s, _ := Open(file)
+	s.AddWatch(Watch{RoomID: "1", ConfigID: "Web_CI", Only: OnlyAll})
+	s.AddWatch(Watch{RoomID: "1", ConfigID: "Web_CI", Branch: "develop", Only: OnlyAll})
+	s.AddWatch(Watch{RoomID: "2", ConfigID: "Web_CI", Only: OnlyAll})
+	s.AddWatch(Watch{RoomID: "1", ConfigID: "Web_CI", Only: OnlyFailures})
+	s.AddWatch(Watch{RoomID: "2", Server: "cloud", ConfigID: "Web_CI", Only: OnlyAll})
+
+	s, _ = Open(file)
+	if ws := s.Watches(); len(ws) != 4 {
+		t.Errorf("got watches %+v", ws)
+	}
+	ws := s.RoomWatches("1")
+	if len(ws) != 2 || ws[0].Only != OnlyFailures || ws[1].Branch != "develop" {
+		t.Errorf("got room watches %+v", ws)
+	}
+
+	if ok, err := s.RemoveWatch("1", "", "Web_CI", ""); !ok || err != nil {
+		t.Errorf("watch not removed: %v", err)
+	}
+	if ok, _ := s.RemoveWatch("1", "", "Web_CI", ""); ok {
+		t.Errorf("watch removed twice")
+	}
+	if ok, _ := s.RemoveWatch("2", "cloud", "Web_CI", ""); !ok {
+		t.Errorf("watch on cloud not removed")
+	}
+	s, _ = Open(file)
+	if ws := s.RoomWatches("1"); len(ws) != 1 || ws[0].Branch != "develop" {
+		t.Errorf("got room watches %+v after removal", ws)
+	}
+	if ws := s.RoomWatches("2"); len(ws) != 1 || ws[0].Server != "" {
+		t.Errorf("got room watches %+v after removal", ws)
+	}
+}
+
+func TestSchedules(t *testing.T) {
+	dir, err := ioutil.TempDir("", "store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "state.json")
+
+	s, _ := Open(file)
+	first, _ := s.AddSchedule(Schedule{RoomID: "1", ConfigID: "Web_CI", Branch: "feature/x", Cron: "0 2 * * *"})
+	s.AddSchedule(Schedule{RoomID: "2", ConfigID: "Web_CI", Branch: "main", Cron: "@daily"})
+	if first.ID != "1" {
+		t.Errorf("first schedule got ID %q", first.ID)
+	}
+	if ok, _ := s.RemoveSchedule("2", "1"); ok {
+		t.Errorf("removed another room's schedule")
+	}
+	if ok, err := s.RemoveSchedule("1", "1"); !ok || err != nil {
+		t.Errorf("schedule not removed: %v", err)
+	}
+
+	s, _ = Open(file)
+	third, _ := s.AddSchedule(Schedule{RoomID: "1", ConfigID: "Api_CI", Branch: "main", Cron: "@hourly"})
+	if third.ID != "3" {
+		t.Errorf("IDs reused: got %q", third.ID)
+	}
+	if scs := s.RoomSchedules("1"); len(scs) != 1 || scs[0].ConfigID != "Api_CI" {
+		t.Errorf("got room schedules %+v", scs)
+	}
+	if scs := s.Schedules(); len(scs) != 2 {
+		t.Errorf("got schedules %+v", scs)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	s, err := Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetRoom("1", config.RoomSettings{Branch: "main"}); err != nil {
+		t.Fatal(err)
+	}
+	if rs, _ := s.Room("1"); rs.Branch != "main" {
+		t.Errorf("got %+v", rs)
+	}
+}
@@ -0,0 +1,103 @@
+package teamcity
+
+//Build is a queued, running or finished TeamCity build
+type Build struct {
+	ID          int64       `json:"id,omitempty"`
+	BuildTypeID string      `json:"buildTypeId,omitempty"`
+	Number      string      `json:"number,omitempty"`
+	Status      string      `json:"status,omitempty"`
+	StatusText  string      `json:"statusText,omitempty"`
+	State       string      `json:"state,omitempty"`
+	BranchName  string      `json:"branchName,omitempty"`
+	HREF        string      `json:"href,omitempty"`
+	WebURL      string      `json:"webUrl,omitempty"`
+	QueuedDate  string      `json:"queuedDate,omitempty"`
+	StartDate   string      `json:"startDate,omitempty"`
+	FinishDate  string      `json:"finishDate,omitempty"`
+	Comment     *Comment    `json:"comment,omitempty"`
+	Properties  *Properties `json:"properties,omitempty"`
+}
+
+//BuildList is the response of the builds endpoint
+type BuildList struct {
+	Count int      `json:"count"`
+	Build []*Build `json:"build"`
+}
+
+//BuildType is a TeamCity build configuration
+type BuildType struct {
+	ID          string `json:"id"`
+	Name        string `json:"name,omitempty"`
+	ProjectID   string `json:"projectId,omitempty"`
+	ProjectName string `json:"projectName,omitempty"`
+	HREF        string `json:"href,omitempty"`
+	WebURL      string `json:"webUrl,omitempty"`
+}
+
+//BuildTypeList is the response of the buildTypes endpoint
+type BuildTypeList struct {
+	Count     int          `json:"count"`
+	BuildType []*BuildType `json:"buildType"`
+}
+
+//Property is a single build parameter
+type Property struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+//Properties is the list of parameters sent with or returned for a build
+type Properties struct {
+	Property []Property `json:"property"`
+}
+
+//Comment is the free text attached to a build
+type Comment struct {
+	Text string `json:"text"`
+}
+
+//Change points at a VCS change by TeamCity locator
+type Change struct {
+	Locator string `json:"locator"`
+}
+
+//LastChanges pins a queued build to specific changes
+type LastChanges struct {
+	Change []Change `json:"change"`
+}
+
+//BuildCancelRequest stops a queued or running build
+type BuildCancelRequest struct {
+	Comment        string `json:"comment"`
+	ReaddIntoQueue bool   `json:"readdIntoQueue"`
+}
+
+//BuildTypeRef references a build configuration by ID
+type BuildTypeRef struct {
+	ID string `json:"id"`
+}
+
+//QueueBuildRequest is the body posted to the buildQueue endpoint
+type QueueBuildRequest struct {
+	BuildType   BuildTypeRef `json:"buildType"`
+	BranchName  string       `json:"branchName,omitempty"`
+	Comment     *Comment     `json:"comment,omitempty"`
+	LastChanges *LastChanges `json:"lastChanges,omitempty"`
+	Properties  *Properties  `json:"properties,omitempty"`
+}
+
+//File is a single build artifact
+type File struct {
+	Name    string `json:"name"`
+	Size    int64  `json:"size,omitempty"`
+	HREF    string `json:"href,omitempty"`
+	Content *struct {
+		HREF string `json:"href"`
+	} `json:"content,omitempty"`
+}
+
+//FileList is the response of the artifacts endpoint
+type FileList struct {
+	Count int     `json:"count"`
+	File  []*File `json:"file"`
+}
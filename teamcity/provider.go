@@ -0,0 +1,165 @@
+package teamcity
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/mkobaly/hipchatBot/ci"
+)
+
+var _ ci.Provider = (*Builder)(nil)
+var _ ci.UserBuilds = (*Builder)(nil)
+var _ ci.Queue = (*Builder)(nil)
+var _ ci.History = (*Builder)(nil)
+
+//dateFormat is the layout of the dates in the REST API
+const dateFormat = "20060102T150405-0700"
+
+//toBuild converts a TeamCity build to the provider neutral form
+func toBuild(b *Build) *ci.Build {
+	res := &ci.Build{
+		ID:         strconv.FormatInt(b.ID, 10),
+		ConfigID:   b.BuildTypeID,
+		Branch:     b.BranchName,
+		Number:     b.Number,
+		State:      b.State,
+		Status:     b.Status,
+		StatusText: b.StatusText,
+		WebURL:     b.WebURL,
+	}
+	res.Started, _ = time.Parse(dateFormat, b.StartDate)
+	res.Ended, _ = time.Parse(dateFormat, b.FinishDate)
+	return res
+}
+
+//List returns the IDs of every build configuration on the server
+func (b *Builder) List(ctx context.Context) ([]string, error) {
+	types, err := b.GetBuilds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Sort(ById(types))
+	ids := make([]string, 0, len(types))
+	for _, t := range types {
+		ids = append(ids, t.ID)
+	}
+	return ids, nil
+}
+
+//Trigger queues req. The branch is also passed as the Branch build parameter
+//and the chat user is recorded as the ci.ChatUserParam build parameter so
+//BuildsTriggeredBy can find the build again.
+func (b *Builder) Trigger(ctx context.Context, req ci.BuildRequest) (*ci.Build, error) {
+	if b.ReadOnly() {
+		return nil, ErrReadOnly
+	}
+	params := map[string]string{"Branch": req.Branch}
+	for k, v := range req.Params {
+		params[k] = v
+	}
+	if req.User != "" {
+		params[ci.ChatUserParam] = req.User
+	}
+	bi := BuildInfo{
+		BuildConfigID: req.ConfigID,
+		Branch:        req.Branch,
+		Revision:      req.Revision,
+		Comment:       req.Comment,
+	}
+	br, err := b.queueBuild(ctx, bi, params)
+	if err != nil {
+		return nil, err
+	}
+	return toBuild(br), nil
+}
+
+//Status returns the queued, running or finished build for a queue task ID
+func (b *Builder) Status(ctx context.Context, id string) (*ci.Build, error) {
+	br, err := b.GetBuildStatus1(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return toBuild(&br), nil
+}
+
+//Cancel removes a queued build from the queue or stops a running one
+func (b *Builder) Cancel(ctx context.Context, id string, comment string) error {
+	if b.ReadOnly() {
+		return ErrReadOnly
+	}
+	br, err := b.GetBuildStatus1(ctx, id)
+	if err != nil {
+		return err
+	}
+	path := fmt.Sprintf("builds/id:%d", br.ID)
+	if br.State == ci.StateQueued {
+		path = fmt.Sprintf("buildQueue/id:%d", br.ID)
+	}
+	return b.client.post(ctx, path, BuildCancelRequest{Comment: comment}, nil)
+}
+
+//Log returns the full build log
+func (b *Builder) Log(ctx context.Context, id string) (string, error) {
+	return b.reader.getText(ctx, b.reader.authPrefix()+"/downloadBuildLog.html?buildId="+url.QueryEscape(id))
+}
+
+//Artifacts lists the files published at the root of the build's artifacts
+func (b *Builder) Artifacts(ctx context.Context, id string) ([]ci.Artifact, error) {
+	var files FileList
+	if err := b.reader.get(ctx, "builds/id:"+url.PathEscape(id)+"/artifacts/children", &files); err != nil {
+		return nil, err
+	}
+	var artifacts []ci.Artifact
+	for _, f := range files.File {
+		a := ci.Artifact{Name: f.Name, Size: f.Size}
+		if f.Content != nil {
+			a.URL = b.reader.url(f.Content.HREF)
+		}
+		artifacts = append(artifacts, a)
+	}
+	return artifacts, nil
+}
+
+//Queued returns the builds in the build queue
+func (b *Builder) Queued(ctx context.Context) ([]*ci.Build, error) {
+	builds, err := b.GetBuildQueue(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var res []*ci.Build
+	for _, br := range builds {
+		res = append(res, toBuild(br))
+	}
+	return res, nil
+}
+
+//FinishedBuilds returns the latest finished builds of the configuration,
+//newest first
+func (b *Builder) FinishedBuilds(ctx context.Context, configID string, branch string, count int) ([]*ci.Build, error) {
+	builds, err := b.GetFinishedBuilds(ctx, configID, branch, count)
+	if err != nil {
+		return nil, err
+	}
+	var res []*ci.Build
+	for _, br := range builds {
+		res = append(res, toBuild(br))
+	}
+	return res, nil
+}
+
+//BuildsTriggeredBy returns the most recent builds queued on behalf of user
+func (b *Builder) BuildsTriggeredBy(ctx context.Context, user string, count int) ([]*ci.Build, error) {
+	builds, err := b.GetBuildsTriggeredBy(ctx, user, count)
+	if err != nil {
+		return nil, err
+	}
+	var res []*ci.Build
+	for _, br := range builds {
+		res = append(res, toBuild(br))
+	}
+	return res, nil
+}
@@ -0,0 +1,121 @@
+package teamcity
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/mkobaly/hipchatBot/ci"
+	"github.com/mkobaly/hipchatBot/config"
+)
+
+//Error is returned for every non 2xx response from TeamCity
+type Error = ci.Error
+
+//restClient talks JSON to the TeamCity REST API
+type restClient struct {
+	*ci.Client
+	baseURL string
+	creds   config.UserCredential
+}
+
+func newRestClient(creds config.UserCredential) *restClient {
+	c := &restClient{
+		Client:  ci.NewClient("teamcity"),
+		baseURL: strings.TrimRight(creds.URL, "/"),
+		creds:   creds,
+	}
+	c.Prepare = c.authorize
+	c.ErrorMessage = errorMessage
+	return c
+}
+
+//authPrefix is the URL prefix for the kind of login in use. Token auth goes
+//to the plain server URLs, basic auth and guest access have their own prefix.
+func (c *restClient) authPrefix() string {
+	switch {
+	case c.creds.Token != "":
+		return ""
+	case c.creds.Guest:
+		return "/guestAuth"
+	}
+	return "/httpAuth"
+}
+
+//restRoot is the REST API root for the kind of login in use
+func (c *restClient) restRoot() string {
+	return c.authPrefix() + "/app/rest/"
+}
+
+//url resolves path against the server. Paths starting with "/" are the href
+//values TeamCity hands back; those are moved onto our own REST root since
+//they carry the auth prefix of whoever fetched them. Anything else is
+//relative to the REST root.
+func (c *restClient) url(path string) string {
+	if !strings.HasPrefix(path, "/") {
+		return c.baseURL + c.restRoot() + path
+	}
+	p := strings.TrimPrefix(strings.TrimPrefix(path, "/httpAuth"), "/guestAuth")
+	if strings.HasPrefix(p, "/app/rest/") {
+		return c.baseURL + c.restRoot() + strings.TrimPrefix(p, "/app/rest/")
+	}
+	return c.baseURL + path
+}
+
+func (c *restClient) get(ctx context.Context, path string, out interface{}) error {
+	return c.JSON(ctx, "GET", c.url(path), nil, out)
+}
+
+//post sends in to TeamCity. ci.Client only retries it when TeamCity can not
+//have handled it, so a build is not queued twice.
+func (c *restClient) post(ctx context.Context, path string, in, out interface{}) error {
+	return c.JSON(ctx, "POST", c.url(path), in, out)
+}
+
+//getText fetches a plain text page outside the REST API, such as a build log
+func (c *restClient) getText(ctx context.Context, path string) (string, error) {
+	var text bytes.Buffer
+	err := c.get(ctx, path, &text)
+	return text.String(), err
+}
+
+//authorize adds the credentials in use to a request
+func (c *restClient) authorize(req *http.Request) {
+	switch {
+	case c.creds.Token != "":
+		req.Header.Set("Authorization", "Bearer "+c.creds.Token)
+	case !c.creds.Guest:
+		req.SetBasicAuth(c.creds.Username, c.creds.Password)
+	}
+}
+
+//errorMessage pulls the useful part out of a TeamCity error body. Older
+//servers answer in plain text with a "Details:" line, newer ones in JSON.
+func errorMessage(resp *http.Response, body []byte) string {
+	var j struct {
+		Message string `json:"message"`
+		Errors  []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if json.Unmarshal(body, &j) == nil {
+		if j.Message != "" {
+			return j.Message
+		}
+		if len(j.Errors) > 0 {
+			return j.Errors[0].Message
+		}
+	}
+	text := strings.TrimSpace(string(body))
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(line, "Details: ") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "Details: "))
+		}
+	}
+	if i := strings.Index(text, "\n"); i >= 0 {
+		text = text[:i]
+	}
+	return strings.TrimSpace(text)
+}
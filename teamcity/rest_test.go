@@ -0,0 +1,275 @@
+package teamcity
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mkobaly/hipchatBot/ci"
+	"github.com/mkobaly/hipchatBot/config"
+)
+
+func newTestBuilder(t *testing.T, h http.HandlerFunc) *Builder {
+	ts := httptest.NewServer(h)
+	t.Cleanup(ts.Close)
+	b := New(config.UserCredential{URL: ts.URL + "/", Username: "bot", Password: "secret"})
+	b.client.Backoff = time.Millisecond
+	b.reader.Backoff = time.Millisecond
+	return b
+}
+
+func TestGetRetriesOn5xx(t *testing.T) {
+	calls := 0
+	b := newTestBuilder(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path != "/httpAuth/app/rest/buildQueue/taskId:42" {
+			t.Errorf("unexpected path %v", r.URL.Path)
+		}
+		if u, p, ok := r.BasicAuth(); !ok || u != "bot" || p != "secret" {
+			t.Errorf("missing basic auth")
+		}
+		if calls < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"id":42,"buildTypeId":"Web_CI","state":"running","branchName":"master"}`))
+	})
+
+	br, err := b.GetBuildStatus1(context.Background(), "42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 calls got %v", calls)
+	}
+	if br.BuildTypeID != "Web_CI" || br.State != "running" {
+		t.Errorf("unexpected build %+v", br)
+	}
+}
+
+func TestPostIsNotRetriedOn500(t *testing.T) {
+	calls := 0
+	b := newTestBuilder(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	b.SetBuildInfo(BuildInfo{BuildConfigID: "Web_CI", Branch: "master"})
+
+	if err := b.Build(context.Background(), nil); err == nil {
+		t.Fatal("expected error")
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 call got %v", calls)
+	}
+}
+
+func TestPostIsNotRetriedOnceSent(t *testing.T) {
+	for name, h := range map[string]http.HandlerFunc{
+		"bad gateway": func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		},
+		"gateway timeout": func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusGatewayTimeout)
+		},
+		"client timeout": func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(50 * time.Millisecond)
+		},
+	} {
+		//the handler may still be running when the client gives up
+		h := h
+		var calls int32
+		b := newTestBuilder(t, func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			h(w, r)
+		})
+		b.client.HTTP.Timeout = 10 * time.Millisecond
+		b.SetBuildInfo(BuildInfo{BuildConfigID: "Web_CI", Branch: "master"})
+		if err := b.Build(context.Background(), nil); err == nil {
+			t.Errorf("%v: expected error", name)
+		}
+		if n := atomic.LoadInt32(&calls); n != 1 {
+			t.Errorf("%v: expected 1 call got %v", name, n)
+		}
+	}
+}
+
+func TestPostIsRetriedBeforeReachingTeamCity(t *testing.T) {
+	calls := 0
+	b := newTestBuilder(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"id":42,"buildTypeId":"Web_CI","state":"queued"}`))
+	})
+	b.SetBuildInfo(BuildInfo{BuildConfigID: "Web_CI", Branch: "master"})
+	if err := b.Build(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 calls got %v", calls)
+	}
+}
+
+func TestTokenAndReadOnlyCredentials(t *testing.T) {
+	var got []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Method+" "+r.URL.Path+" "+r.Header.Get("Authorization"))
+		w.Write([]byte(`{"id":7,"href":"/app/rest/buildQueue/id:7"}`))
+	}))
+	defer ts.Close()
+
+	b := New(config.UserCredential{
+		URL:      ts.URL,
+		Token:    "write",
+		ReadOnly: &config.UserCredential{Guest: true},
+	})
+	b.SetBuildInfo(BuildInfo{BuildConfigID: "Web_CI", Branch: "master"})
+	if err := b.Build(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.VerifyBuildStatus(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"POST /app/rest/buildQueue Bearer write",
+		"GET /guestAuth/app/rest/buildQueue/id:7 ",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("request %d: expected %q got %q", i, want[i], got[i])
+		}
+	}
+}
+
+func TestGuestIsReadOnly(t *testing.T) {
+	b := New(config.UserCredential{URL: "http://teamcity", Guest: true})
+	b.SetBuildInfo(BuildInfo{BuildConfigID: "Web_CI", Branch: "master"})
+	if err := b.Build(context.Background(), nil); err != ErrReadOnly {
+		t.Errorf("expected ErrReadOnly got %v", err)
+	}
+}
+
+func TestFinishedBuilds(t *testing.T) {
+	var locators []string
+	b := newTestBuilder(t, func(w http.ResponseWriter, r *http.Request) {
+		locators = append(locators, r.URL.Query().Get("locator"))
+		w.Write([]byte(`{"build":[{"id":12,"buildTypeId":"Web_CI","state":"finished","status":"FAILURE","branchName":"develop"}]}`))
+	})
+
+	builds, err := b.FinishedBuilds(context.Background(), "Web_CI", "develop", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(builds) != 1 || builds[0].ID != "12" || builds[0].Status != "FAILURE" {
+		t.Errorf("unexpected builds %+v", builds)
+	}
+	b.FinishedBuilds(context.Background(), "Web_CI", "", 5)
+	b.FinishedBuilds(context.Background(), "Web_CI", "feature/a,b:(c)", 5)
+	b.FinishedBuilds(context.Background(), "Web_CI),count:(1", "", 5)
+	want := []string{
+		"buildType:(id:$base64:V2ViX0NJ),branch:(name:$base64:ZGV2ZWxvcA==),state:finished,canceled:any,count:5",
+		"buildType:(id:$base64:V2ViX0NJ),branch:(default:any),state:finished,canceled:any,count:5",
+		"buildType:(id:$base64:V2ViX0NJ),branch:(name:$base64:" + base64.URLEncoding.EncodeToString([]byte("feature/a,b:(c)")) + "),state:finished,canceled:any,count:5",
+		"buildType:(id:$base64:" + base64.URLEncoding.EncodeToString([]byte("Web_CI),count:(1")) + "),branch:(default:any),state:finished,canceled:any,count:5",
+	}
+	if len(locators) != 4 || locators[0] != want[0] || locators[1] != want[1] || locators[2] != want[2] || locators[3] != want[3] {
+		t.Errorf("expected locators %v got %v", want, locators)
+	}
+}
+
+func TestTriggerRecordsChatUser(t *testing.T) {
+	for _, tc := range []struct {
+		user    string
+		comment string
+	}{
+		{"Ada Lovelace (@ada)", "Triggered from HipChat by Ada Lovelace (@ada)"},
+		{"O'Brien, Pat (@pat)", "Triggered from Slack by O'Brien, Pat (@pat)"},
+		{"", ""},
+	} {
+		var qb QueueBuildRequest
+		b := newTestBuilder(t, func(w http.ResponseWriter, r *http.Request) {
+			json.NewDecoder(r.Body).Decode(&qb)
+			w.Write([]byte(`{"id":7,"buildTypeId":"Web_CI","state":"queued"}`))
+		})
+		_, err := b.Trigger(context.Background(), ci.BuildRequest{ConfigID: "Web_CI", Branch: "main", Comment: tc.comment, User: tc.user})
+		if err != nil {
+			t.Fatal(err)
+		}
+		comment := ""
+		if qb.Comment != nil {
+			comment = qb.Comment.Text
+		}
+		if comment != tc.comment {
+			t.Errorf("expected comment %q got %q", tc.comment, comment)
+		}
+		user, found := "", false
+		for _, p := range qb.Properties.Property {
+			if p.Name == ci.ChatUserParam {
+				user, found = p.Value, true
+			}
+		}
+		if user != tc.user || found != (tc.user != "") {
+			t.Errorf("expected %v %q got %q", ci.ChatUserParam, tc.user, user)
+		}
+	}
+}
+
+func TestBuildsTriggeredBy(t *testing.T) {
+	for _, user := range []string{
+		"Ada Lovelace (@ada)",
+		"O'Brien, Pat (@pat)",
+		"a:b),count:1000 (@x)",
+	} {
+		var locator string
+		b := newTestBuilder(t, func(w http.ResponseWriter, r *http.Request) {
+			locator = r.URL.Query().Get("locator")
+			w.Write([]byte(`{"build":[{"id":12,"buildTypeId":"Web_CI","state":"finished","status":"SUCCESS"}]}`))
+		})
+		builds, err := b.BuildsTriggeredBy(context.Background(), user, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(builds) != 1 || builds[0].ID != "12" {
+			t.Errorf("unexpected builds %+v", builds)
+		}
+		value := "$base64:" + base64.URLEncoding.EncodeToString([]byte(user))
+		want := "property:(name:" + ci.ChatUserParam + ",value:" + value + ",matchType:equals),running:any,canceled:any,count:10"
+		if locator != want {
+			t.Errorf("expected locator %q got %q", want, locator)
+		}
+	}
+}
+
+func TestErrorDecoding(t *testing.T) {
+	b := newTestBuilder(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("Responding with error, status code: 404 (Not Found).\n" +
+			"Details: jetbrains.buildServer.server.rest.errors.NotFoundException: No build types found by locator 'Nope'.\n" +
+			"Could not find the entity requested."))
+	})
+
+	_, err := b.GetLastestBuild(context.Background(), "Nope")
+	e, ok := err.(*Error)
+	if !ok {
+		t.Fatalf("expected *Error got %v", err)
+	}
+	if e.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 got %v", e.StatusCode)
+	}
+	want := "jetbrains.buildServer.server.rest.errors.NotFoundException: No build types found by locator 'Nope'."
+	if e.Message != want {
+		t.Errorf("unexpected message %q", e.Message)
+	}
+}
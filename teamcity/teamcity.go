@@ -1,40 +1,41 @@
 package teamcity
 
 import (
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"net/http"
-	"strconv"
+	"net/url"
 	"strings"
 
+	"github.com/mkobaly/hipchatBot/ci"
 	"github.com/mkobaly/hipchatBot/config"
-	"github.com/mkobaly/teamcity"
 )
 
 //BuildInfo represents a Build Configuration and branch in Teamcity
 type BuildInfo struct {
 	BuildConfigID string
 	Branch        string
+	Revision      string
+	Comment       string
 }
 
-type IBuilder interface {
-	//Build(id string, branch string) error
-	Build() error
-	Done() <-chan struct{}
-	Result() interface{}
-}
+//ErrReadOnly is returned when a build is requested but only read access to
+//TeamCity is configured
+var ErrReadOnly = ci.ErrReadOnly
 
 type Builder struct {
 	Credentials config.UserCredential
 	BuildInfo   BuildInfo
 	// buildID     string
 	// branch      string
-	client      *teamcity.Client
-	BuildResult *teamcity.Build
+	client      *restClient
+	reader      *restClient
+	BuildResult *Build
 }
 
-type ById []*teamcity.BuildType
+type ById []*BuildType
 
 func (a ById) Len() int           { return len(a) }
 func (a ById) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
@@ -44,24 +45,40 @@ func (a ById) Less(i, j int) bool { return a[i].ID < a[j].ID }
 func New(creds config.UserCredential) *Builder {
 	var b = new(Builder)
 	b.Credentials = creds
-	b.client = teamcity.New(creds.URL, creds.Username, creds.Password)
+	b.client = newRestClient(creds)
+	b.reader = b.client
+	if creds.ReadOnly != nil {
+		ro := *creds.ReadOnly
+		if ro.URL == "" {
+			ro.URL = creds.URL
+		}
+		b.reader = newRestClient(ro)
+	}
 	return b
 }
 
+//ReadOnly reports whether the builder has no credential allowed to start builds
+func (b *Builder) ReadOnly() bool {
+	c := b.Credentials
+	return c.Guest || (c.Token == "" && c.Username == "")
+}
+
 func (b *Builder) SetBuildInfo(bi BuildInfo) error {
 	b.BuildInfo = bi
-	b.BuildResult = new(teamcity.Build)
+	b.BuildResult = new(Build)
 	return nil
 }
 
 //Build will kick off a TeamCity build
-func (b *Builder) Build(params map[string]string) error {
+func (b *Builder) Build(ctx context.Context, params map[string]string) error {
 	if (BuildInfo{}) == b.BuildInfo {
 		return errors.New("Build Info not set yet so unable to build")
 	}
+	if b.ReadOnly() {
+		return ErrReadOnly
+	}
 
-	//client := teamcity.New(b.Credentials.URL, b.Credentials.Username, b.Credentials.Password)
-	x, err := b.client.QueueBuild(b.BuildInfo.BuildConfigID, b.BuildInfo.Branch, params)
+	x, err := b.queueBuild(ctx, b.BuildInfo, params)
 	if err != nil {
 		return err
 	}
@@ -69,131 +86,141 @@ func (b *Builder) Build(params map[string]string) error {
 	return nil
 }
 
+//queueBuild will add bi to the TeamCity build queue. A revision is sent as
+//the lastChanges of the queued build so it gets built at that commit
+func (b *Builder) queueBuild(ctx context.Context, bi BuildInfo, params map[string]string) (*Build, error) {
+	qb := QueueBuildRequest{
+		BuildType:  BuildTypeRef{ID: bi.BuildConfigID},
+		BranchName: bi.Branch,
+	}
+	if bi.Comment != "" {
+		qb.Comment = &Comment{Text: bi.Comment}
+	}
+	if bi.Revision != "" {
+		qb.LastChanges = &LastChanges{Change: []Change{{
+			Locator: fmt.Sprintf("version:%s,buildType:(id:%s)", bi.Revision, bi.BuildConfigID),
+		}}}
+	}
+	if len(params) > 0 {
+		qb.Properties = new(Properties)
+		for k, v := range params {
+			qb.Properties.Property = append(qb.Properties.Property, Property{Name: k, Value: v})
+		}
+	}
+
+	br := new(Build)
+	if err := b.client.post(ctx, "buildQueue", qb, br); err != nil {
+		return nil, err
+	}
+	return br, nil
+}
+
 func (b *Builder) BuildResultToJson() string {
 	r, _ := json.MarshalIndent(b.BuildResult, "", "\t")
 	return string(r)
 }
 
 // //GetBuild will return the current state of the build
-func (b *Builder) GetBuild() error {
-	br, err := b.client.GetBuild(strconv.FormatInt(b.BuildResult.ID, 10))
-	b.BuildResult = br
-	return err
-}
-
-//VerifyBuildStatus will return the current state of the build
-func (b *Builder) VerifyBuildStatus() error {
-	client := &http.Client{}
-	req, _ := http.NewRequest("GET", b.Credentials.URL+b.BuildResult.HREF, nil)
-	req.SetBasicAuth(b.Credentials.Username, b.Credentials.Password)
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := client.Do(req)
-	defer resp.Body.Close()
-
-	if err != nil {
-		panic(err.Error())
-	}
-	if err := json.NewDecoder(resp.Body).Decode(b.BuildResult); err != nil {
+func (b *Builder) GetBuild(ctx context.Context) error {
+	br := new(Build)
+	if err := b.reader.get(ctx, fmt.Sprintf("builds/id:%d", b.BuildResult.ID), br); err != nil {
 		return err
 	}
+	b.BuildResult = br
 	return nil
 }
 
-func (b *Builder) GetBuildStatus1(taskId string) (teamcity.Build, error) {
-	client := &http.Client{}
-	req, _ := http.NewRequest("GET", b.Credentials.URL+"httpAuth/app/rest/buildQueue/taskId:"+taskId, nil)
-	req.SetBasicAuth(b.Credentials.Username, b.Credentials.Password)
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := client.Do(req)
-	defer resp.Body.Close()
+//VerifyBuildStatus will return the current state of the build
+func (b *Builder) VerifyBuildStatus(ctx context.Context) error {
+	return b.GetBuildStatus(ctx, b.BuildResult)
+}
 
-	if err != nil {
-		panic(err.Error())
-	}
-	var br teamcity.Build
-	err = json.NewDecoder(resp.Body).Decode(&br)
+//GetBuildStatus1 will return the queued or running build for taskId
+func (b *Builder) GetBuildStatus1(ctx context.Context, taskId string) (Build, error) {
+	var br Build
+	err := b.reader.get(ctx, "buildQueue/taskId:"+url.PathEscape(taskId), &br)
 	return br, err
-
 }
 
-func (b *Builder) GetBuildStatus(br *teamcity.Build) error {
-	client := &http.Client{}
-	req, _ := http.NewRequest("GET", b.Credentials.URL+br.HREF, nil)
-	req.SetBasicAuth(b.Credentials.Username, b.Credentials.Password)
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := client.Do(req)
-	defer resp.Body.Close()
-
-	if err != nil {
-		panic(err.Error())
+//GetBuildStatus will refresh br from its href
+func (b *Builder) GetBuildStatus(ctx context.Context, br *Build) error {
+	if br.HREF == "" {
+		return errors.New("Build has no href to refresh from")
 	}
-	if err := json.NewDecoder(resp.Body).Decode(br); err != nil {
-		return err
-	}
-	return nil
+	return b.reader.get(ctx, br.HREF, br)
 }
 
 //GetArtifactVersion will return the version number of the build artifact
-func (b *Builder) GetArtifactVersion() (string, error) {
-	client := teamcity.New(b.Credentials.URL, b.Credentials.Username, b.Credentials.Password)
-	version, err := client.GetArtifact(b.BuildResult.ID)
-	if err != nil {
-		var s = strings.Replace(version.Name, ".zip", "", 1)
-		var parts = strings.Split(s, ".v")
-		if len(parts) == 2 {
-			return parts[1], nil
-		}
-	}
-	return "", err
+func (b *Builder) GetArtifactVersion(ctx context.Context) (string, error) {
+	return b.GetArtifactVersionByID(ctx, b.BuildResult.ID)
 }
 
 //GetArtifactVersionByID will return the version number of the build artifact
-func (b *Builder) GetArtifactVersionByID(id int64) (string, error) {
-	client := teamcity.New(b.Credentials.URL, b.Credentials.Username, b.Credentials.Password)
-	version, err := client.GetArtifact(id)
-	if err != nil {
-		var s = strings.Replace(version.Name, ".zip", "", 1)
-		var parts = strings.Split(s, ".v")
-		if len(parts) == 2 {
-			return parts[1], nil
-		}
+func (b *Builder) GetArtifactVersionByID(ctx context.Context, id int64) (string, error) {
+	var files FileList
+	if err := b.reader.get(ctx, fmt.Sprintf("builds/id:%d/artifacts/children", id), &files); err != nil {
+		return "", err
+	}
+	if len(files.File) == 0 {
+		return "", fmt.Errorf("Build %d has no artifacts", id)
+	}
+	var s = strings.Replace(files.File[0].Name, ".zip", "", 1)
+	var parts = strings.Split(s, ".v")
+	if len(parts) == 2 {
+		return parts[1], nil
 	}
-	return "", err
+	return "", nil
 }
 
 //GetBuilds will list out all available builds on TeamCity
-func (b *Builder) GetBuilds() ([]*teamcity.BuildType, error) {
-	//client := teamcity.New(b.Credentials.URL, b.Credentials.Username, b.Credentials.Password)
-	builds, err := b.client.GetBuildTypes()
-	return builds, err
+func (b *Builder) GetBuilds(ctx context.Context) ([]*BuildType, error) {
+	var list BuildTypeList
+	err := b.reader.get(ctx, "buildTypes", &list)
+	return list.BuildType, err
 }
 
-//VerifyBuildStatus will return the current state of the build
-func (b *Builder) GetLastestBuild(buildType string) (string, error) {
-	b.BuildResult = new(teamcity.Build)
-	client := &http.Client{}
-	var url = fmt.Sprintf("%s/httpAuth/app/rest/buildTypes/id:%s/builds/running:false,status:success", b.Credentials.URL, buildType)
-	req, _ := http.NewRequest("GET", url, nil)
-	req.SetBasicAuth(b.Credentials.Username, b.Credentials.Password)
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := client.Do(req)
-	defer resp.Body.Close()
+//locatorValue escapes a value for a TeamCity locator. Chat users and branch
+//names can hold the commas, colons and parentheses locators are built from.
+func locatorValue(s string) string {
+	return "$base64:" + base64.URLEncoding.EncodeToString([]byte(s))
+}
 
-	if err != nil {
-		panic(err.Error())
+//GetBuildsTriggeredBy will list the most recent builds queued on behalf of chatUser
+func (b *Builder) GetBuildsTriggeredBy(ctx context.Context, chatUser string, count int) ([]*Build, error) {
+	locator := fmt.Sprintf("property:(name:%s,value:%s,matchType:equals),running:any,canceled:any,count:%d",
+		ci.ChatUserParam, locatorValue(chatUser), count)
+	var list BuildList
+	err := b.reader.get(ctx, "builds?locator="+url.QueryEscape(locator), &list)
+	return list.Build, err
+}
+
+//GetFinishedBuilds will list the most recent finished builds of buildType on
+//branch, or on any branch when branch is empty
+func (b *Builder) GetFinishedBuilds(ctx context.Context, buildType string, branch string, count int) ([]*Build, error) {
+	branchLocator := "default:any"
+	if branch != "" {
+		branchLocator = "name:" + locatorValue(branch)
 	}
-	if err := json.NewDecoder(resp.Body).Decode(b.BuildResult); err != nil {
+	locator := fmt.Sprintf("buildType:(id:%s),branch:(%s),state:finished,canceled:any,count:%d",
+		locatorValue(buildType), branchLocator, count)
+	var list BuildList
+	err := b.reader.get(ctx, "builds?locator="+url.QueryEscape(locator), &list)
+	return list.Build, err
+}
+
+//GetBuildQueue returns the builds waiting in the build queue
+func (b *Builder) GetBuildQueue(ctx context.Context) ([]*Build, error) {
+	var list BuildList
+	err := b.reader.get(ctx, "buildQueue", &list)
+	return list.Build, err
+}
+
+//GetLastestBuild will return the artifact version of the last successful build of buildType
+func (b *Builder) GetLastestBuild(ctx context.Context, buildType string) (string, error) {
+	b.BuildResult = new(Build)
+	path := fmt.Sprintf("buildTypes/id:%s/builds/running:false,status:success", url.PathEscape(buildType))
+	if err := b.reader.get(ctx, path, b.BuildResult); err != nil {
 		return "", err
 	}
-	ff, err := b.GetArtifactVersion()
-	return ff, err
-	//return b.GetArtifactVersionByID(b.BuildResult.ID)
+	return b.GetArtifactVersion(ctx)
 }
@@ -0,0 +1,147 @@
+package teamcity
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/mkobaly/hipchatBot/ci"
+)
+
+//ErrWebhookSecret is returned for a webhook request without the shared secret
+var ErrWebhookSecret = errors.New("teamcity: webhook secret does not match")
+
+//maxWebhookBody is the largest webhook payload read
+const maxWebhookBody = 1 << 20
+
+//tcWebHooksPayload is the JSON payload of the tcWebHooks plugin
+type tcWebHooksPayload struct {
+	Build *struct {
+		NotifyType     string `json:"notifyType"`
+		BuildID        string `json:"buildId"`
+		BuildTypeID    string `json:"buildTypeId"`
+		BuildNumber    string `json:"buildNumber"`
+		BuildResult    string `json:"buildResult"`
+		BuildStatus    string `json:"buildStatus"`
+		BranchName     string `json:"branchName"`
+		BuildStatusURL string `json:"buildStatusUrl"`
+	} `json:"build"`
+}
+
+//nativePayload is the payload of TeamCity's own webhooks, a build of the REST
+//API with the event it is about
+type nativePayload struct {
+	EventType string `json:"eventType"`
+	Payload   *Build `json:"payload"`
+}
+
+//tcWebHooksStates are the build state of each tcWebHooks notification
+var tcWebHooksStates = map[string]string{
+	"buildAddedToQueue": ci.StateQueued,
+	"buildStarted":      ci.StateRunning,
+	"changesLoaded":     ci.StateRunning,
+	"beforeBuildFinish": ci.StateRunning,
+	"buildFinished":     ci.StateFinished,
+	"buildInterrupted":  ci.StateFinished,
+	"buildSuccessful":   ci.StateFinished,
+	"buildFailed":       ci.StateFinished,
+	"buildFixed":        ci.StateFinished,
+	"buildBroken":       ci.StateFinished,
+}
+
+//nativeStates are the build state of each native webhook event
+var nativeStates = map[string]string{
+	"BUILD_QUEUED":      ci.StateQueued,
+	"BUILD_STARTED":     ci.StateRunning,
+	"CHANGES_LOADED":    ci.StateRunning,
+	"BUILD_FINISHED":    ci.StateFinished,
+	"BUILD_INTERRUPTED": ci.StateFinished,
+}
+
+//ReadWebhook verifies a webhook request and returns the build it is about.
+//The secret is either sent as is, in the X-Webhook-Secret header or the
+//secret query parameter, or signs the body with HMAC-SHA256 in the
+//X-Signature-256 header. The build is nil for events about something else.
+func ReadWebhook(r *http.Request, secret string) (*ci.Build, error) {
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxWebhookBody))
+	if err != nil {
+		return nil, err
+	}
+	if !webhookSigned(r, body, secret) {
+		return nil, ErrWebhookSecret
+	}
+	return ParseWebhook(body)
+}
+
+//webhookSigned reports whether the request carries the shared secret
+func webhookSigned(r *http.Request, body []byte, secret string) bool {
+	if secret == "" {
+		return false
+	}
+	if sig := r.Header.Get("X-Signature-256"); sig != "" {
+		got, err := hex.DecodeString(strings.TrimPrefix(sig, "sha256="))
+		if err != nil {
+			return false
+		}
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		return hmac.Equal(got, mac.Sum(nil))
+	}
+	token := r.Header.Get("X-Webhook-Secret")
+	if token == "" {
+		token = r.URL.Query().Get("secret")
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+}
+
+//ParseWebhook reads a tcWebHooks JSON or native webhook payload. The build is
+//nil for events about something else.
+func ParseWebhook(body []byte) (*ci.Build, error) {
+	var tc tcWebHooksPayload
+	if err := json.Unmarshal(body, &tc); err != nil {
+		return nil, err
+	}
+	if b := tc.Build; b != nil {
+		state, ok := tcWebHooksStates[b.NotifyType]
+		if !ok || b.BuildID == "" {
+			return nil, nil
+		}
+		res := &ci.Build{
+			ID:         b.BuildID,
+			ConfigID:   b.BuildTypeID,
+			Branch:     b.BranchName,
+			Number:     b.BuildNumber,
+			State:      state,
+			StatusText: b.BuildStatus,
+			WebURL:     b.BuildStatusURL,
+		}
+		if state == ci.StateFinished {
+			res.Status = strings.ToUpper(b.BuildResult)
+			if b.NotifyType == "buildInterrupted" {
+				res.Status = ci.StatusUnknown
+			}
+		}
+		return res, nil
+	}
+
+	var native nativePayload
+	if err := json.Unmarshal(body, &native); err != nil {
+		return nil, err
+	}
+	state, ok := nativeStates[native.EventType]
+	if !ok || native.Payload == nil || native.Payload.ID == 0 {
+		return nil, nil
+	}
+	res := toBuild(native.Payload)
+	if res.State == "" {
+		res.State = state
+	}
+	return res, nil
+}
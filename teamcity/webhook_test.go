@@ -0,0 +1,83 @@
+package teamcity
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mkobaly/hipchatBot/ci"
+)
+
+const tcWebHooksFinished = `{"build":{"notifyType":"buildFinished","buildId":"12","buildTypeId":"Web_CI",
+"buildNumber":"42","buildResult":"failure","buildStatus":"Tests failed: 3","branchName":"develop",
+"buildStatusUrl":"http://teamcity/viewLog.html?buildId=12"}}`
+
+func TestParseWebhook(t *testing.T) {
+	for name, tt := range map[string]struct {
+		body string
+		want *ci.Build
+	}{
+		"tcWebHooks finished": {tcWebHooksFinished, &ci.Build{ID: "12", ConfigID: "Web_CI", Branch: "develop", Number: "42",
+			State: ci.StateFinished, Status: ci.StatusFailure, StatusText: "Tests failed: 3", WebURL: "http://teamcity/viewLog.html?buildId=12"}},
+		"tcWebHooks started": {`{"build":{"notifyType":"buildStarted","buildId":"12","buildTypeId":"Web_CI","buildResult":"success"}}`,
+			&ci.Build{ID: "12", ConfigID: "Web_CI", State: ci.StateRunning}},
+		"tcWebHooks interrupted": {`{"build":{"notifyType":"buildInterrupted","buildId":"12","buildTypeId":"Web_CI","buildResult":"failure"}}`,
+			&ci.Build{ID: "12", ConfigID: "Web_CI", State: ci.StateFinished, Status: ci.StatusUnknown}},
+		"tcWebHooks responsibility": {`{"build":{"notifyType":"responsibilityChanged","buildTypeId":"Web_CI"}}`, nil},
+		"native finished": {`{"eventType":"BUILD_FINISHED","payload":{"id":12,"buildTypeId":"Web_CI","number":"42",
+"status":"SUCCESS","state":"finished","branchName":"develop","webUrl":"http://teamcity/build/12"}}`,
+			&ci.Build{ID: "12", ConfigID: "Web_CI", Branch: "develop", Number: "42", State: ci.StateFinished,
+				Status: ci.StatusSuccess, WebURL: "http://teamcity/build/12"}},
+		"native started": {`{"eventType":"BUILD_STARTED","payload":{"id":12,"buildTypeId":"Web_CI"}}`,
+			&ci.Build{ID: "12", ConfigID: "Web_CI", State: ci.StateRunning}},
+		"native other": {`{"eventType":"AGENT_REGISTERED","payload":{"id":3}}`, nil},
+	} {
+		got, err := ParseWebhook([]byte(tt.body))
+		if err != nil {
+			t.Errorf("%v: %v", name, err)
+			continue
+		}
+		if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+			t.Errorf("%v: got %+v want %+v", name, got, tt.want)
+		}
+	}
+
+	if _, err := ParseWebhook([]byte("<xml/>")); err == nil {
+		t.Errorf("XML payload parsed")
+	}
+}
+
+func TestReadWebhook(t *testing.T) {
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write([]byte(tcWebHooksFinished))
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	for name, tt := range map[string]struct {
+		target string
+		header string
+		value  string
+		ok     bool
+	}{
+		"signature":       {"/teamcity/webhook", "X-Signature-256", signature, true},
+		"bad signature":   {"/teamcity/webhook", "X-Signature-256", "sha256=00", false},
+		"secret header":   {"/teamcity/webhook", "X-Webhook-Secret", "s3cret", true},
+		"secret query":    {"/teamcity/webhook?secret=s3cret", "", "", true},
+		"wrong secret":    {"/teamcity/webhook?secret=guess", "", "", false},
+		"no secret given": {"/teamcity/webhook", "", "", false},
+	} {
+		r := httptest.NewRequest("POST", tt.target, strings.NewReader(tcWebHooksFinished))
+		if tt.header != "" {
+			r.Header.Set(tt.header, tt.value)
+		}
+		b, err := ReadWebhook(r, "s3cret")
+		if tt.ok && (err != nil || b == nil || b.ID != "12") {
+			t.Errorf("%v: got %+v, %v", name, b, err)
+		}
+		if !tt.ok && err != ErrWebhookSecret {
+			t.Errorf("%v: expected ErrWebhookSecret got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package teams
+
+import (
+	"github.com/mkobaly/hipchatBot/chat"
+	"github.com/mkobaly/hipchatBot/ci"
+)
+
+// element is the subset of Adaptive Card elements the replies use
+type element struct {
+	Type   string `json:"type"`
+	Text   string `json:"text,omitempty"`
+	Size   string `json:"size,omitempty"`
+	Weight string `json:"weight,omitempty"`
+	Color  string `json:"color,omitempty"`
+	Wrap   bool   `json:"wrap,omitempty"`
+	Facts  []fact `json:"facts,omitempty"`
+}
+
+// fact is a title and value pair of a FactSet, or of a connector card
+// section
+type fact struct {
+	Title string `json:"title,omitempty"`
+	Name  string `json:"name,omitempty"`
+	Value string `json:"value"`
+}
+
+// adaptiveCard is an Adaptive Card, version 1.2 being what Teams renders
+// everywhere
+type adaptiveCard struct {
+	Type    string    `json:"type"`
+	Schema  string    `json:"$schema"`
+	Version string    `json:"version"`
+	Body    []element `json:"body"`
+}
+
+// attachment wraps a card in a Bot Framework message
+type attachment struct {
+	ContentType string        `json:"contentType"`
+	Content     *adaptiveCard `json:"content"`
+}
+
+// activity is the message returned to an outgoing webhook
+type activity struct {
+	Type             string       `json:"type"`
+	Text             string       `json:"text,omitempty"`
+	AttachmentLayout string       `json:"attachmentLayout,omitempty"`
+	Attachments      []attachment `json:"attachments,omitempty"`
+}
+
+// cardColors map the message colors onto the Adaptive Card text colors
+var cardColors = map[string]string{
+	chat.ColorGreen:  "good",
+	chat.ColorYellow: "warning",
+	chat.ColorRed:    "attention",
+	chat.ColorGray:   "default",
+}
+
+// themeColors are the connector card accent colors for the message colors
+var themeColors = map[string]string{
+	chat.ColorGreen:  "2EB886",
+	chat.ColorYellow: "DAA038",
+	chat.ColorRed:    "A30200",
+	chat.ColorGray:   "CCCCCC",
+}
+
+func newCard(body ...element) attachment {
+	return attachment{
+		ContentType: "application/vnd.microsoft.card.adaptive",
+		Content: &adaptiveCard{
+			Type:    "AdaptiveCard",
+			Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+			Version: "1.2",
+			Body:    body,
+		},
+	}
+}
+
+func title(s string, color string) element {
+	return element{Type: "TextBlock", Text: s, Size: "Medium", Weight: "Bolder", Color: cardColors[color], Wrap: true}
+}
+
+func facts(pairs ...string) element {
+	fs := element{Type: "FactSet"}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i+1] != "" {
+			fs.Facts = append(fs.Facts, fact{Title: pairs[i], Value: pairs[i+1]})
+		}
+	}
+	return fs
+}
+
+// render lays out a reply as an Adaptive Card. Build status and kick replies
+// are fact sets, the rest the Markdown text from markdown.
+func render(m chat.Message, markdown func(chat.Message) string) attachment {
+	switch data := m.Data.(type) {
+	case *ci.Build:
+		if m.Template == "status" {
+			return newCard(
+				title("Build Result Status", m.Color),
+				facts("Build Config", data.ConfigID, "Branch", data.Branch, "State", data.State,
+					"Status", data.Status, "Details", data.StatusText),
+			)
+		}
+	case chat.KickReply:
+		if m.Template == "kick" {
+			status := "/build status " + data.TaskID
+			if data.Server != "" {
+				status += " --server " + data.Server
+			}
+			return newCard(
+				title(data.Heading(), m.Color),
+				facts("Build Configuration", data.BuildConfigID, "Branch", data.Branch, "Revision", data.Revision,
+					"Schedule", data.Schedule),
+				element{Type: "TextBlock", Text: "Check on it with `" + status + "`", Wrap: true},
+			)
+		}
+	}
+	if m.Template == "" {
+		return newCard(title(m.Text, m.Color))
+	}
+	return newCard(element{Type: "TextBlock", Text: markdown(m), Wrap: true})
+}
+
+// connectorCard is an Office 365 connector card, the format Teams incoming
+// webhooks take
+type connectorCard struct {
+	Type       string    `json:"@type"`
+	Context    string    `json:"@context"`
+	ThemeColor string    `json:"themeColor,omitempty"`
+	Summary    string    `json:"summary"`
+	Title      string    `json:"title,omitempty"`
+	Text       string    `json:"text,omitempty"`
+	Sections   []section `json:"sections,omitempty"`
+}
+
+type section struct {
+	Facts []fact `json:"facts,omitempty"`
+	Text  string `json:"text,omitempty"`
+}
+
+// connector lays out a notification as a connector card
+func connector(m chat.Message, markdown func(chat.Message) string) *connectorCard {
+	card := &connectorCard{
+		Type:       "MessageCard",
+		Context:    "http://schema.org/extensions",
+		ThemeColor: themeColors[m.Color],
+	}
+	if b, ok := m.Data.(*ci.Build); ok {
+		card.Title = "Build " + b.State + ": " + b.ConfigID
+		card.Summary = card.Title
+		s := section{}
+		for _, f := range [][2]string{{"Branch", b.Branch}, {"Status", b.Status}, {"Details", b.StatusText}} {
+			if f[1] != "" {
+				s.Facts = append(s.Facts, fact{Name: f[0], Value: f[1]})
+			}
+		}
+		if b.WebURL != "" {
+			s.Text = "[Open build](" + b.WebURL + ")"
+		}
+		card.Sections = []section{s}
+		return card
+	}
+	if m.Template == "" {
+		card.Title = m.Text
+		card.Summary = m.Text
+		return card
+	}
+	card.Text = markdown(m)
+	card.Summary = m.Template
+	return card
+}
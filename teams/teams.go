@@ -0,0 +1,263 @@
+// Package teams connects the bot to Microsoft Teams through an outgoing
+// webhook. The bot answers "@bot build kick ..." mentions with Adaptive Cards
+// and posts build results to a channel's incoming webhook as connector cards.
+package teams
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mkobaly/hipchatBot/chat"
+	"github.com/mkobaly/hipchatBot/config"
+)
+
+const (
+	defaultTimeout = 30 * time.Second
+	// maxBody is the largest request body read from Teams
+	maxBody = 1 << 20
+	// defaultReplyWait is how long the response to a message waits for its
+	// replies when they can not be posted later. Teams gives up after five
+	// seconds.
+	defaultReplyWait = 4 * time.Second
+)
+
+// ErrSignature is returned for requests that were not signed with the
+// outgoing webhook's security token
+var ErrSignature = errors.New("teams: invalid request signature")
+
+// message is the part of the Bot Framework activity an outgoing webhook
+// sends that the bot uses
+type message struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+	From struct {
+		ID          string `json:"id"`
+		Name        string `json:"name"`
+		AADObjectID string `json:"aadObjectId"`
+	} `json:"from"`
+	Conversation struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"conversation"`
+	ChannelData struct {
+		Channel struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"channel"`
+	} `json:"channelData"`
+}
+
+// Handler serves a Teams outgoing webhook
+type Handler struct {
+	secret     []byte
+	webhookURL string
+	dispatch   chat.Dispatcher
+	markdown   func(chat.Message) string
+	http       *http.Client
+	replyWait  time.Duration
+}
+
+// New creates the Teams handler. Commands are passed to dispatch and replies
+// with a template are shown as the Markdown returned by markdown. It fails
+// when the security token Teams handed out is not base64.
+func New(settings config.TeamsSettings, dispatch chat.Dispatcher, markdown func(chat.Message) string) (*Handler, error) {
+	secret, err := base64.StdEncoding.DecodeString(settings.Secret)
+	if err != nil {
+		return nil, fmt.Errorf("teams: security token: %v", err)
+	}
+	return &Handler{
+		secret:     secret,
+		webhookURL: settings.WebhookURL,
+		dispatch:   dispatch,
+		markdown:   markdown,
+		http:       &http.Client{Timeout: defaultTimeout},
+		replyWait:  defaultReplyWait,
+	}, nil
+}
+
+// verify checks the "HMAC <signature>" Authorization header, returning the
+// request body
+func (h *Handler) verify(r *http.Request) ([]byte, error) {
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxBody))
+	if err != nil {
+		return nil, err
+	}
+	if len(h.secret) == 0 {
+		return nil, ErrSignature
+	}
+	got, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(r.Header.Get("Authorization"), "HMAC "))
+	if err != nil {
+		return nil, ErrSignature
+	}
+	mac := hmac.New(sha256.New, h.secret)
+	mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return nil, ErrSignature
+	}
+	return body, nil
+}
+
+// Webhook handles a message sent to the outgoing webhook. Teams waits for
+// the reply, which has to come within five seconds, so the command runs on
+// its own. With an incoming webhook set up the message is acknowledged
+// straight away and the replies are posted to the webhook's channel. Without
+// one the response waits a little for the replies and later ones are lost.
+func (h *Handler) Webhook(w http.ResponseWriter, r *http.Request) {
+	body, err := h.verify(r)
+	if err != nil {
+		log.Printf("Teams message rejected: %v", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	var msg message
+	if err := json.Unmarshal(body, &msg); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	cmd := chat.Command{
+		Text: commandText(msg.Text),
+		User: chat.User{
+			ID:          msg.From.AADObjectID,
+			Name:        msg.From.Name,
+			MentionName: msg.From.Name,
+		},
+		RoomID:   msg.ChannelData.Channel.ID,
+		RoomName: msg.ChannelData.Channel.Name,
+		Source:   "Teams",
+	}
+	if cmd.User.ID == "" {
+		cmd.User.ID = msg.From.ID
+	}
+	if cmd.RoomID == "" {
+		// the conversation of a channel message is "<channel>;messageid=<id>"
+		cmd.RoomID = strings.SplitN(msg.Conversation.ID, ";", 2)[0]
+	}
+
+	rep := &replier{h: h}
+	var cr chat.Replier = rep
+	if h.webhookURL != "" {
+		cr = &notifier{rep}
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.dispatch(context.Background(), cmd, cr)
+	}()
+	if h.webhookURL == "" {
+		select {
+		case <-done:
+		case <-time.After(h.replyWait):
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rep.respond(cmd.Text))
+}
+
+var (
+	mention = regexp.MustCompile(`<at>[^<]*</at>`)
+	tag     = regexp.MustCompile(`<[^>]*>`)
+)
+
+// commandText turns the text of a message to the bot into a "/build" command
+func commandText(s string) string {
+	s = html.UnescapeString(tag.ReplaceAllString(mention.ReplaceAllString(s, " "), " "))
+	words := strings.Fields(s)
+	if len(words) > 0 && (words[0] == "build" || words[0] == "/build") {
+		words = words[1:]
+	}
+	return strings.Join(append([]string{"/build"}, words...), " ")
+}
+
+// replier collects the replies to a message as cards for the response. Once
+// the response is sent replies go to the incoming webhook.
+type replier struct {
+	h         *Handler
+	mu        sync.Mutex
+	cards     []attachment
+	responded bool
+}
+
+func (r *replier) Reply(ctx context.Context, m chat.Message) error {
+	r.mu.Lock()
+	if !r.responded {
+		r.cards = append(r.cards, render(m, r.h.markdown))
+		r.mu.Unlock()
+		return nil
+	}
+	r.mu.Unlock()
+	if r.h.webhookURL == "" {
+		return errors.New("teams: reply came after the response and there is no incoming webhook")
+	}
+	return r.h.post(ctx, m)
+}
+
+// respond returns the response to the message running command: the replies
+// so far, or an acknowledgement when there are none yet
+func (r *replier) respond(command string) *activity {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.responded = true
+	if len(r.cards) == 0 {
+		return &activity{Type: "message", Text: "Running " + command}
+	}
+	return &activity{Type: "message", AttachmentLayout: "list", Attachments: r.cards}
+}
+
+// Notifier posts to the incoming webhook's channel, outside of any message.
+// It is nil when no webhook is set up.
+func (h *Handler) Notifier(channelID string, channelName string) chat.Notifier {
+	if h.webhookURL == "" {
+		return nil
+	}
+	return &notifier{replier: &replier{h: h}}
+}
+
+// notifier is the replier of a message when an incoming webhook is set up.
+// Notify posts a connector card to the webhook's channel.
+type notifier struct {
+	*replier
+}
+
+func (n *notifier) Notify(ctx context.Context, m chat.Message) error {
+	return n.h.post(ctx, m)
+}
+
+// post sends m to the incoming webhook as a connector card
+func (h *Handler) post(ctx context.Context, m chat.Message) error {
+	b, err := json.Marshal(connector(m, h.markdown))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", h.webhookURL, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := h.http.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		var body bytes.Buffer
+		body.ReadFrom(resp.Body)
+		return fmt.Errorf("teams: POST incoming webhook: %d %s", resp.StatusCode, strings.TrimSpace(body.String()))
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package teams
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mkobaly/hipchatBot/chat"
+	"github.com/mkobaly/hipchatBot/ci"
+	"github.com/mkobaly/hipchatBot/config"
+)
+
+var secret = base64.StdEncoding.EncodeToString([]byte("teams security token"))
+
+func signed(body string) *http.Request {
+	r := httptest.NewRequest("POST", "/teams/webhook", strings.NewReader(body))
+	mac := hmac.New(sha256.New, []byte("teams security token"))
+	mac.Write([]byte(body))
+	r.Header.Set("Authorization", "HMAC "+base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	return r
+}
+
+const kickMessage = `{"type":"message","text":"<at>BuildBot</at>&nbsp;build kick Web_CI main\n",
+	"from":{"id":"29:1abc","name":"Ada Lovelace","aadObjectId":"aad-42"},
+	"conversation":{"id":"19:chan@thread.skype;messageid=1500"},
+	"channelData":{"channel":{"id":"19:chan@thread.skype"}}}`
+
+func TestCommandText(t *testing.T) {
+	for in, want := range map[string]string{
+		"<at>BuildBot</at> build kick Web_CI main": "/build kick Web_CI main",
+		"<at>BuildBot</at>&nbsp;status 42":         "/build status 42",
+		"<p><at>Build Bot</at> --help</p>":         "/build --help",
+	} {
+		if got := commandText(in); got != want {
+			t.Errorf("commandText(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	var got chat.Command
+	dispatch := func(ctx context.Context, cmd chat.Command, r chat.Replier) {
+		got = cmd
+		r.Reply(ctx, chat.Message{
+			Template: "status",
+			Data:     &ci.Build{ConfigID: "Web_CI", Branch: "main", State: ci.StateFinished, Status: ci.StatusSuccess},
+			Color:    chat.ColorGreen,
+		})
+	}
+	h, err := New(config.TeamsSettings{Secret: secret}, dispatch, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	h.Webhook(w, signed(kickMessage))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d", w.Code)
+	}
+	if got.Text != "/build kick Web_CI main" || got.User.ID != "aad-42" || got.RoomID != "19:chan@thread.skype" || got.Source != "Teams" {
+		t.Errorf("unexpected command %+v", got)
+	}
+	var a activity
+	json.NewDecoder(w.Body).Decode(&a)
+	if len(a.Attachments) != 1 || a.Attachments[0].ContentType != "application/vnd.microsoft.card.adaptive" {
+		t.Fatalf("unexpected reply %+v", a)
+	}
+	body := a.Attachments[0].Content.Body
+	if body[0].Color != "good" || len(body[1].Facts) != 4 || body[1].Facts[3].Value != "SUCCESS" {
+		t.Errorf("unexpected status card %+v", body)
+	}
+
+	r := signed(kickMessage)
+	r.Header.Set("Authorization", "HMAC "+base64.StdEncoding.EncodeToString([]byte("forged")))
+	w = httptest.NewRecorder()
+	h.Webhook(w, r)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("forged signature got status %d", w.Code)
+	}
+}
+
+func TestWebhookAcknowledges(t *testing.T) {
+	posted := make(chan connectorCard, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var c connectorCard
+		json.NewDecoder(r.Body).Decode(&c)
+		posted <- c
+		w.Write([]byte("1"))
+	}))
+	defer ts.Close()
+
+	release := make(chan struct{})
+	dispatch := func(ctx context.Context, cmd chat.Command, r chat.Replier) {
+		<-release
+		if err := r.Reply(ctx, chat.Message{Text: "Build 42 kicked off", Color: chat.ColorGreen}); err != nil {
+			t.Errorf("Reply: %v", err)
+		}
+	}
+	h, err := New(config.TeamsSettings{Secret: secret, WebhookURL: ts.URL}, dispatch, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	h.Webhook(w, signed(kickMessage))
+	var a activity
+	json.NewDecoder(w.Body).Decode(&a)
+	if a.Text != "Running /build kick Web_CI main" || len(a.Attachments) != 0 {
+		t.Errorf("unexpected acknowledgement %+v", a)
+	}
+
+	close(release)
+	select {
+	case c := <-posted:
+		if c.ThemeColor == "" || !strings.Contains(c.Text+c.Title, "Build 42 kicked off") {
+			t.Errorf("unexpected connector card %+v", c)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("reply was not posted to the incoming webhook")
+	}
+}
+
+func TestWebhookWaitsWithoutIncomingWebhook(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	dispatch := func(ctx context.Context, cmd chat.Command, r chat.Replier) {
+		<-release
+	}
+	h, err := New(config.TeamsSettings{Secret: secret}, dispatch, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.replyWait = 10 * time.Millisecond
+	w := httptest.NewRecorder()
+	h.Webhook(w, signed(kickMessage))
+	var a activity
+	json.NewDecoder(w.Body).Decode(&a)
+	if a.Text != "Running /build kick Web_CI main" {
+		t.Errorf("unexpected response %+v", a)
+	}
+}
+
+func TestNotify(t *testing.T) {
+	posted := make(chan connectorCard, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var c connectorCard
+		json.NewDecoder(r.Body).Decode(&c)
+		posted <- c
+		w.Write([]byte("1"))
+	}))
+	defer ts.Close()
+
+	dispatch := func(ctx context.Context, cmd chat.Command, r chat.Replier) {
+		n, ok := r.(chat.Notifier)
+		if !ok {
+			t.Error("replier can not notify with a webhook set up")
+			return
+		}
+		b := &ci.Build{ConfigID: "Web_CI", Branch: "main", State: ci.StateFinished, Status: ci.StatusFailure, WebURL: "https://ci/b/1"}
+		if err := n.Notify(ctx, chat.Message{Text: "Build complete", Data: b, Color: chat.ColorRed}); err != nil {
+			t.Errorf("Notify: %v", err)
+		}
+	}
+	h, err := New(config.TeamsSettings{Secret: secret, WebhookURL: ts.URL}, dispatch, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Webhook(httptest.NewRecorder(), signed(kickMessage))
+
+	c := <-posted
+	if c.Type != "MessageCard" || c.ThemeColor != "A30200" || c.Title != "Build finished: Web_CI" || len(c.Sections[0].Facts) != 2 {
+		t.Errorf("unexpected connector card %+v", c)
+	}
+}
@@ -0,0 +1,61 @@
+package util
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrInvalidJWT is returned for a signed_request that is malformed, expired
+// or not signed by a known issuer
+var ErrInvalidJWT = errors.New("invalid JWT")
+
+// ParseJWT verifies a HS256 JWT, like the signed_request HipChat passes to
+// add-on pages, with the shared secret secret returns for its issuer. The
+// token's claims are returned.
+func ParseJWT(token string, secret func(issuer string) (string, bool)) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidJWT
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil || header.Alg != "HS256" {
+		return nil, ErrInvalidJWT
+	}
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrInvalidJWT
+	}
+	issuer, _ := claims["iss"].(string)
+	key, ok := secret(issuer)
+	if !ok {
+		return nil, ErrInvalidJWT
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidJWT
+	}
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return nil, ErrInvalidJWT
+	}
+	if exp, ok := claims["exp"].(float64); ok && time.Now().Unix() > int64(exp) {
+		return nil, ErrInvalidJWT
+	}
+	return claims, nil
+}
+
+func decodeSegment(s string, out interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, out)
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/mkobaly/hipchatBot/chat"
+	"github.com/mkobaly/hipchatBot/ci"
+	"github.com/mkobaly/hipchatBot/store"
+)
+
+// How often watched build configurations are checked for finished builds and
+// how many of their latest builds are looked at
+var (
+	subscriptionInterval = 30 * time.Second
+	watchHistory         = 5
+)
+
+// maxSeen is how many build IDs of a watch are remembered as posted
+const maxSeen = 50
+
+// subscriptions remembers the builds of each watch that were already seen,
+// so every finished build is posted once
+type subscriptions struct {
+	mu      sync.Mutex
+	watches map[string]*seenBuilds
+}
+
+// seenBuilds are the builds of a watch seen so far, oldest first, and the
+// status of the latest one. primed is set once the watch's history has been
+// looked at. followed are the builds kicked off from the watching room, whose
+// result is posted when they finish anyway.
+type seenBuilds struct {
+	ids      []string
+	status   string
+	primed   bool
+	followed map[string]bool
+}
+
+func newSubscriptions() *subscriptions {
+	return &subscriptions{watches: make(map[string]*seenBuilds)}
+}
+
+// prime records builds, newest first, as seen without posting them. It is
+// used when a configuration starts being watched and when its history is
+// first looked at since the bot started.
+func (s *subscriptions) prime(w store.Watch, builds []*ci.Build) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seen := s.seen(w)
+	for i := len(builds) - 1; i >= 0; i-- {
+		if !seen.has(builds[i].ID) {
+			seen.add(builds[i])
+		}
+	}
+	seen.primed = true
+}
+
+// primed reports whether the watch's history has been looked at
+func (s *subscriptions) primed(w store.Watch) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seen, ok := s.watches[w.Key()]
+	return ok && seen.primed
+}
+
+// follow records a build kicked off from the watching room, so the watch
+// does not post its result a second time
+func (s *subscriptions) follow(w store.Watch, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seen := s.seen(w)
+	if seen.followed == nil {
+		seen.followed = make(map[string]bool)
+	}
+	seen.followed[id] = true
+}
+
+// seen returns what was seen of the watch, s.mu must be held
+func (s *subscriptions) seen(w store.Watch) *seenBuilds {
+	seen, ok := s.watches[w.Key()]
+	if !ok {
+		seen = &seenBuilds{}
+		s.watches[w.Key()] = seen
+	}
+	return seen
+}
+
+// forget drops what was seen of the watch
+func (s *subscriptions) forget(w store.Watch) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.watches, w.Key())
+}
+
+// finished records a finished build of the watch, reporting whether it was
+// not seen before and should be posted. Builds kicked off from the watching
+// room are recorded without being posted.
+func (s *subscriptions) finished(w store.Watch, b *ci.Build) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seen := s.seen(w)
+	if seen.has(b.ID) {
+		return false
+	}
+	previous := seen.status
+	seen.add(b)
+	if seen.followed[b.ID] {
+		delete(seen.followed, b.ID)
+		return false
+	}
+	switch w.Only {
+	case store.OnlyFailures:
+		return b.Status != ci.StatusSuccess
+	case store.OnlyChanges:
+		// without an earlier build there is no change to tell
+		return previous != "" && b.Status != previous
+	}
+	return true
+}
+
+func (s *seenBuilds) has(id string) bool {
+	for _, seen := range s.ids {
+		if seen == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *seenBuilds) add(b *ci.Build) {
+	s.ids = append(s.ids, b.ID)
+	if len(s.ids) > maxSeen {
+		s.ids = s.ids[len(s.ids)-maxSeen:]
+	}
+	s.status = b.Status
+}
+
+// addNotifier registers how build results are posted to the rooms of a chat
+// platform outside of any command
+func (c *Context) addNotifier(source string, notifier func(roomID string, roomName string) chat.Notifier) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.notifiers == nil {
+		c.notifiers = make(map[string]func(string, string) chat.Notifier)
+	}
+	c.notifiers[source] = notifier
+}
+
+// notifier returns what posts to the room on the chat platform, or nil when
+// the bot can not post there by itself
+func (c *Context) notifier(source string, roomID string, roomName string) chat.Notifier {
+	c.mu.Lock()
+	notifier := c.notifiers[source]
+	c.mu.Unlock()
+	if notifier == nil {
+		return nil
+	}
+	return notifier(roomID, roomName)
+}
+
+// followWatched keeps the room's watches of the configuration from posting a
+// build kicked off from the room, whose result is posted when it finishes
+func (c *Context) followWatched(roomID string, provider ci.Provider, req ci.BuildRequest, id string) {
+	for _, w := range c.store.RoomWatches(roomID) {
+		if c.providers[w.Server] == provider && w.ConfigID == req.ConfigID && (w.Branch == "" || w.Branch == req.Branch) {
+			c.subs.follow(w, id)
+		}
+	}
+}
+
+// pollWatches checks the watched build configurations every
+// subscriptionInterval until ctx is done. The ones on servers posting their
+// build events are only checked every webhookFallback.
+func (c *Context) pollWatches(ctx context.Context) {
+	var lastAll time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(subscriptionInterval):
+		}
+		all := time.Since(lastAll) >= webhookFallback
+		if all {
+			lastAll = time.Now()
+		}
+		c.checkWatches(ctx, all)
+	}
+}
+
+// checkWatches posts the builds of the watched configurations that finished
+// since they were last checked. Unless all is set the configurations on
+// servers posting their build events are left out.
+func (c *Context) checkWatches(ctx context.Context, all bool) {
+	for _, w := range c.store.Watches() {
+		provider := c.providers[w.Server]
+		history, ok := provider.(ci.History)
+		if !ok || (!all && c.webhooked(provider)) {
+			continue
+		}
+		builds, err := history.FinishedBuilds(ctx, w.ConfigID, w.Branch, watchHistory)
+		if err != nil {
+			log.Printf("Error getting builds of watched %v: %v", w.ConfigID, err)
+			continue
+		}
+		if !c.subs.primed(w) {
+			// first look since the bot started, the builds were posted before
+			c.subs.prime(w, builds)
+			continue
+		}
+		for i := len(builds) - 1; i >= 0; i-- {
+			c.watchedBuildFinished(ctx, w, builds[i])
+		}
+	}
+}
+
+// watchedBuildFinished posts a finished build of a watched configuration to
+// the watching room, unless it was posted already or the watch leaves it out
+func (c *Context) watchedBuildFinished(ctx context.Context, w store.Watch, b *ci.Build) {
+	c.buildChanged(w.RoomID, b.ID, b)
+	if !c.subs.finished(w, b) {
+		return
+	}
+	n := c.notifier(w.Source, w.RoomID, w.RoomName)
+	if n == nil {
+		log.Printf("Can not post to %v room %v", w.Source, w.RoomID)
+		return
+	}
+	if err := n.Notify(ctx, chat.Message{Template: "finished", Data: b, Color: statusColor(b)}); err != nil {
+		log.Printf("Error posting build %v to room %v: %v", b.ID, w.RoomID, err)
+	}
+}
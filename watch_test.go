@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/mkobaly/hipchatBot/chat"
+	"github.com/mkobaly/hipchatBot/ci"
+	"github.com/mkobaly/hipchatBot/config"
+	"github.com/mkobaly/hipchatBot/store"
+)
+
+// historyProvider is a provider whose finished builds are set by the test
+type historyProvider struct {
+	ci.Provider
+	builds []*ci.Build
+}
+
+func (p *historyProvider) FinishedBuilds(ctx context.Context, configID string, branch string, count int) ([]*ci.Build, error) {
+	return p.builds, nil
+}
+
+func finishedBuild(id string, status string) *ci.Build {
+	return &ci.Build{ID: id, ConfigID: "Web_CI", State: ci.StateFinished, Status: status}
+}
+
+func TestSubscriptionsFinished(t *testing.T) {
+	for only, want := range map[string][]bool{
+		store.OnlyAll:      {true, true, true},
+		store.OnlyFailures: {false, true, true},
+		store.OnlyChanges:  {false, true, false},
+	} {
+		s := newSubscriptions()
+		w := store.Watch{RoomID: "1", ConfigID: "Web_CI", Only: only}
+		s.prime(w, []*ci.Build{finishedBuild("2", ci.StatusSuccess), finishedBuild("1", ci.StatusFailure)})
+		if s.finished(w, finishedBuild("2", ci.StatusSuccess)) {
+			t.Errorf("%v: posted a primed build", only)
+		}
+		builds := []*ci.Build{
+			finishedBuild("3", ci.StatusSuccess),
+			finishedBuild("4", ci.StatusFailure),
+			finishedBuild("5", ci.StatusFailure),
+		}
+		for i, b := range builds {
+			if got := s.finished(w, b); got != want[i] {
+				t.Errorf("%v: build %v posted %v, want %v", only, b.ID, got, want[i])
+			}
+		}
+		if s.finished(w, builds[2]) {
+			t.Errorf("%v: build posted twice", only)
+		}
+	}
+}
+
+func TestWatchCommands(t *testing.T) {
+	st, err := store.Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &historyProvider{builds: []*ci.Build{finishedBuild("1", ci.StatusSuccess)}}
+	c := &Context{
+		rooms:     make(map[string]*RoomConfig),
+		providers: map[string]ci.Provider{"default": p},
+		cfg:       &config.Config{DefaultServer: "default"},
+		health:    newRoomHealth(),
+		store:     st,
+		subs:      newSubscriptions(),
+	}
+	var posted replies
+	c.addNotifier("test", func(roomID string, roomName string) chat.Notifier {
+		return notifierFunc(posted.Reply)
+	})
+	ctx := context.Background()
+	run := func(text string) replies {
+		var r replies
+		c.dispatch(ctx, chat.Command{Text: text, RoomID: "1", RoomName: "builds", Source: "test"}, &r)
+		return r
+	}
+
+	r := run("/build watch Web_CI --only failures")
+	if len(r) != 1 || r[0].Template != "watching" {
+		t.Fatalf("unexpected watch reply %+v", r)
+	}
+	if ws := r[0].Data.([]store.Watch); len(ws) != 1 || ws[0].Server != "default" || ws[0].Only != store.OnlyFailures || ws[0].RoomName != "builds" {
+		t.Errorf("unexpected watches %+v", ws)
+	}
+	if r := run("/build watch Web_CI --only sometimes"); len(r) != 1 || r[0].Template != "help" {
+		t.Errorf("bad --only accepted: %+v", r)
+	}
+
+	p.builds = []*ci.Build{finishedBuild("3", ci.StatusFailure), finishedBuild("2", ci.StatusSuccess), finishedBuild("1", ci.StatusSuccess)}
+	c.checkWatches(ctx, true)
+	c.checkWatches(ctx, true)
+	if len(posted) != 1 || posted[0].Data.(*ci.Build).ID != "3" || posted[0].Color != chat.ColorRed {
+		t.Errorf("unexpected posts %+v", posted)
+	}
+	if bs := c.health.builds("1"); len(bs) != 1 || bs[0].ID != "3" {
+		t.Errorf("room health not updated: %+v", bs)
+	}
+
+	if r := run("/build unwatch Web_CI"); len(r) != 1 || r[0].Color != chat.ColorGreen {
+		t.Errorf("unexpected unwatch reply %+v", r)
+	}
+	if bs := c.health.builds("1"); len(bs) != 0 {
+		t.Errorf("unwatched configuration still in the room health: %+v", bs)
+	}
+	if r := run("/build unwatch Web_CI"); len(r) != 1 || r[0].Color != chat.ColorYellow {
+		t.Errorf("unexpected second unwatch reply %+v", r)
+	}
+	if r := run("/build watching"); len(r) != 1 || len(r[0].Data.([]store.Watch)) != 0 {
+		t.Errorf("unexpected watching reply %+v", r)
+	}
+
+	var r2 replies
+	c.dispatch(ctx, chat.Command{Text: "/build watch Web_CI", RoomID: "1", Source: "the command line"}, &r2)
+	if len(r2) != 1 || r2[0].Color != chat.ColorYellow {
+		t.Errorf("watch from a room the bot can not post to: %+v", r2)
+	}
+}
+
+func TestWatchOnTwoServers(t *testing.T) {
+	st, err := store.Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &Context{
+		rooms: make(map[string]*RoomConfig),
+		providers: map[string]ci.Provider{
+			"default": &historyProvider{},
+			"cloud":   &historyProvider{},
+		},
+		cfg:    &config.Config{DefaultServer: "default"},
+		health: newRoomHealth(),
+		store:  st,
+		subs:   newSubscriptions(),
+	}
+	c.addNotifier("test", func(roomID string, roomName string) chat.Notifier {
+		return notifierFunc(func(context.Context, chat.Message) error { return nil })
+	})
+	run := func(text string) replies {
+		var r replies
+		c.dispatch(context.Background(), chat.Command{Text: text, RoomID: "1", Source: "test"}, &r)
+		return r
+	}
+
+	run("/build watch Web_CI")
+	r := run("/build watch Web_CI --server cloud")
+	if len(r) != 1 || len(r[0].Data.([]store.Watch)) != 2 {
+		t.Fatalf("watch on the second server replaced the first: %+v", r)
+	}
+	if r := run("/build unwatch Web_CI --server cloud"); len(r) != 1 || r[0].Color != chat.ColorGreen {
+		t.Errorf("unexpected unwatch reply %+v", r)
+	}
+	if ws := st.RoomWatches("1"); len(ws) != 1 || ws[0].Server != "default" {
+		t.Errorf("unexpected watches %+v", ws)
+	}
+	if r := run("/build unwatch Web_CI --server nope"); len(r) != 1 || r[0].Color != chat.ColorRed {
+		t.Errorf("unexpected unwatch reply for an unknown server %+v", r)
+	}
+}
+
+func TestCheckWatchesAfterRestart(t *testing.T) {
+	st, err := store.Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &historyProvider{}
+	for i := 5; i >= 1; i-- {
+		p.builds = append(p.builds, finishedBuild(strconv.Itoa(i), ci.StatusSuccess))
+	}
+	c := &Context{
+		providers: map[string]ci.Provider{"default": p},
+		cfg:       &config.Config{DefaultServer: "default"},
+		health:    newRoomHealth(),
+		store:     st,
+		subs:      newSubscriptions(),
+	}
+	var posted replies
+	c.addNotifier("test", func(roomID string, roomName string) chat.Notifier {
+		return notifierFunc(posted.Reply)
+	})
+	w := store.Watch{RoomID: "1", Source: "test", Server: "default", ConfigID: "Web_CI", Only: store.OnlyAll}
+	st.AddWatch(w)
+	ctx := context.Background()
+
+	c.checkWatches(ctx, true)
+	if len(posted) != 0 {
+		t.Errorf("posted old builds after a restart: %+v", posted)
+	}
+
+	// a build kicked off from the room posts its own result
+	c.followWatched("1", p, ci.BuildRequest{ConfigID: "Web_CI", Branch: "main"}, "6")
+	p.builds = append([]*ci.Build{finishedBuild("7", ci.StatusFailure), finishedBuild("6", ci.StatusSuccess)}, p.builds...)
+	c.checkWatches(ctx, true)
+	if len(posted) != 1 || posted[0].Data.(*ci.Build).ID != "7" {
+		t.Errorf("unexpected posts %+v", posted)
+	}
+}
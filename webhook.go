@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mkobaly/hipchatBot/ci"
+	"github.com/mkobaly/hipchatBot/config"
+	"github.com/mkobaly/hipchatBot/teamcity"
+)
+
+// webhookFallback is how often builds are still polled on servers that post
+// their build events, in case an event gets lost
+var webhookFallback = 5 * time.Minute
+
+// eventKey is a build of a CI server
+type eventKey struct {
+	provider ci.Provider
+	id       string
+}
+
+// buildEvents hands the builds of webhook events to the watchers of kicked
+// off builds
+type buildEvents struct {
+	mu      sync.Mutex
+	waiters map[eventKey][]chan *ci.Build
+}
+
+func newBuildEvents() *buildEvents {
+	return &buildEvents{waiters: make(map[eventKey][]chan *ci.Build)}
+}
+
+// subscribe returns the channel the events of the build come in on and the
+// function to call once they are no longer wanted
+func (e *buildEvents) subscribe(p ci.Provider, id string) (<-chan *ci.Build, func()) {
+	if e == nil {
+		return nil, func() {}
+	}
+	key := eventKey{p, id}
+	ch := make(chan *ci.Build, 1)
+	e.mu.Lock()
+	e.waiters[key] = append(e.waiters[key], ch)
+	e.mu.Unlock()
+	return ch, func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		waiters := e.waiters[key]
+		for i, w := range waiters {
+			if w == ch {
+				waiters = append(waiters[:i:i], waiters[i+1:]...)
+			}
+		}
+		if len(waiters) == 0 {
+			delete(e.waiters, key)
+		} else {
+			e.waiters[key] = waiters
+		}
+	}
+}
+
+// publish passes the build to its watchers. A watcher only gets the latest
+// event it has not read yet.
+func (e *buildEvents) publish(p ci.Provider, b *ci.Build) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, ch := range e.waiters[eventKey{p, b.ID}] {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- b
+	}
+}
+
+// webhooked reports whether the provider's server posts its build events to
+// the bot
+func (c *Context) webhooked(p ci.Provider) bool {
+	if c.cfg == nil {
+		return false
+	}
+	servers := c.cfg.Servers()
+	for name, provider := range c.providers {
+		if provider == p {
+			return servers[name].WebhookSecret != ""
+		}
+	}
+	return false
+}
+
+// teamcityWebhook receives the build events of the TeamCity server named by
+// the server query parameter, or of the default server
+func (c *Context) teamcityWebhook(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("server")
+	if name == "" {
+		name = c.cfg.ServerForRoom("")
+	}
+	creds, ok := c.cfg.Servers()[name]
+	if !ok || creds.ServerType() != config.TypeTeamcity || creds.WebhookSecret == "" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	b, err := teamcity.ReadWebhook(r, creds.WebhookSecret)
+	if err == teamcity.ErrWebhookSecret {
+		log.Printf("TeamCity webhook for %v rejected: %v", name, err)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	} else if err != nil {
+		log.Printf("Error reading TeamCity webhook for %v: %v", name, err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if b != nil {
+		c.buildEvent(r.Context(), name, b)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// buildEvent passes a build event of the server to the watcher of the build,
+// when it was kicked off from a room, and to the rooms watching its
+// configuration
+func (c *Context) buildEvent(ctx context.Context, server string, b *ci.Build) {
+	c.events.publish(c.providers[server], b)
+	for _, w := range c.store.Watches() {
+		if w.Server != server || w.ConfigID != b.ConfigID || (w.Branch != "" && w.Branch != b.Branch) {
+			continue
+		}
+		if b.Finished() {
+			c.watchedBuildFinished(ctx, w, b)
+		} else {
+			c.buildChanged(w.RoomID, b.ID, b)
+		}
+	}
+}
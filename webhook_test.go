@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mkobaly/hipchatBot/chat"
+	"github.com/mkobaly/hipchatBot/ci"
+	"github.com/mkobaly/hipchatBot/config"
+	"github.com/mkobaly/hipchatBot/store"
+)
+
+const finishedEvent = `{"build":{"notifyType":"buildFinished","buildId":"7","buildTypeId":"Web_CI","buildResult":"failure","branchName":"develop"}}`
+
+func TestTeamcityWebhook(t *testing.T) {
+	st, err := store.Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &historyProvider{}
+	c := &Context{
+		rooms:     make(map[string]*RoomConfig),
+		providers: map[string]ci.Provider{"tc": p, "other": &historyProvider{}},
+		cfg: &config.Config{
+			DefaultServer: "tc",
+			TeamcityServers: map[string]config.UserCredential{
+				"tc":    {URL: "http://teamcity", WebhookSecret: "s3cret"},
+				"other": {URL: "http://other"},
+			},
+		},
+		health: newRoomHealth(),
+		store:  st,
+		subs:   newSubscriptions(),
+		events: newBuildEvents(),
+	}
+	var posted replies
+	c.addNotifier("test", func(roomID string, roomName string) chat.Notifier {
+		return notifierFunc(posted.Reply)
+	})
+	watch := store.Watch{RoomID: "1", Source: "test", Server: "tc", ConfigID: "Web_CI", Only: store.OnlyAll}
+	st.AddWatch(watch)
+	st.AddWatch(store.Watch{RoomID: "2", Source: "test", Server: "tc", ConfigID: "Web_CI", Branch: "main", Only: store.OnlyAll})
+	c.subs.prime(watch, nil)
+
+	// a build kicked off from a room waits for its events
+	events, unsubscribe := c.events.subscribe(p, "7")
+	defer unsubscribe()
+	var kicked replies
+	done := make(chan error)
+	go func() {
+		done <- watchForFinishedBuild(context.Background(), p, "7", events, time.Hour, notifierFunc(kicked.Reply), func(*ci.Build) {})
+	}()
+
+	post := func(target string, body string) int {
+		w := httptest.NewRecorder()
+		c.teamcityWebhook(w, httptest.NewRequest("POST", target, strings.NewReader(body)))
+		return w.Code
+	}
+	if code := post("/teamcity/webhook?secret=s3cret", finishedEvent); code != http.StatusNoContent {
+		t.Fatalf("got status %d", code)
+	}
+	select {
+	case err := <-done:
+		if err != nil || len(kicked) != 1 || kicked[0].Data.(*ci.Build).Status != ci.StatusFailure {
+			t.Errorf("kick watcher got %+v, %v", kicked, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("kick watcher did not get the event")
+	}
+	if len(posted) != 1 || posted[0].Template != "finished" {
+		t.Errorf("watching room got %+v", posted)
+	}
+
+	// the same event again is not posted twice
+	post("/teamcity/webhook?secret=s3cret", finishedEvent)
+	if len(posted) != 1 {
+		t.Errorf("event posted %d times", len(posted))
+	}
+
+	if code := post("/teamcity/webhook?secret=guess", finishedEvent); code != http.StatusUnauthorized {
+		t.Errorf("wrong secret got status %d", code)
+	}
+	if code := post("/teamcity/webhook?server=other&secret=", finishedEvent); code != http.StatusNotFound {
+		t.Errorf("server without webhooks got status %d", code)
+	}
+	if !c.webhooked(p) || c.webhooked(c.providers["other"]) {
+		t.Errorf("webhooked servers not told apart")
+	}
+}